@@ -7,4 +7,7 @@ require (
 	github.com/google/go-cmp v0.5.9 // indirect
 	github.com/stretchr/testify v1.8.2
 	go.uber.org/automaxprocs v1.5.1
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
+	golang.org/x/sys v0.0.0-20210806184541-e5e7981a1069
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -28,7 +28,7 @@ type AdapterProxy struct {
 	lastFailCount     int32
 	sendCount         int32
 	successCount      int32
-	status            bool // true for good
+	status            int32 // 1 for good
 	lastSuccessTime   int64
 	lastBlockTime     int64
 	lastCheckTime     int64
@@ -36,7 +36,7 @@ type AdapterProxy struct {
 	pushCallback      func([]byte)
 	onceKeepAlive     sync.Once
 
-	closed bool
+	closed int32
 }
 
 // NewAdapterProxy create an adapter proxy
@@ -67,7 +67,7 @@ func NewAdapterProxy(objName string, point *endpointf.EndpointF, comm *Communica
 	}
 	c.conf = conf
 	c.tarsClient = transport.NewTarsClient(fmt.Sprintf("%s:%d", point.Host, point.Port), c, conf)
-	c.status = true
+	atomic.StoreInt32(&c.status, 1)
 	return c
 }
 
@@ -133,7 +133,7 @@ func (c *AdapterProxy) GetPoint() *endpointf.EndpointF {
 // Close the client
 func (c *AdapterProxy) Close() {
 	c.tarsClient.Close()
-	c.closed = true
+	atomic.StoreInt32(&c.closed, 1)
 }
 
 func (c *AdapterProxy) sendAdd() {
@@ -161,19 +161,19 @@ func (c *AdapterProxy) reset() {
 	atomic.SwapInt64(&c.lastBlockTime, now)
 	atomic.SwapInt64(&c.lastCheckTime, now)
 	atomic.SwapInt64(&c.lastKeepAliveTime, now)
-	c.status = true
+	atomic.StoreInt32(&c.status, 1)
 }
 
 func (c *AdapterProxy) checkActive() (firstTime bool, needCheck bool) {
-	if c.closed {
+	if atomic.LoadInt32(&c.closed) == 1 {
 		return false, false
 	}
 
 	now := time.Now().Unix()
-	if c.status {
+	if atomic.LoadInt32(&c.status) == 1 {
 		//check if healthy，fail 5 times in a row within 5s
 		if (now-c.lastSuccessTime) >= failInterval && c.lastFailCount >= fainN {
-			c.status = false
+			atomic.StoreInt32(&c.status, 0)
 			c.lastBlockTime = now
 			return true, false
 		}
@@ -182,7 +182,7 @@ func (c *AdapterProxy) checkActive() (firstTime bool, needCheck bool) {
 			c.lastBlockTime = now
 			// The number of failures is more than 2 and the failure rate is more than 50%
 			if c.failCount >= overN && (float32(c.failCount)/float32(c.sendCount)) >= failRatio {
-				c.status = false
+				atomic.StoreInt32(&c.status, 0)
 				return true, false
 			}
 			return false, false
@@ -228,7 +228,7 @@ func (c *AdapterProxy) autoKeepAlive() {
 		interval = time.Minute
 	}
 	for range time.NewTicker(interval).C {
-		if c.closed {
+		if atomic.LoadInt32(&c.closed) == 1 {
 			return
 		}
 		c.doKeepAlive()
@@ -236,7 +236,7 @@ func (c *AdapterProxy) autoKeepAlive() {
 }
 
 func (c *AdapterProxy) doKeepAlive() {
-	if c.closed {
+	if atomic.LoadInt32(&c.closed) == 1 {
 		return
 	}
 
@@ -2,9 +2,11 @@ package tars
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -16,7 +18,7 @@ import (
 
 // Admin struct
 type Admin struct {
-	app *application
+	app *Application
 }
 
 type adminFn func(string) (string, error)
@@ -27,7 +29,7 @@ func RegisterAdmin(name string, fn adminFn) {
 }
 
 // RegisterAdmin register admin functions
-func (a *application) RegisterAdmin(name string, fn adminFn) {
+func (a *Application) RegisterAdmin(name string, fn adminFn) {
 	a.adminMethods[name] = fn
 }
 
@@ -38,14 +40,83 @@ func (a *Admin) Shutdown() error {
 	return nil
 }
 
+// objs returns the sorted objs of the tars servers, or the ones in filter.
+func (a *Admin) objs(filter []string) []string {
+	var objs []string
+	for obj := range a.app.goSvrs {
+		if len(filter) > 0 && filter[0] != obj {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	sort.Strings(objs)
+	return objs
+}
+
+// connections lists the live tcp connections and the udp peers of each adapter.
+func (a *Admin) connections(filter []string) string {
+	var b strings.Builder
+	for _, obj := range a.objs(filter) {
+		s := a.app.goSvrs[obj]
+		cfg := s.GetConfig()
+		if cfg.Proto == "udp" {
+			peers := s.UDPPeers()
+			fmt.Fprintf(&b, "[%s] udp %s, %d peers, %d dropped\n", obj, cfg.Address, len(peers), s.NumDrop())
+			for _, p := range peers {
+				fmt.Fprintf(&b, "%s idle=%s packets=%d in=%d out=%d\n", p.RemoteAddr, p.Idle.Truncate(time.Second), p.Packets, p.BytesIn, p.BytesOut)
+			}
+			continue
+		}
+		conns := s.Conns()
+		fmt.Fprintf(&b, "[%s] %s %s, %d connections\n", obj, cfg.Proto, cfg.Address, len(conns))
+		for _, c := range conns {
+			fmt.Fprintf(&b, "%s idle=%s invoke=%d in=%d out=%d", c.RemoteAddr, c.Idle.Truncate(time.Second), c.NumInvoke, c.BytesIn, c.BytesOut)
+			if c.TLS != nil {
+				fmt.Fprintf(&b, " tls=%s cipher=%s", tlsVersionName(c.TLS.Version), tls.CipherSuiteName(c.TLS.CipherSuite))
+				if c.TLS.ServerName != "" {
+					fmt.Fprintf(&b, " sni=%s", c.TLS.ServerName)
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// drainStatus shows the progress of the graceful shutdown of each adapter.
+func (a *Admin) drainStatus(filter []string) string {
+	var b strings.Builder
+	for _, obj := range a.objs(filter) {
+		stat := a.app.goSvrs[obj].DrainStat()
+		fmt.Fprintf(&b, "[%s] draining=%t connections=%d invoke=%d rejected=%d\n", obj, stat.Draining, stat.Conns, stat.NumInvoke, stat.Rejected)
+	}
+	return b.String()
+}
+
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	}
+	return fmt.Sprintf("0x%04x", v)
+}
+
 // Notify handler for cmds from admin
 func (a *Admin) Notify(command string) (string, error) {
 	cmd := strings.Split(command, " ")
 	// report command to notify
-	go ReportNotifyInfo(NotifyNormal, "AdminServant::notify:"+command)
+	go a.app.ReportNotifyInfo(NotifyNormal, "AdminServant::notify:"+command)
 	switch cmd[0] {
 	case "tars.viewversion":
 		return a.app.ServerConfig().Version, nil
+	case "tars.viewconfig":
+		return a.app.effectiveConfig(), nil
 	case "tars.setloglevel":
 		if len(cmd) >= 2 {
 			a.app.appCache.LogLevel = cmd[1]
@@ -71,18 +142,76 @@ func (a *Admin) Notify(command string) (string, error) {
 		return fmt.Sprintf("%s succ", command), nil
 	case "tars.loadconfig":
 		cfg := a.app.ServerConfig()
-		remoteConf := NewRConf(cfg.App, cfg.Server, cfg.BasePath)
+		remoteConf := a.app.newRConf(cfg.App, cfg.Server, cfg.BasePath)
 		_, err := remoteConf.GetConfig(cmd[1])
 		if err != nil {
 			return fmt.Sprintf("Getconfig Error!: %s", cmd[1]), err
 		}
 		return fmt.Sprintf("Getconfig Success!: %s", cmd[1]), nil
 	case "tars.connection":
-		return fmt.Sprintf("%s not support now!", command), nil
+		return a.connections(cmd[1:]), nil
+	case "tars.closeconnection":
+		if len(cmd) < 3 {
+			return fmt.Sprintf("%s failed: usage tars.closeconnection <obj> <remote addr>", command), nil
+		}
+		s, ok := a.app.goSvrs[cmd[1]]
+		if !ok {
+			return fmt.Sprintf("%s failed: unknown obj [%s]!", command, cmd[1]), nil
+		}
+		if !s.CloseConn(cmd[2]) {
+			return fmt.Sprintf("%s failed: no connection from [%s]!", command, cmd[2]), nil
+		}
+		return fmt.Sprintf("%s succ", command), nil
+	case "tars.closeidleconnection":
+		if len(cmd) < 2 {
+			return fmt.Sprintf("%s failed: usage tars.closeidleconnection <idle seconds> [obj]", command), nil
+		}
+		idle, err := strconv.Atoi(cmd[1])
+		if err != nil || idle < 0 {
+			return fmt.Sprintf("%s failed: invalid idle seconds [%s]!", command, cmd[1]), nil
+		}
+		var n int
+		for _, obj := range a.objs(cmd[2:]) {
+			n += a.app.goSvrs[obj].CloseIdleConns(time.Duration(idle) * time.Second)
+		}
+		return fmt.Sprintf("%s succ, %d connections closed", command, n), nil
+	case "tars.drainstatus":
+		return a.drainStatus(cmd[1:]), nil
+	case "tars.endpoints":
+		var obj string
+		if len(cmd) > 1 {
+			obj = cmd[1]
+		}
+		return a.app.dumpEndpoints(obj), nil
+	case "tars.refreshendpoints":
+		if len(cmd) < 2 {
+			return fmt.Sprintf("%s failed: usage tars.refreshendpoints <obj>", command), nil
+		}
+		n, err := a.app.refreshEndpoints(cmd[1])
+		if err != nil {
+			return fmt.Sprintf("%s failed: %v", command, err), nil
+		}
+		return fmt.Sprintf("%s succ, %d endpoint managers refreshed", command, n), nil
+	case "tars.resetendpoints":
+		if len(cmd) < 2 {
+			return fmt.Sprintf("%s failed: usage tars.resetendpoints <obj>", command), nil
+		}
+		return fmt.Sprintf("%s succ, %d endpoint managers reset", command, a.app.resetEndpoints(cmd[1])), nil
 	case "tars.gracerestart":
-		a.app.graceRestart()
-		return "restart gracefully!", nil
+		if atomic.LoadInt32(&graceRestarting) == 1 {
+			return "grace restart failed: grace restart is in progress", nil
+		}
+		// the new process is ready within the grace restart timeout, which is longer than the timeout of the admin client,
+		// so the result is reported by the notify and tars.gracerestartstatus
+		go a.app.graceRestart()
+		return "grace restart started, see tars.gracerestartstatus for the result", nil
+	case "tars.gracerestartstatus":
+		return graceRestartStatus(), nil
 	case "tars.pprof":
+		// the admin http debug console serves pprof persistently
+		if addr := a.app.ServerConfig().AdminHttpAddress; addr != "" {
+			return "see http://" + addr + "/debug/pprof/", nil
+		}
 		port := ":8080"
 		timeout := time.Second * 600
 		if len(cmd) > 1 {
@@ -0,0 +1,237 @@
+package tars
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/util/debug"
+	"github.com/TarsCloud/TarsGo/tars/util/grace"
+	"github.com/TarsCloud/TarsGo/tars/util/rogger"
+)
+
+// adminCommands are the built-in commands of Admin.Notify.
+var adminCommands = []string{
+	"tars.viewversion",
+	"tars.viewconfig",
+	"tars.setloglevel",
+	"tars.dumpstack",
+	"tars.loadconfig",
+	"tars.connection",
+	"tars.closeconnection",
+	"tars.closeidleconnection",
+	"tars.drainstatus",
+	"tars.endpoints",
+	"tars.refreshendpoints",
+	"tars.resetendpoints",
+	"tars.gracerestart",
+	"tars.gracerestartstatus",
+	"tars.pprof",
+}
+
+var startTime = time.Now()
+
+// AdminHttpHandler returns the http handler of the admin debug console, which serves
+//
+//	/debug/pprof/        the pprof profiles
+//	/debug/vars          the runtime stats in json
+//	/admin/              the admin commands, POST /admin/tars.setloglevel?arg=DEBUG runs tars.setloglevel DEBUG
+//	/loglevel            the log level, which is set by POST /loglevel?level=DEBUG
+//	/goroutines          the stacks of all goroutines, which are dumped to file by POST /goroutines like tars.dumpstack
+//	/config              the loaded config
+//	/endpoints           the client endpoints, ?obj=App.Server.Obj for the obj
+//	/connections         the server connections, ?obj=App.Server.Obj for the obj
+//	/healthz, /readyz    the liveness and readiness
+//
+// The requests are authorized by the token in the Authorization: Bearer header or the basic auth
+// if admin-http-token or admin-http-user is configured, otherwise only /healthz and /readyz are served.
+func AdminHttpHandler() http.Handler {
+	return defaultApp.adminHttpHandler()
+}
+
+func (a *Application) adminHttpHandler() http.Handler {
+	adm := &Admin{app: a}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", a.serveVars)
+	mux.HandleFunc("/admin/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/admin/")
+		if name == "" {
+			writeText(w, strings.Join(a.adminCommandNames(), "\n")+"\n")
+			return
+		}
+		if r.Method != http.MethodPost {
+			// the commands change the server, which must not be run by the links
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.ParseForm()
+		command := strings.Join(append([]string{name}, r.Form["arg"]...), " ")
+		ret, err := adm.Notify(command)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeText(w, ret+"\n")
+	})
+	mux.HandleFunc("/loglevel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			ret, _ := adm.Notify("tars.setloglevel " + r.FormValue("level"))
+			writeText(w, ret+"\n")
+			return
+		}
+		writeText(w, rogger.GetLevel()+"\n")
+	})
+	mux.HandleFunc("/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			ret, _ := adm.Notify("tars.dumpstack")
+			writeText(w, ret+"\n")
+			return
+		}
+		writeText(w, fmt.Sprintf("current running goroutine num: %d\n\n%s", runtime.NumGoroutine(), debug.Stack(true)))
+	})
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		if a.conf == nil {
+			http.Error(w, "no config loaded", http.StatusNotFound)
+			return
+		}
+		writeText(w, a.conf.ToString())
+	})
+	mux.HandleFunc("/endpoints", func(w http.ResponseWriter, r *http.Request) {
+		writeText(w, a.dumpEndpoints(r.FormValue("obj"))+"\n")
+	})
+	mux.HandleFunc("/connections", func(w http.ResponseWriter, r *http.Request) {
+		var filter []string
+		if obj := r.FormValue("obj"); obj != "" {
+			filter = append(filter, obj)
+		}
+		writeText(w, adm.connections(filter))
+	})
+	health := a.healthHandler()
+	mux.Handle("/healthz", health)
+	mux.Handle("/readyz", health)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		writeText(w, "/debug/pprof/\n/debug/vars\n/admin/\n/loglevel\n/goroutines\n/config\n/endpoints\n/connections\n/healthz\n/readyz\n")
+	})
+	return a.adminAuth(mux)
+}
+
+// adminPublicPaths are served without the credentials, which expose nothing but the index and the health.
+var adminPublicPaths = map[string]bool{"/": true, "/healthz": true, "/readyz": true}
+
+// adminAuth authorizes the requests by the token in the Authorization: Bearer header, or by the basic auth.
+// Only adminPublicPaths are served if neither is configured, since the profiles, the stacks and the command line
+// may contain the secrets.
+func (a *Application) adminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := a.svrCfg
+		if cfg == nil || (cfg.AdminHttpToken == "" && cfg.AdminHttpUser == "") {
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !adminPublicPaths[r.URL.Path] {
+				http.Error(w, "forbidden without admin-http-token or admin-http-user", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if cfg.AdminHttpToken != "" {
+			auth := r.Header.Get("Authorization")
+			if strings.HasPrefix(auth, "Bearer ") &&
+				subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(cfg.AdminHttpToken)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if cfg.AdminHttpUser != "" {
+			if user, password, ok := r.BasicAuth(); ok &&
+				subtle.ConstantTimeCompare([]byte(user), []byte(cfg.AdminHttpUser)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(password), []byte(cfg.AdminHttpPassword)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="tars admin"`)
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// adminCommandNames returns the built-in and the registered admin commands.
+func (a *Application) adminCommandNames() []string {
+	names := append([]string(nil), adminCommands...)
+	var registered []string
+	for name := range a.adminMethods {
+		registered = append(registered, name)
+	}
+	sort.Strings(registered)
+	return append(names, registered...)
+}
+
+// serveVars writes the runtime stats in json.
+func (a *Application) serveVars(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	vars := map[string]interface{}{
+		"cmdline":    os.Args,
+		"pid":        os.Getpid(),
+		"goversion":  runtime.Version(),
+		"uptime":     time.Since(startTime).String(),
+		"goroutines": runtime.NumGoroutine(),
+		"cpus":       runtime.NumCPU(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
+		"memstats":   mem,
+	}
+	if cfg := a.svrCfg; cfg != nil {
+		vars["version"] = cfg.Version
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(vars)
+}
+
+func writeText(w http.ResponseWriter, s string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, s)
+}
+
+// ServeAdminHttp serves the admin debug console on addr.
+func ServeAdminHttp(addr string) error {
+	return defaultApp.ServeAdminHttp(addr)
+}
+
+// ServeAdminHttp serves the admin debug console on addr, it returns nil after the application shuts down.
+func (a *Application) ServeAdminHttp(addr string) error {
+	ln, err := grace.CreateListener("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return a.serveDebug(ln, a.adminHttpHandler())
+}
+
+func (a *Application) serveAdminHttp() {
+	addr := a.svrCfg.AdminHttpAddress
+	TLOG.Infof("admin http server start on %s", addr)
+	if a.svrCfg.AdminHttpToken == "" && a.svrCfg.AdminHttpUser == "" {
+		TLOG.Warnf("admin http server on %s only serves the health without admin-http-token or admin-http-user", addr)
+	}
+	if err := a.ServeAdminHttp(addr); err != nil {
+		TLOG.Errorf("admin http server on %s stop: %v", addr, err)
+		return
+	}
+	TLOG.Infof("admin http server on %s stop", addr)
+}
@@ -0,0 +1,150 @@
+package tars
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/transport"
+	"github.com/TarsCloud/TarsGo/tars/util/rogger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHttp(t *testing.T) {
+	cfg := newServerConfig()
+	cfg.Version = "1.0.0"
+	cfg.AdminHttpToken = "secret"
+	cfg.AdminHttpUser = "admin"
+	cfg.AdminHttpPassword = "pass"
+	app := &Application{
+		svrCfg:       cfg,
+		goSvrs:       map[string]*transport.TarsServer{},
+		httpSvrs:     map[string]*http.Server{},
+		adminMethods: map[string]adminFn{"app.hello": func(cmd string) (string, error) { return "hello " + cmd, nil }},
+		health:       newHealth(),
+	}
+	// keep the config above
+	app.initOnce.Do(func() {})
+	h := app.adminHttpHandler()
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	authed := func(method, target string) *http.Request {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Authorization", "Bearer secret")
+		return r
+	}
+
+	// unauthorized
+	w := serve(httptest.NewRequest("GET", "/admin/", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="tars admin"`, w.Header().Get("WWW-Authenticate"))
+	r := httptest.NewRequest("GET", "/admin/", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	assert.Equal(t, http.StatusUnauthorized, serve(r).Code)
+	// the token in the query leaks into the logs
+	assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest("GET", "/admin/?token=secret", nil)).Code)
+
+	// token and basic auth
+	r = httptest.NewRequest("GET", "/admin/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	w = serve(r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "tars.viewversion\n")
+	assert.True(t, strings.HasSuffix(w.Body.String(), "app.hello\n"))
+	r = httptest.NewRequest("POST", "/admin/app.hello?arg=a&arg=b", nil)
+	r.SetBasicAuth("admin", "pass")
+	w = serve(r)
+	assert.Equal(t, "hello app.hello a b\n", w.Body.String())
+	r = authed("GET", "/admin/tars.viewversion")
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(r).Code)
+	r = authed("POST", "/admin/tars.viewversion")
+	assert.Equal(t, "1.0.0\n", serve(r).Body.String())
+
+	// log level
+	defer rogger.SetLevel(rogger.ERROR)
+	r = authed("POST", "/loglevel?level=DEBUG")
+	assert.Equal(t, "tars.setloglevel DEBUG succ\n", serve(r).Body.String())
+	r = authed("GET", "/loglevel")
+	assert.Equal(t, "DEBUG\n", serve(r).Body.String())
+
+	r = authed("GET", "/goroutines")
+	assert.Contains(t, serve(r).Body.String(), "TestAdminHttp")
+
+	r = authed("GET", "/debug/vars")
+	var vars map[string]interface{}
+	assert.NoError(t, json.Unmarshal(serve(r).Body.Bytes(), &vars))
+	assert.Equal(t, "1.0.0", vars["version"])
+	assert.Contains(t, vars, "memstats")
+
+	r = authed("GET", "/debug/pprof/")
+	assert.Equal(t, http.StatusOK, serve(r).Code)
+	r = authed("GET", "/readyz")
+	assert.Equal(t, http.StatusOK, serve(r).Code)
+	r = authed("GET", "/config")
+	assert.Equal(t, http.StatusNotFound, serve(r).Code)
+
+	r = authed("GET", "/goroutines?file=1")
+	assert.Contains(t, serve(r).Body.String(), "current running goroutine num")
+}
+
+// TestAdminHttpReadOnly test the admin http console without the credentials, which only serves the health.
+func TestAdminHttpWithoutCredentials(t *testing.T) {
+	app := &Application{
+		svrCfg:       newServerConfig(),
+		goSvrs:       map[string]*transport.TarsServer{},
+		httpSvrs:     map[string]*http.Server{},
+		adminMethods: map[string]adminFn{},
+		health:       newHealth(),
+	}
+	app.initOnce.Do(func() {})
+	h := app.adminHttpHandler()
+	serve := func(method, target string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, serve("GET", "/"))
+	assert.Equal(t, http.StatusOK, serve("GET", "/healthz"))
+	assert.Equal(t, http.StatusOK, serve("GET", "/readyz"))
+	for _, path := range []string{"/admin/", "/loglevel", "/endpoints", "/connections", "/goroutines", "/debug/vars",
+		"/debug/pprof/", "/debug/pprof/cmdline", "/debug/pprof/profile", "/debug/pprof/trace"} {
+		assert.Equal(t, http.StatusForbidden, serve("GET", path), path)
+	}
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/admin/tars.gracerestart"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/loglevel?level=DEBUG"))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/goroutines"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/config"))
+	assert.Equal(t, "ERROR", rogger.GetLevel())
+}
+
+// TestServeAdminHttpShutdown test the admin http server is shut down with the application.
+func TestServeAdminHttpShutdown(t *testing.T) {
+	app := NewApplication(WithServer("TestApp", "AdminHttpServer"), WithLogPath(t.TempDir()))
+	app.init()
+	done := make(chan error, 1)
+	go func() {
+		done <- app.ServeAdminHttp("127.0.0.1:17994")
+	}()
+	assert.Eventually(t, func() bool {
+		rsp, err := http.Get("http://127.0.0.1:17994/")
+		if err != nil {
+			return false
+		}
+		rsp.Body.Close()
+		return rsp.StatusCode == http.StatusOK
+	}, 3*time.Second, 10*time.Millisecond)
+	app.shutdownDebugServers()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the admin http server is not shut down")
+	}
+}
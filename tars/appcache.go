@@ -18,10 +18,21 @@ type ObjCache struct {
 	InactiveEndpoints []endpointf.EndpointF
 }
 
+// ConfigCache is the metadata of the remote config file saved in the BasePath, which is written beside the file
+// as filename.tarsdat and used to load the last fetched copy when the config servant is unreachable.
+type ConfigCache struct {
+	TarsVersion string
+	ModifyTime  string
+	App         string
+	Server      string
+	Filename    string
+	MD5         string
+}
+
 func GetAppCache() AppCache {
 	return defaultApp.AppCache()
 }
 
-func (a *application) AppCache() AppCache {
-	return defaultApp.appCache
+func (a *Application) AppCache() AppCache {
+	return a.appCache
 }
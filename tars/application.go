@@ -6,8 +6,11 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -19,12 +22,14 @@ import (
 
 	"github.com/TarsCloud/TarsGo/tars/protocol"
 	"github.com/TarsCloud/TarsGo/tars/protocol/res/adminf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/healthf"
 	"github.com/TarsCloud/TarsGo/tars/transport"
 	"github.com/TarsCloud/TarsGo/tars/util/conf"
 	"github.com/TarsCloud/TarsGo/tars/util/endpoint"
 	"github.com/TarsCloud/TarsGo/tars/util/grace"
 	"github.com/TarsCloud/TarsGo/tars/util/rogger"
 	"github.com/TarsCloud/TarsGo/tars/util/ssl"
+	utilSync "github.com/TarsCloud/TarsGo/tars/util/sync"
 	"github.com/TarsCloud/TarsGo/tars/util/tools"
 	"go.uber.org/automaxprocs/maxprocs"
 )
@@ -33,7 +38,12 @@ type destroyableImp interface {
 	Destroy()
 }
 
-type application struct {
+// Application is a tars server with its own config, servants, communicator, endpoint managers,
+// stat and property reports and shutdown, several of which can run in one process.
+// The package level functions like AddServant and Run use the default application.
+type Application struct {
+	configPath         string
+	configFlag         bool
 	conf               *conf.Conf
 	svrCfg             *serverConfig
 	cltCfg             *clientConfig
@@ -44,18 +54,42 @@ type application struct {
 	httpSvrs           map[string]*http.Server
 	serList            []string
 	objRunList         []string
+	descriptors        map[string]string
 	clientObjInfo      map[string]map[string]string
 	clientObjTlsConfig map[string]*tls.Config
 	clientTlsConfig    *tls.Config
+	configOpts         []Option
+	overlayPath        string
+	configOverrides    []configOverride
+	adapterOpts        map[string][]ServerConfOption
 
-	defaultRConf *RConf
-	onceRConf    sync.Once
+	defaultRConf  *RConf
+	onceRConf     sync.Once
+	configWatcher configWatcher
 
 	appCache         AppCache
 	destroyableObjs  []destroyableImp
+	lifecycle        lifecycle
 	adminMethods     map[string]adminFn
 	allFilters       *filters
 	dispatchReporter DispatchReporter
+	health           *health
+
+	statReport       *StatFHelper
+	statInited       chan struct{}
+	statInitOnce     utilSync.Once
+	proHelper        *PropertyReportHelper
+	proOnce          utilSync.Once
+	gManager         *globalManager
+	gManagerMu       sync.Mutex
+	gManagerInitOnce sync.Once
+	metrics          *metricsExporter
+	sinks            appSinks
+	// debugSvrs are the http servers of the metrics, health and admin, which are shut down after the drain
+	debugSvrsMu sync.Mutex
+	debugSvrs   []*http.Server
+	// tlog is the framework log of the application, which is TLOG of the default application
+	tlog *rogger.Logger
 
 	shutdown          chan bool
 	isShutdownByAdmin int32
@@ -68,7 +102,7 @@ var (
 	// TLOG is the logger for tars framework.
 	TLOG = rogger.GetLogger("TLOG")
 
-	defaultApp       *application
+	defaultApp       *Application
 	ServerConfigPath string
 )
 
@@ -76,16 +110,37 @@ func init() {
 	_, _ = maxprocs.Set(maxprocs.Logger(TLOG.Infof))
 	rogger.SetLevel(rogger.ERROR)
 
-	defaultApp = &application{
+	// the default application reads the config file from the --config flag
+	defaultApp = newApplication(withConfigFlag())
+}
+
+// NewApplication returns an application isolated from the default one and the others,
+// which is configured by opts instead of the --config flag.
+func NewApplication(opts ...Option) *Application {
+	return newApplication(opts...)
+}
+
+func newApplication(opts ...Option) *Application {
+	a := &Application{
 		tarsConfig:         make(map[string]*transport.TarsServerConf),
 		goSvrs:             make(map[string]*transport.TarsServer),
 		httpSvrs:           make(map[string]*http.Server),
+		descriptors:        make(map[string]string),
 		clientObjInfo:      make(map[string]map[string]string),
 		clientObjTlsConfig: make(map[string]*tls.Config),
+		adapterOpts:        make(map[string][]ServerConfOption),
 		adminMethods:       make(map[string]adminFn),
 		shutdown:           make(chan bool, 1),
 		allFilters:         &filters{},
+		health:             newHealth(),
+		statInited:         make(chan struct{}, 1),
+		metrics:            newMetricsExporter(),
+		tlog:               TLOG,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // GetConf Get server conf.Conf config
@@ -94,44 +149,72 @@ func GetConf() *conf.Conf {
 }
 
 // GetConf Get server conf.Conf config
-func (a *application) GetConf() *conf.Conf {
+func (a *Application) GetConf() *conf.Conf {
 	a.init()
 	return a.conf
 }
 
-func (a *application) init() {
+func (a *Application) init() {
 	a.initOnce.Do(func() {
 		a.initConfig()
 	})
 }
 
-func (a *application) initConfig() {
+func (a *Application) initConfig() {
 	defer func() {
 		go func() {
-			_ = statInitOnce.Do(func() error {
+			_ = a.statInitOnce.Do(func() error {
 				return initReport(a)
 			})
 		}()
+		if a.cltCfg.MetricsAddress != "" {
+			go a.serveMetrics()
+		}
+		if a.svrCfg.HealthAddress != "" {
+			go a.serveHealth()
+		}
 	}()
 	a.svrCfg = newServerConfig()
 	a.cltCfg = newClientConfig()
-	if ServerConfigPath == "" {
-		svrFlag := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
-		svrFlag.StringVar(&ServerConfigPath, "config", "", "server config path")
-		_ = svrFlag.Parse(os.Args[1:])
+	configPath := a.configPath
+	if a.configFlag && configPath == "" {
+		if ServerConfigPath == "" {
+			svrFlag := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			svrFlag.StringVar(&ServerConfigPath, "config", "", "server config path")
+			_ = svrFlag.Parse(os.Args[1:])
+		}
+		configPath = ServerConfigPath
 	}
 
-	if len(ServerConfigPath) == 0 {
+	overlay := a.hasConfigOverlay()
+	if len(configPath) == 0 && !overlay && len(a.configOpts) == 0 {
 		return
 	}
 
-	c, err := conf.NewConf(ServerConfigPath)
-	if err != nil {
-		TLOG.Errorf("Parse server config fail %v", err)
-		return
+	if len(configPath) != 0 || overlay {
+		c := conf.New()
+		if len(configPath) != 0 {
+			if err := c.InitFromFile(configPath); err != nil {
+				a.tlog.Errorf("Parse server config fail %v", err)
+				return
+			}
+		}
+		if err := a.overlayConf(c); err != nil {
+			a.tlog.Errorf("Overlay server config fail %v", err)
+			return
+		}
+		a.conf = c
+		a.loadConf(c)
+	}
+	// the options override the config file
+	for _, opt := range a.configOpts {
+		opt(a)
 	}
-	defaultApp.conf = c
+	a.setupConfig()
+}
 
+// loadConf loads the server and client config from the tars config file.
+func (a *Application) loadConf(c *conf.Conf) {
 	// Config.go
 	// init server config
 	if strings.EqualFold(c.GetString("/tars/application<enableset>"), "Y") {
@@ -157,29 +240,6 @@ func (a *application) initConfig() {
 	a.svrCfg.DataPath = sMap["datapath"]
 	a.svrCfg.Log = sMap["log"]
 
-	// add version info
-	a.svrCfg.Version = Version
-	// add adapters config
-	a.svrCfg.Adapters = make(map[string]adapterConfig)
-
-	cachePath := filepath.Join(a.svrCfg.DataPath, a.svrCfg.Server) + ".tarsdat"
-	if cacheData, err := os.ReadFile(cachePath); err == nil {
-		_ = json.Unmarshal(cacheData, &defaultApp.appCache)
-	}
-
-	if a.svrCfg.LogLevel == "" {
-		a.svrCfg.LogLevel = a.appCache.LogLevel
-	} else {
-		a.appCache.LogLevel = a.svrCfg.LogLevel
-	}
-	rogger.SetLevel(rogger.StringToLevel(a.svrCfg.LogLevel))
-	if a.svrCfg.LogPath != "" {
-		_ = TLOG.SetFileRoller(a.svrCfg.LogPath+"/"+a.svrCfg.App+"/"+a.svrCfg.Server, 10, 100)
-	}
-
-	// cache
-	defaultApp.appCache.TarsVersion = Version
-
 	// add timeout config
 	a.svrCfg.AcceptTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<accepttimeout>", AcceptTimeout))
 	a.svrCfg.ReadTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<readtimeout>", ReadTimeout))
@@ -189,6 +249,8 @@ func (a *application) initConfig() {
 	a.svrCfg.ZombieTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<zombietimeout>", ZombieTimeout))
 	a.svrCfg.QueueCap = c.GetIntWithDef("/tars/application/server<queuecap>", QueueCap)
 	a.svrCfg.GracedownTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<gracedowntimeout>", GracedownTimeout))
+	a.svrCfg.HookTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<hooktimeout>", HookTimeout))
+	a.svrCfg.GraceRestartTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<gracerestarttimeout>", GraceRestartTimeout))
 
 	// add tcp config
 	a.svrCfg.TCPReadBuffer = c.GetIntWithDef("/tars/application/server<tcpreadbuffer>", TCPReadBuffer)
@@ -201,21 +263,24 @@ func (a *application) initConfig() {
 	a.svrCfg.StatReportInterval = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<statreportinterval>", StatReportInterval))
 	a.svrCfg.MainLoopTicker = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<mainloopticker>", MainLoopTicker))
 	a.svrCfg.StatReportChannelBufLen = c.GetInt32WithDef("/tars/application/server<statreportchannelbuflen>", StatReportChannelBufLen)
+	a.svrCfg.ConfigPollInterval = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/server<configpollinterval>", ConfigPollInterval))
 	// maxPackageLength
 	a.svrCfg.MaxPackageLength = c.GetIntWithDef("/tars/application/server<maxPackageLength>", MaxPackageLength)
-	protocol.SetMaxPackageLength(a.svrCfg.MaxPackageLength)
+	// reflection
+	a.svrCfg.Reflection = c.GetBoolWithDef("/tars/application/server<reflection>", false)
+	// health
+	a.svrCfg.HealthAddress = c.GetString("/tars/application/server<health-address>")
+	// admin http debug console
+	a.svrCfg.AdminHttpToken = c.GetString("/tars/application/server<admin-http-token>")
+	a.svrCfg.AdminHttpUser = c.GetString("/tars/application/server<admin-http-user>")
+	a.svrCfg.AdminHttpPassword = c.GetString("/tars/application/server<admin-http-password>")
 	// tls
 	a.svrCfg.Key = c.GetString("/tars/application/server<key>")
 	a.svrCfg.Cert = c.GetString("/tars/application/server<cert>")
-	var tlsConfig *tls.Config
 	if a.svrCfg.Key != "" && a.svrCfg.Cert != "" {
 		a.svrCfg.CA = c.GetString("/tars/application/server<ca>")
 		a.svrCfg.VerifyClient = c.GetStringWithDef("/tars/application/server<verifyclient>", "0") != "0"
 		a.svrCfg.Ciphers = c.GetString("/tars/application/server<ciphers>")
-		tlsConfig, err = ssl.NewServerTlsConfig(a.svrCfg.CA, a.svrCfg.Cert, a.svrCfg.Key, a.svrCfg.VerifyClient, a.svrCfg.Ciphers)
-		if err != nil {
-			panic(err)
-		}
 	}
 	a.svrCfg.SampleRate = c.GetFloatWithDef("/tars/application/server<samplerate>", 0)
 	a.svrCfg.SampleType = c.GetString("/tars/application/server<sampletype>")
@@ -242,6 +307,8 @@ func (a *application) initConfig() {
 	a.cltCfg.ClientDialTimeout = tools.ParseTimeOut(c.GetIntWithDef("/tars/application/client<clientdialtimeout>", ClientDialTimeout))
 	a.cltCfg.ReqDefaultTimeout = c.GetInt32WithDef("/tars/application/client<reqdefaulttimeout>", ReqDefaultTimeout)
 	a.cltCfg.ObjQueueMax = c.GetInt32WithDef("/tars/application/client<objqueuemax>", ObjQueueMax)
+	a.cltCfg.MetricsAddress = c.GetString("/tars/application/client<metrics-address>")
+	a.cltCfg.MetricsPath = c.GetStringWithDef("/tars/application/client<metrics-path>", MetricsPath)
 	ca := c.GetString("/tars/application/client<ca>")
 	if ca != "" {
 		cert := c.GetString("/tars/application/client<cert>")
@@ -251,7 +318,7 @@ func (a *application) initConfig() {
 		if err != nil {
 			panic(err)
 		}
-		defaultApp.clientTlsConfig = clientTlsConfig
+		a.clientTlsConfig = clientTlsConfig
 	}
 
 	serList := c.GetDomain("/tars/application/server")
@@ -260,15 +327,14 @@ func (a *application) initConfig() {
 		end := endpoint.Parse(endString)
 		svrObj := c.GetString("/tars/application/server/" + adapter + "<servant>")
 		proto := c.GetString("/tars/application/server/" + adapter + "<protocol>")
-		queuecap := c.GetIntWithDef("/tars/application/server/"+adapter+"<queuecap>", a.svrCfg.QueueCap)
 		threads := c.GetInt("/tars/application/server/" + adapter + "<threads>")
+		udpReaders := c.GetIntWithDef("/tars/application/server/"+adapter+"<udpreaders>", 1)
 		a.svrCfg.Adapters[adapter] = adapterConfig{end, proto, svrObj, threads}
-		host := end.Host
-		if end.Bind != "" {
-			host = end.Bind
-		}
 		var opts []ServerConfOption
-		opts = append(opts, WithQueueCap(queuecap))
+		if c.GetString("/tars/application/server/"+adapter+"<queuecap>") != "" {
+			opts = append(opts, WithQueueCap(c.GetInt("/tars/application/server/"+adapter+"<queuecap>")))
+		}
+		opts = append(opts, WithUDPReaders(udpReaders))
 		if end.IsSSL() {
 			key := c.GetString("/tars/application/server/" + adapter + "<key>")
 			cert := c.GetString("/tars/application/server/" + adapter + "<cert>")
@@ -276,29 +342,24 @@ func (a *application) initConfig() {
 				ca = c.GetString("/tars/application/server/" + adapter + "<ca>")
 				verifyClient := c.GetString("/tars/application/server/"+adapter+"<verifyclient>") != "0"
 				ciphers := c.GetString("/tars/application/server/" + adapter + "<ciphers>")
-				var adpTlsConfig *tls.Config
-				adpTlsConfig, err = ssl.NewServerTlsConfig(ca, cert, key, verifyClient, ciphers)
+				adpTlsConfig, err := ssl.NewServerTlsConfig(ca, cert, key, verifyClient, ciphers)
 				if err != nil {
 					panic(err)
 				}
 				opts = append(opts, WithTlsConfig(adpTlsConfig))
-			} else {
-				// common tls.Config
-				opts = append(opts, WithTlsConfig(tlsConfig))
 			}
 		}
-		a.tarsConfig[svrObj] = newTarsServerConf(end.Proto, fmt.Sprintf("%s:%d", host, end.Port), a.svrCfg, opts...)
+		a.adapterOpts[adapter] = opts
 	}
 	a.serList = serList
 
-	TLOG.Debug("config add ", a.tarsConfig)
-
-	if len(a.svrCfg.Local) > 0 {
-		localPoint := endpoint.Parse(a.svrCfg.Local)
-		// 管理端口不启动协程池
-		a.tarsConfig["AdminObj"] = newTarsServerConf(localPoint.Proto, fmt.Sprintf("%s:%d", localPoint.Host, localPoint.Port), a.svrCfg, WithMaxInvoke(0))
-		a.svrCfg.Adapters["AdminAdapter"] = adapterConfig{localPoint, localPoint.Proto, "AdminObj", 1}
-		RegisterAdmin(rogger.Admin, rogger.HandleDyeingAdmin)
+	// the admin http debug console is bound to the host of the local adapter
+	if port := c.GetString("/tars/application/server<admin-http-port>"); port != "" {
+		host := "127.0.0.1"
+		if len(a.svrCfg.Local) > 0 {
+			host = endpoint.Parse(a.svrCfg.Local).Host
+		}
+		a.svrCfg.AdminHttpAddress = net.JoinHostPort(host, port)
 	}
 
 	auths := c.GetDomain("/tars/application/client")
@@ -312,8 +373,7 @@ func (a *application) initConfig() {
 		authInfo["ciphers"] = c.GetString("/tars/application/client/" + objName + "<ciphers>")
 		a.clientObjInfo[objName] = authInfo
 		if authInfo["ca"] != "" {
-			var objTlsConfig *tls.Config
-			objTlsConfig, err = ssl.NewClientTlsConfig(authInfo["ca"], authInfo["cert"], authInfo["key"], authInfo["ciphers"])
+			objTlsConfig, err := ssl.NewClientTlsConfig(authInfo["ca"], authInfo["cert"], authInfo["key"], authInfo["ciphers"])
 			if err != nil {
 				panic(err)
 			}
@@ -322,37 +382,132 @@ func (a *application) initConfig() {
 	}
 }
 
+// setupConfig sets up the log, the app cache and the servers of the adapters by the config,
+// which is loaded from the config file and set by the options.
+func (a *Application) setupConfig() {
+	// add version info
+	a.svrCfg.Version = Version
+
+	cachePath := filepath.Join(a.svrCfg.DataPath, a.svrCfg.Server) + ".tarsdat"
+	if cacheData, err := os.ReadFile(cachePath); err == nil {
+		_ = json.Unmarshal(cacheData, &a.appCache)
+	}
+
+	if a.svrCfg.LogLevel == "" {
+		a.svrCfg.LogLevel = a.appCache.LogLevel
+	} else {
+		a.appCache.LogLevel = a.svrCfg.LogLevel
+	}
+	logPath := a.svrCfg.LogPath + "/" + a.svrCfg.App + "/" + a.svrCfg.Server
+	if a == defaultApp {
+		// the log level and TLOG are shared by the process, which are set by the default application
+		rogger.SetLevel(rogger.StringToLevel(a.svrCfg.LogLevel))
+		if a.svrCfg.LogPath != "" {
+			_ = TLOG.SetFileRoller(logPath, 10, 100)
+		}
+	} else if a.svrCfg.LogPath != "" {
+		a.tlog = rogger.NewLogger("TLOG")
+		_ = a.tlog.SetFileRoller(logPath, 10, 100)
+	}
+
+	// cache
+	a.appCache.TarsVersion = Version
+
+	protocol.SetMaxPackageLength(a.svrCfg.MaxPackageLength)
+
+	// the common tls.Config of the ssl adapters
+	var tlsConfig *tls.Config
+	if a.svrCfg.Key != "" && a.svrCfg.Cert != "" {
+		var err error
+		tlsConfig, err = ssl.NewServerTlsConfig(a.svrCfg.CA, a.svrCfg.Cert, a.svrCfg.Key, a.svrCfg.VerifyClient, a.svrCfg.Ciphers)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for _, adapter := range a.serList {
+		adp := a.svrCfg.Adapters[adapter]
+		end := adp.Endpoint
+		host := end.Host
+		if end.Bind != "" {
+			host = end.Bind
+		}
+		cfg := newTarsServerConf(end.Proto, fmt.Sprintf("%s:%d", host, end.Port), a.svrCfg, a.adapterOpts[adapter]...)
+		if end.IsSSL() && cfg.TlsConfig == nil {
+			cfg.TlsConfig = tlsConfig
+		}
+		a.tarsConfig[adp.Obj] = cfg
+	}
+
+	a.tlog.Debug("config add ", a.tarsConfig)
+
+	if len(a.svrCfg.Local) > 0 {
+		localPoint := endpoint.Parse(a.svrCfg.Local)
+		// 管理端口不启动协程池
+		a.tarsConfig["AdminObj"] = newTarsServerConf(localPoint.Proto, fmt.Sprintf("%s:%d", localPoint.Host, localPoint.Port), a.svrCfg, WithMaxInvoke(0))
+		a.svrCfg.Adapters["AdminAdapter"] = adapterConfig{localPoint, localPoint.Proto, "AdminObj", 1}
+		a.RegisterAdmin(rogger.Admin, rogger.HandleDyeingAdmin)
+	}
+}
+
 func Run() {
 	defaultApp.Run()
 }
 
 // Run the application
-func (a *application) Run() {
-	defer rogger.FlushLogger()
+func (a *Application) Run() {
+	if a == defaultApp {
+		// the logs are shared by the applications, which are flushed when the default one stops
+		defer rogger.FlushLogger()
+	}
 	a.isShutdowning = 0
+	// the process started by grace restart serves after all the applications are ready, and the parent process serves until then
+	handover := os.Getenv("GRACE_RESTART") == "1"
+	if handover {
+		processHandover.add()
+	}
 	a.init()
-	<-statInited
+	<-a.statInited
+
+	err := a.runHooks(hookInit, true)
+	if err == nil {
+		err = a.initServants()
+	}
+	if err != nil {
+		if handover {
+			processHandover.ready(err, 0)
+		}
+		a.teerDown(fmt.Errorf("init failed: %v", err))
+		return
+	}
 
 	for _, env := range os.Environ() {
 		if strings.HasPrefix(env, grace.InheritFdPrefix) {
-			TLOG.Infof("env %s", env)
+			a.tlog.Infof("env %s", env)
 		}
 	}
 
 	// add adminF
-	if _, ok := defaultApp.tarsConfig["AdminObj"]; ok {
+	if _, ok := a.tarsConfig["AdminObj"]; ok {
 		adf := new(adminf.AdminF)
-		ad := new(Admin)
-		AddServant(adf, ad, "AdminObj")
+		ad := &Admin{app: a}
+		a.AddServant(adf, ad, "AdminObj")
+	}
+	if a.svrCfg.AdminHttpAddress != "" {
+		go a.serveAdminHttp()
 	}
 
+	serving := make(chan struct{})
+	if !handover {
+		close(serving)
+	}
 	lisDone := &sync.WaitGroup{}
-	for _, obj := range defaultApp.objRunList {
-		if s, ok := defaultApp.httpSvrs[obj]; ok {
+	for _, obj := range a.objRunList {
+		// the http server which shares the port with the tars server is served by the tars server
+		if s, ok := a.httpSvrs[obj]; ok && a.goSvrs[obj] == nil {
 			lisDone.Add(1)
 			go func(obj string) {
 				addr := s.Addr
-				TLOG.Infof("%s http server start on %s", obj, s.Addr)
+				a.tlog.Infof("%s http server start on %s", obj, s.Addr)
 				if addr == "" {
 					lisDone.Done()
 					a.teerDown(fmt.Errorf("empty addr for %s", obj))
@@ -366,6 +521,7 @@ func (a *application) Run() {
 				}
 
 				lisDone.Done()
+				<-serving
 				if s.TLSConfig != nil {
 					err = s.ServeTLS(ln, "", "")
 				} else {
@@ -373,7 +529,7 @@ func (a *application) Run() {
 				}
 				if err != nil {
 					if err == http.ErrServerClosed {
-						TLOG.Infof("%s http server stop: %v", obj, err)
+						a.tlog.Infof("%s http server stop: %v", obj, err)
 					} else {
 						a.teerDown(fmt.Errorf("%s server stop: %v", obj, err))
 					}
@@ -382,12 +538,12 @@ func (a *application) Run() {
 			continue
 		}
 
-		s := defaultApp.goSvrs[obj]
+		s := a.goSvrs[obj]
 		if s == nil {
 			a.teerDown(fmt.Errorf("obj not found %s", obj))
 			break
 		}
-		TLOG.Debugf("Run %s  %+v", obj, s.GetConfig())
+		a.tlog.Debugf("Run %s  %+v", obj, s.GetConfig())
 		lisDone.Add(1)
 		go func(obj string) {
 			if err := s.Listen(); err != nil {
@@ -397,47 +553,176 @@ func (a *application) Run() {
 			}
 
 			lisDone.Done()
+			<-serving
 			if err := s.Serve(); err != nil {
 				a.teerDown(fmt.Errorf("server obj for %s failed: %v", obj, err))
 				return
 			}
 		}(obj)
 	}
-	go ReportNotifyInfo(NotifyNormal, "restart")
+	go a.ReportNotifyInfo(NotifyNormal, "restart")
 
 	lisDone.Wait()
-	if os.Getenv("GRACE_RESTART") == "1" {
-		ppid := os.Getppid()
-		TLOG.Infof("stop ppid %d", ppid)
-		if ppid > 1 {
-			grace.SignalUSR2(ppid)
+	if handover {
+		err = processHandover.ready(a.waitReady(), a.svrCfg.GraceRestartTimeout)
+		if err != nil {
+			// the parent process keeps serving
+			a.teerDown(fmt.Errorf("grace restart not ready: %v", err))
+			return
 		}
+		close(serving)
+	}
+	if err = a.runHooks(hookStarted, false); err != nil {
+		a.ReportNotifyInfo(NotifyError, err.Error())
 	}
 	a.mainLoop()
 }
 
-func (a *application) graceRestart() {
-	pid := os.Getpid()
-	TLOG.Debugf("grace restart server begin %d", pid)
-	_ = os.Setenv("GRACE_RESTART", "1")
-	envs := os.Environ()
-	newEnvs := make([]string, 0)
-	for _, env := range envs {
-		// skip fd inherited from parent process
-		if strings.HasPrefix(env, grace.InheritFdPrefix) {
-			continue
+// waitReady waits until the health checks pass within the grace restart timeout.
+func (a *Application) waitReady() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.svrCfg.GraceRestartTimeout)
+	defer cancel()
+	for {
+		status, details := a.checkHealth(ctx, "")
+		if status == healthf.ServingStatus_SERVING {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %v", servingStatusName(status), details)
+		case <-time.After(graceReadyInterval):
 		}
-		newEnvs = append(newEnvs, env)
 	}
+}
+
+// handover makes the process started by grace restart take over from the parent process
+// after all the applications run by the process are ready.
+type handover struct {
+	mu      sync.Mutex
+	pending int
+	err     error
+	done    chan struct{}
+	// notify notifies the parent process that the process is ready
+	notify func()
+}
+
+var processHandover = &handover{done: make(chan struct{}), notify: notifyParentReady}
+
+// notifyParentReady writes the ready pipe for the parent process and stops it.
+func notifyParentReady() {
+	if err := grace.NotifyReady(); err != nil {
+		TLOG.Errorf("notify ready failed: %v", err)
+	}
+	ppid := os.Getppid()
+	TLOG.Infof("stop ppid %d", ppid)
+	if ppid > 1 {
+		grace.SignalUSR2(ppid)
+	}
+}
+
+// add adds the application to be ready, which must be called when the application starts running.
+func (h *handover) add() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending++
+}
+
+// ready marks the application ready or failed by err, and waits for the other applications within timeout.
+// The parent process is notified to stop once all the applications are ready, and keeps serving if any fails.
+func (h *handover) ready(err error, timeout time.Duration) error {
+	h.mu.Lock()
+	select {
+	case <-h.done:
+		// the process has taken over or failed
+		h.mu.Unlock()
+		if err == nil {
+			err = h.err
+		}
+		return err
+	default:
+	}
+	if err != nil {
+		h.err = fmt.Errorf("another application is not ready: %v", err)
+		close(h.done)
+		h.mu.Unlock()
+		return err
+	}
+	if h.pending--; h.pending == 0 {
+		close(h.done)
+		h.mu.Unlock()
+		h.notify()
+		return nil
+	}
+	h.mu.Unlock()
+	select {
+	case <-h.done:
+		return h.err
+	case <-time.After(timeout):
+		return errors.New("other applications are not ready")
+	}
+}
+
+// graceRestarting is set while the new process is started by grace restart, which is shared by the applications of the process.
+var graceRestarting int32
+
+// graceRestartResult is the result of the last grace restart of the process, which is shown by tars.gracerestartstatus.
+var graceRestartResult struct {
+	sync.Mutex
+	msg string
+	at  time.Time
+}
+
+func setGraceRestartResult(msg string) {
+	graceRestartResult.Lock()
+	defer graceRestartResult.Unlock()
+	graceRestartResult.msg = msg
+	graceRestartResult.at = time.Now()
+}
+
+// graceRestartStatus returns the result of the last grace restart with the time.
+func graceRestartStatus() string {
+	graceRestartResult.Lock()
+	defer graceRestartResult.Unlock()
+	if graceRestartResult.msg == "" {
+		return "no grace restart"
+	}
+	return fmt.Sprintf("%s at %s", graceRestartResult.msg, graceRestartResult.at.Format("2006-01-02 15:04:05"))
+}
+
+// graceReadyInterval is the interval of checking the health of the new process started by grace restart.
+var graceReadyInterval = 100 * time.Millisecond
+
+// graceRestart starts the new process which inherits the listeners, and waits until it is ready to take over.
+// If the new process exits or is not ready within the grace restart timeout, it is killed and this process keeps serving.
+func (a *Application) graceRestart() (err error) {
+	if !atomic.CompareAndSwapInt32(&graceRestarting, 0, 1) {
+		a.tlog.Debug("grace restart is in progress")
+		return errors.New("grace restart is in progress")
+	}
+	setGraceRestartResult("grace restart in progress")
+	started := false
+	defer func() {
+		if !started {
+			atomic.StoreInt32(&graceRestarting, 0)
+		}
+		if err != nil {
+			a.tlog.Errorf("grace restart failed: %v", err)
+			setGraceRestartResult("grace restart failed: " + err.Error())
+			go a.ReportNotifyInfo(NotifyError, "grace restart failed: "+err.Error())
+		}
+	}()
+	pid := os.Getpid()
+	a.tlog.Debugf("grace restart server begin %d", pid)
+	newEnvs := graceRestartEnvs(os.Environ())
 
 	// redirect stdout/stderr to logger
 	svrCfg := a.ServerConfig()
 	var logfile *os.File
 	if svrCfg != nil {
-		GetLogger("")
+		a.GetLogger("")
 		logpath := filepath.Join(svrCfg.LogPath, svrCfg.App, svrCfg.Server, svrCfg.App+"."+svrCfg.Server+".log")
 		logfile, _ = os.OpenFile(logpath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
-		TLOG.Debugf("redirect to %s %v", logpath, logfile)
+		a.tlog.Debugf("redirect to %s %v", logpath, logfile)
 	}
 	if logfile == nil {
 		logfile = os.Stdout
@@ -446,33 +731,113 @@ func (a *application) graceRestart() {
 	for key, file := range grace.GetAllListenFiles() {
 		fd := fmt.Sprint(file.Fd())
 		newFd := len(files)
-		TLOG.Debugf("translate %s=%s to %s=%d", key, fd, key, newFd)
+		a.tlog.Debugf("translate %s=%s to %s=%d", key, fd, key, newFd)
 		newEnvs = append(newEnvs, fmt.Sprintf("%s=%d", key, newFd))
 		files = append(files, file)
 	}
 
+	// the subprocess writes the pipe once it is ready, and the pipe is closed without writing if it exits
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("create ready pipe failed: %v", err)
+	}
+	defer ready.Close()
+	newEnvs = append(newEnvs, fmt.Sprintf("%s=%d", grace.ReadyFdKey, len(files)))
+	files = append(files, readyW)
+
 	exePath, err := exec.LookPath(os.Args[0])
 	if err != nil {
-		TLOG.Errorf("LookPath failed %v", err)
-		return
+		readyW.Close()
+		return fmt.Errorf("look path failed: %v", err)
 	}
 
 	process, err := os.StartProcess(exePath, os.Args, &os.ProcAttr{
 		Env:   newEnvs,
 		Files: files,
 	})
+	readyW.Close()
 	if err != nil {
-		TLOG.Errorf("start subprocess failed %v", err)
-		return
+		return fmt.Errorf("start subprocess failed: %v", err)
+	}
+	a.tlog.Infof("subprocess start %d", process.Pid)
+	started = true
+	exited := make(chan error, 1)
+	go func() {
+		// the subprocess stops this process once it is ready, restart again if it exits before that
+		state, err := process.Wait()
+		atomic.StoreInt32(&graceRestarting, 0)
+		if err == nil {
+			err = fmt.Errorf("subprocess %d exited: %v", process.Pid, state)
+		}
+		exited <- err
+	}()
+
+	timeout := GraceRestartTimeout * time.Millisecond
+	if svrCfg != nil {
+		timeout = svrCfg.GraceRestartTimeout
+	}
+	if err = waitHandover(ready, exited, timeout); err != nil {
+		_ = process.Kill()
+		return err
+	}
+	a.tlog.Infof("subprocess %d is ready", process.Pid)
+	msg := fmt.Sprintf("grace restart succ, subprocess %d takes over", process.Pid)
+	setGraceRestartResult(msg)
+	a.ReportNotifyInfo(NotifyNormal, msg)
+	return nil
+}
+
+// graceRestartEnvs returns the environment of the subprocess started by grace restart, which is marked by GRACE_RESTART.
+func graceRestartEnvs(envs []string) []string {
+	newEnvs := make([]string, 0, len(envs)+1)
+	for _, env := range envs {
+		// skip fd inherited from parent process
+		if strings.HasPrefix(env, grace.InheritFdPrefix) || strings.HasPrefix(env, grace.ReadyFdKey+"=") ||
+			strings.HasPrefix(env, "GRACE_RESTART=") {
+			continue
+		}
+		newEnvs = append(newEnvs, env)
+	}
+	return append(newEnvs, "GRACE_RESTART=1")
+}
+
+// waitHandover waits until the subprocess writes the ready pipe, and fails if it exits or times out before that.
+func waitHandover(ready io.Reader, exited <-chan error, timeout time.Duration) error {
+	readDone := make(chan error, 1)
+	go func() {
+		_, err := ready.Read(make([]byte, 1))
+		readDone <- err
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-readDone:
+		if err == nil {
+			return nil
+		}
+		// the pipe is closed as the subprocess exits, prefer the exit status
+		select {
+		case err = <-exited:
+		case <-time.After(time.Second):
+			err = fmt.Errorf("subprocess is not ready: %v", err)
+		}
+		return err
+	case err := <-exited:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("subprocess is not ready within %v", timeout)
 	}
-	TLOG.Infof("subprocess start %d", process.Pid)
-	go process.Wait()
 }
 
-func (a *application) graceShutdown() {
+// Shutdown shuts the application down gracefully within the gracedown timeout, and then Run returns.
+func (a *Application) Shutdown() {
+	a.graceShutdown()
+}
+
+func (a *Application) graceShutdown() {
 	var wg sync.WaitGroup
 
-	atomic.StoreInt32(&defaultApp.isShutdowning, 1)
+	atomic.StoreInt32(&a.isShutdowning, 1)
 	pid := os.Getpid()
 
 	var graceShutdownTimeout time.Duration
@@ -483,41 +848,42 @@ func (a *application) graceShutdown() {
 		graceShutdownTimeout = a.svrCfg.GracedownTimeout
 	}
 
-	TLOG.Infof("grace shutdown start %d in %v", pid, graceShutdownTimeout)
+	a.tlog.Infof("grace shutdown start %d in %v", pid, graceShutdownTimeout)
+	_ = a.runHooks(hookShutdownBegin, false)
 	ctx, cancel := context.WithTimeout(context.Background(), graceShutdownTimeout)
 
-	for _, obj := range defaultApp.destroyableObjs {
+	for _, obj := range a.destroyableObjs {
 		wg.Add(1)
 		go func(wg *sync.WaitGroup, obj destroyableImp) {
 			defer wg.Done()
 			obj.Destroy()
-			TLOG.Infof("grace Destroy success %d", pid)
+			a.tlog.Infof("grace Destroy success %d", pid)
 		}(&wg, obj)
 	}
 
-	for _, obj := range defaultApp.objRunList {
-		if s, ok := defaultApp.httpSvrs[obj]; ok {
+	for _, obj := range a.objRunList {
+		if s, ok := a.httpSvrs[obj]; ok && a.goSvrs[obj] == nil {
 			wg.Add(1)
 			go func(s *http.Server, ctx context.Context, wg *sync.WaitGroup, objstr string) {
 				defer wg.Done()
 				err := s.Shutdown(ctx)
 				if err == nil {
-					TLOG.Infof("grace shutdown http %s success %d", objstr, pid)
+					a.tlog.Infof("grace shutdown http %s success %d", objstr, pid)
 				} else {
-					TLOG.Infof("grace shutdown http %s failed within %v : %v", objstr, graceShutdownTimeout, err)
+					a.tlog.Infof("grace shutdown http %s failed within %v : %v", objstr, graceShutdownTimeout, err)
 				}
 			}(s, ctx, &wg, obj)
 		}
 
-		if s, ok := defaultApp.goSvrs[obj]; ok {
+		if s, ok := a.goSvrs[obj]; ok {
 			wg.Add(1)
 			go func(s *transport.TarsServer, ctx context.Context, wg *sync.WaitGroup, objstr string) {
 				defer wg.Done()
 				err := s.Shutdown(ctx)
 				if err == nil {
-					TLOG.Infof("grace shutdown tars %s success %d", objstr, pid)
+					a.tlog.Infof("grace shutdown tars %s success %d", objstr, pid)
 				} else {
-					TLOG.Infof("grace shutdown tars %s failed within %v: %v", objstr, graceShutdownTimeout, err)
+					a.tlog.Infof("grace shutdown tars %s failed within %v: %v", objstr, graceShutdownTimeout, err)
 				}
 			}(s, ctx, &wg, obj)
 		}
@@ -530,31 +896,65 @@ func (a *application) graceShutdown() {
 
 	select {
 	case <-ctx.Done():
-		TLOG.Infof("grace shutdown all success within : %v", graceShutdownTimeout)
+		a.tlog.Infof("grace shutdown all success within : %v", graceShutdownTimeout)
 	case <-time.After(graceShutdownTimeout):
-		TLOG.Infof("grace shutdown timeout within : %v", graceShutdownTimeout)
+		a.tlog.Infof("grace shutdown timeout within : %v", graceShutdownTimeout)
 	}
+	a.shutdownDebugServers()
+	_ = a.runHooks(hookShutdownComplete, false)
 
 	a.teerDown(nil)
 }
 
-func (a *application) teerDown(err error) {
+// serveDebug serves handler on ln until the application shuts down, and returns nil then.
+func (a *Application) serveDebug(ln net.Listener, handler http.Handler) error {
+	s := &http.Server{Handler: handler}
+	a.debugSvrsMu.Lock()
+	a.debugSvrs = append(a.debugSvrs, s)
+	a.debugSvrsMu.Unlock()
+	if err := s.Serve(ln); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// shutdownDebugServers shuts the http servers served by serveDebug down.
+func (a *Application) shutdownDebugServers() {
+	a.debugSvrsMu.Lock()
+	svrs := a.debugSvrs
+	a.debugSvrs = nil
+	a.debugSvrsMu.Unlock()
+	for _, s := range svrs {
+		ctx, cancel := context.WithTimeout(context.Background(), debugShutdownTimeout)
+		if err := s.Shutdown(ctx); err != nil {
+			s.Close()
+		}
+		cancel()
+	}
+}
+
+// debugShutdownTimeout is the timeout of shutting each debug http server down.
+var debugShutdownTimeout = time.Second
+
+func (a *Application) teerDown(err error) {
 	a.shutdownOnce.Do(func() {
 		if err != nil {
-			ReportNotifyInfo(NotifyNormal, "server is fatal: "+err.Error())
+			a.ReportNotifyInfo(NotifyNormal, "server is fatal: "+err.Error())
 			fmt.Println(err)
-			TLOG.Error(err)
+			a.tlog.Error(err)
 		}
 		a.shutdown <- true
 	})
 }
 
-func (a *application) handleSignal() {
-	usrFun, killFunc := a.graceRestart, a.graceShutdown
+func (a *Application) handleSignal() {
+	// restart in background, so the signal of stopping is handled while waiting for the new process
+	usrFun := func() { go a.graceRestart() }
+	killFunc := a.graceShutdown
 	grace.GraceHandler(usrFun, killFunc)
 }
 
-func (a *application) mainLoop() {
+func (a *Application) mainLoop() {
 	ha := new(NodeFHelper)
 	comm := a.Communicator()
 	node := a.svrCfg.Node
@@ -568,14 +968,15 @@ func (a *application) mainLoop() {
 	go ha.KeepAlive("") //first start
 	go a.handleSignal()
 	loop := time.NewTicker(svrCfg.MainLoopTicker)
+	numDrop := make(map[string]int64)
 
 	for {
 		select {
-		case <-defaultApp.shutdown:
-			ReportNotifyInfo(NotifyNormal, "stop")
+		case <-a.shutdown:
+			a.ReportNotifyInfo(NotifyNormal, "stop")
 			return
 		case <-loop.C:
-			if atomic.LoadInt32(&defaultApp.isShutdowning) == 1 {
+			if atomic.LoadInt32(&a.isShutdowning) == 1 {
 				continue
 			}
 			for name, adapter := range a.svrCfg.Adapters {
@@ -584,12 +985,19 @@ func (a *application) mainLoop() {
 					ha.KeepAlive(name)
 					continue
 				}
-				if s, ok := defaultApp.goSvrs[adapter.Obj]; ok {
+				if s, ok := a.goSvrs[adapter.Obj]; ok {
 					if !s.IsZombie(svrCfg.ZombieTimeout) {
 						ha.KeepAlive(name)
 					}
 				}
 			}
+			for obj, s := range a.goSvrs {
+				// report the packages dropped since the last loop
+				if n := s.NumDrop(); n > numDrop[obj] {
+					a.ReportSum(obj+".dropped", int(n-numDrop[obj]))
+					numDrop[obj] = n
+				}
+			}
 		}
 	}
 }
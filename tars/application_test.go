@@ -0,0 +1,246 @@
+package tars
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/adminf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/healthf"
+	"github.com/TarsCloud/TarsGo/tars/util/grace"
+	"github.com/TarsCloud/TarsGo/tars/util/rogger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAppConf = `<tars>
+	<application>
+		<server>
+			app=%[1]s
+			server=%[2]s
+			logpath=%[3]s
+			<%[1]s.%[2]s.AdminObjAdapter>
+				endpoint=tcp -h 127.0.0.1 -p %[4]d -t 60000
+				protocol=tars
+				queuecap=100
+				queuetimeout=60000
+				servant=%[1]s.%[2]s.AdminObj
+				threads=1
+			</%[1]s.%[2]s.AdminObjAdapter>
+		</server>
+	</application>
+</tars>`
+
+func newTestApplication(t *testing.T, app, server string, port int, opts ...Option) *Application {
+	path := filepath.Join(t.TempDir(), server+".conf")
+	err := os.WriteFile(path, []byte(fmt.Sprintf(testAppConf, app, server, t.TempDir(), port)), 0644)
+	assert.NoError(t, err)
+	return NewApplication(append([]Option{WithConfigFile(path)}, opts...)...)
+}
+
+// TestNewApplication test running the isolated applications in a process.
+func TestNewApplication(t *testing.T) {
+	apps := []*Application{
+		newTestApplication(t, "TestApp", "FirstServer", 17981),
+		NewApplication(
+			WithServer("TestApp", "SecondServer"),
+			WithAdapter("TestApp.SecondServer.AdminObj", "tcp -h 127.0.0.1 -p 17982 -t 60000"),
+			WithLogPath(t.TempDir()),
+		),
+	}
+	assert.Equal(t, "FirstServer", apps[0].ServerConfig().Server)
+	assert.Equal(t, "SecondServer", apps[1].ServerConfig().Server)
+	assert.NotEqual(t, defaultApp.ServerConfig(), apps[0].ServerConfig())
+	assert.NotSame(t, apps[0].metrics, apps[1].metrics)
+	assert.NotSame(t, apps[0].Communicator(), apps[1].Communicator())
+	// the framework log and the log level of the default application are not changed
+	assert.NotSame(t, TLOG, apps[1].tlog)
+	assert.True(t, TLOG.IsConsoleWriter())
+	assert.Equal(t, "ERROR", rogger.GetLevel())
+
+	done := make(chan struct{}, len(apps))
+	for _, app := range apps {
+		obj := app.ServerConfig().App + "." + app.ServerConfig().Server + ".AdminObj"
+		app.AddServant(new(adminf.AdminF), &Admin{app: app}, obj)
+		go func(app *Application) {
+			app.Run()
+			done <- struct{}{}
+		}(app)
+	}
+	for i, app := range apps {
+		obj := fmt.Sprintf("%s.%s.AdminObj@tcp -h 127.0.0.1 -p %d -t 3000", app.ServerConfig().App, app.ServerConfig().Server, 17981+i)
+		proxy := new(healthf.HealthF)
+		app.Communicator().StringToProxy(obj, proxy)
+		var status healthf.ServingStatus
+		var err error
+		for retry := 0; retry < 50; retry++ {
+			var details map[string]string
+			if status, err = proxy.Tars_health("", &details); err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_SERVING), status)
+	}
+
+	// shutting down an application does not stop the other one
+	apps[0].Shutdown()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the application is not shut down")
+	}
+	assert.Equal(t, int32(0), apps[1].isShutdowning)
+	apps[1].Shutdown()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the application is not shut down")
+	}
+}
+
+// TestConfigOptions test configuring the application by the options, which override the config file.
+func TestConfigOptions(t *testing.T) {
+	app := newTestApplication(t, "TestApp", "OptionServer", 17983,
+		WithAdapter("TestApp.OptionServer.AdminObj", "tcp -h 127.0.0.1 -p 17984 -t 60000", WithQueueCap(10)),
+		WithAdapter("TestApp.OptionServer.HelloObj", "tcp -h 127.0.0.1 -p 17985 -t 60000"),
+		WithLocator("tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890"),
+		WithServerTimeout(time.Second, 0, 2*time.Second, 0),
+		WithInvokeTimeout(500*time.Millisecond),
+	)
+	svrCfg := app.ServerConfig()
+	assert.Equal(t, "OptionServer", svrCfg.Server)
+	assert.Equal(t, time.Second, svrCfg.ReadTimeout)
+	assert.Equal(t, 2*time.Second, svrCfg.HandleTimeout)
+	assert.Equal(t, "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890", app.ClientConfig().Locator)
+	assert.Equal(t, int32(500), app.ClientConfig().ReqDefaultTimeout)
+	assert.Equal(t, []string{"TestApp.OptionServer.AdminObjAdapter", "TestApp.OptionServer.HelloObjAdapter"}, app.serList)
+
+	cfg := app.tarsConfig["TestApp.OptionServer.AdminObj"]
+	assert.Equal(t, "127.0.0.1:17984", cfg.Address)
+	assert.Equal(t, 10, cfg.QueueCap)
+	assert.Equal(t, time.Second, cfg.ReadTimeout)
+	cfg = app.tarsConfig["TestApp.OptionServer.HelloObj"]
+	assert.Equal(t, "127.0.0.1:17985", cfg.Address)
+	assert.Equal(t, svrCfg.QueueCap, cfg.QueueCap)
+}
+
+// TestWithAdapterReplace test replacing the adapter of obj in the config file which is named differently.
+func TestWithAdapterReplace(t *testing.T) {
+	content := strings.ReplaceAll(fmt.Sprintf(testAppConf, "TestApp", "RenameServer", t.TempDir(), 17983), "AdminObjAdapter", "Admin")
+	path := filepath.Join(t.TempDir(), "RenameServer.conf")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	app := NewApplication(WithConfigFile(path), WithAdapter("TestApp.RenameServer.AdminObj", "tcp -h 127.0.0.1 -p 17984 -t 60000"))
+	app.init()
+	assert.Equal(t, []string{"TestApp.RenameServer.Admin"}, app.serList)
+	assert.Len(t, app.svrCfg.Adapters, 1)
+	assert.Equal(t, "127.0.0.1:17984", app.tarsConfig["TestApp.RenameServer.AdminObj"].Address)
+}
+
+// TestWaitHandover test waiting for the subprocess of grace restart to be ready.
+func TestWaitHandover(t *testing.T) {
+	// ready
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	w.Write([]byte{1})
+	assert.NoError(t, waitHandover(r, make(chan error), time.Second))
+	r.Close()
+	w.Close()
+
+	// exits before ready
+	r, w, err = os.Pipe()
+	assert.NoError(t, err)
+	exited := make(chan error, 1)
+	exited <- errors.New("exit status 1")
+	w.Close()
+	assert.EqualError(t, waitHandover(r, exited, time.Second), "exit status 1")
+	r.Close()
+
+	// not ready in time
+	r, w, err = os.Pipe()
+	assert.NoError(t, err)
+	start := time.Now()
+	assert.EqualError(t, waitHandover(r, make(chan error), 100*time.Millisecond), "subprocess is not ready within 100ms")
+	assert.Less(t, time.Since(start), time.Second)
+	r.Close()
+	w.Close()
+}
+
+// TestGraceRestartEnvs test only the subprocess of grace restart is marked by GRACE_RESTART.
+func TestGraceRestartEnvs(t *testing.T) {
+	envs := []string{"PATH=/bin", grace.InheritFdPrefix + "tcp_127.0.0.1:80=3", grace.ReadyFdKey + "=5", "GRACE_RESTART=1"}
+	assert.Equal(t, []string{"PATH=/bin", "GRACE_RESTART=1"}, graceRestartEnvs(envs))
+	assert.Equal(t, []string{"PATH=/bin", "GRACE_RESTART=1"}, graceRestartEnvs(envs[:1]))
+}
+
+// TestGraceRestartStatus test showing the result of the last grace restart.
+func TestGraceRestartStatus(t *testing.T) {
+	assert.Equal(t, "no grace restart", graceRestartStatus())
+	setGraceRestartResult("grace restart failed: subprocess is not ready within 1s")
+	defer setGraceRestartResult("")
+	status := graceRestartStatus()
+	assert.True(t, strings.HasPrefix(status, "grace restart failed: subprocess is not ready within 1s at "), status)
+}
+
+// TestHandover test the grace restarted process takes over after all the applications are ready.
+func TestHandover(t *testing.T) {
+	notified := 0
+	h := &handover{done: make(chan struct{}), notify: func() { notified++ }}
+	h.add()
+	h.add()
+	results := make(chan error, 1)
+	go func() {
+		results <- h.ready(nil, time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, results, 0)
+	assert.Equal(t, 0, notified)
+	assert.NoError(t, h.ready(nil, time.Second))
+	assert.NoError(t, <-results)
+	assert.Equal(t, 1, notified)
+
+	// any application fails
+	notified = 0
+	h = &handover{done: make(chan struct{}), notify: func() { notified++ }}
+	h.add()
+	h.add()
+	h.add()
+	go func() {
+		results <- h.ready(nil, time.Second)
+	}()
+	assert.EqualError(t, h.ready(errors.New("not serving"), time.Second), "not serving")
+	assert.EqualError(t, <-results, "another application is not ready: not serving")
+	assert.EqualError(t, h.ready(nil, time.Second), "another application is not ready: not serving")
+	assert.Equal(t, 0, notified)
+
+	// the other application is not ready in time
+	h = &handover{done: make(chan struct{}), notify: func() { notified++ }}
+	h.add()
+	h.add()
+	assert.EqualError(t, h.ready(nil, 100*time.Millisecond), "other applications are not ready")
+}
+
+// TestWaitReady test waiting for the health checks of the grace restarted process to pass.
+func TestWaitReady(t *testing.T) {
+	app := NewApplication(WithServer("TestApp", "ReadyServer"), WithLogPath(t.TempDir()), WithGraceRestartTimeout(time.Second))
+	app.init()
+	checked := 0
+	app.RegisterHealthCheck("config", time.Second, func(ctx context.Context) error {
+		if checked++; checked < 3 {
+			return errors.New("config not loaded")
+		}
+		return nil
+	})
+	assert.NoError(t, app.waitReady())
+	assert.Equal(t, 3, checked)
+
+	app.SetServingStatus("", healthf.ServingStatus_NOT_SERVING)
+	start := time.Now()
+	assert.EqualError(t, app.waitReady(), "NOT_SERVING map[config:ok status:NOT_SERVING]")
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
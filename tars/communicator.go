@@ -18,7 +18,7 @@ type ProxyPrx interface {
 // Communicator struct
 type Communicator struct {
 	Client     *clientConfig
-	app        *application
+	app        *Application
 	properties sync.Map
 }
 
@@ -34,14 +34,14 @@ func NewCommunicator() *Communicator {
 }
 
 // Communicator returns a default communicator
-func (a *application) Communicator() *Communicator {
+func (a *Application) Communicator() *Communicator {
 	a.onceCommunicator.Do(func() {
 		a.communicator = a.NewCommunicator()
 	})
 	return a.communicator
 }
 
-func (a *application) NewCommunicator() *Communicator {
+func (a *Application) NewCommunicator() *Communicator {
 	c := &Communicator{app: a, Client: a.ClientConfig()}
 	c.init()
 	return c
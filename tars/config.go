@@ -56,7 +56,19 @@ type serverConfig struct {
 	MainLoopTicker          time.Duration
 	StatReportChannelBufLen int32
 	MaxPackageLength        int
+	ConfigPollInterval      time.Duration
 	GracedownTimeout        time.Duration
+	HookTimeout             time.Duration
+	GraceRestartTimeout     time.Duration
+	// serve the interface descriptions by the reflection servant
+	Reflection bool
+	// serve /healthz and /readyz on the address
+	HealthAddress string
+	// the admin http debug console
+	AdminHttpAddress  string
+	AdminHttpToken    string
+	AdminHttpUser     string
+	AdminHttpPassword string
 
 	// tls
 	CA           string
@@ -89,6 +101,9 @@ type clientConfig struct {
 	ClientDialTimeout  time.Duration
 	ReqDefaultTimeout  int32
 	ObjQueueMax        int32
+	// metrics exporter
+	MetricsAddress string
+	MetricsPath    string
 }
 
 // GetServerConfig Get server config
@@ -102,13 +117,13 @@ func GetClientConfig() *clientConfig {
 }
 
 // ServerConfig returns server config
-func (a *application) ServerConfig() *serverConfig {
+func (a *Application) ServerConfig() *serverConfig {
 	a.init()
 	return a.svrCfg
 }
 
 // ClientConfig returns client config
-func (a *application) ClientConfig() *clientConfig {
+func (a *Application) ClientConfig() *clientConfig {
 	a.init()
 	return a.cltCfg
 }
@@ -138,8 +153,11 @@ func newServerConfig() *serverConfig {
 		StatReportInterval:      tools.ParseTimeOut(StatReportInterval),
 		MainLoopTicker:          tools.ParseTimeOut(MainLoopTicker),
 		StatReportChannelBufLen: StatReportChannelBufLen,
+		ConfigPollInterval:      tools.ParseTimeOut(ConfigPollInterval),
 		MaxPackageLength:        MaxPackageLength,
 		GracedownTimeout:        tools.ParseTimeOut(GracedownTimeout),
+		HookTimeout:             tools.ParseTimeOut(HookTimeout),
+		GraceRestartTimeout:     tools.ParseTimeOut(GraceRestartTimeout),
 	}
 }
 
@@ -160,6 +178,7 @@ func newClientConfig() *clientConfig {
 		ClientDialTimeout:       tools.ParseTimeOut(ClientDialTimeout),
 		ReqDefaultTimeout:       ReqDefaultTimeout,
 		ObjQueueMax:             ObjQueueMax,
+		MetricsPath:             MetricsPath,
 	}
 	return conf
 }
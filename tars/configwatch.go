@@ -0,0 +1,241 @@
+package tars
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/configf"
+	"github.com/TarsCloud/TarsGo/tars/util/conf"
+	"gopkg.in/yaml.v3"
+)
+
+// configKey is the remote config file of the app or the server.
+type configKey struct {
+	appLevel bool
+	filename string
+}
+
+// loadName is the name of the initial load of the config file in the health details.
+func (k configKey) loadName() string {
+	if k.appLevel {
+		return "appconfig " + k.filename
+	}
+	return "config " + k.filename
+}
+
+type configWatch struct {
+	content string
+	loaded  bool
+	fns     []func(content string)
+}
+
+// configWatcher calls the watchers when the content of the remote config files changes,
+// which is downloaded by tars.loadconfig, AddConfig or polling.
+type configWatcher struct {
+	mu       sync.Mutex
+	watches  map[configKey]*configWatch
+	keys     []configKey
+	pollOnce sync.Once
+}
+
+// ConfigValue holds the value parsed from the remote config file, which is swapped atomically when the file changes.
+// It is returned by the watchers even if the first download or parse fails, and Load returns nil until the file is valid.
+type ConfigValue struct {
+	value atomic.Value
+}
+
+// Load returns the value parsed from the latest content.
+func (v *ConfigValue) Load() interface{} {
+	return v.value.Load()
+}
+
+// WatchConfig downloads the server level config file and calls fn with the content,
+// and then calls fn again when the content changes.
+func WatchConfig(filename string, fn func(content string)) error {
+	return defaultApp.WatchConfig(filename, fn)
+}
+
+// WatchAppConfig downloads the app level config file and calls fn with the content,
+// and then calls fn again when the content changes.
+func WatchAppConfig(filename string, fn func(content string)) error {
+	return defaultApp.WatchAppConfig(filename, fn)
+}
+
+// WatchConfigConf watches the server level config file of the tars config format, which is loaded as *conf.Conf.
+func WatchConfigConf(filename string) (*ConfigValue, error) {
+	return defaultApp.WatchConfigConf(filename)
+}
+
+// WatchConfigJSON watches the server level config file of json, which is loaded as the new value of the type of v,
+// for example WatchConfigJSON("app.json", &AppConfig{}) loads *AppConfig.
+func WatchConfigJSON(filename string, v interface{}) (*ConfigValue, error) {
+	return defaultApp.WatchConfigJSON(filename, v)
+}
+
+// WatchConfigYAML watches the server level config file of yaml, which is loaded as the new value of the type of v.
+func WatchConfigYAML(filename string, v interface{}) (*ConfigValue, error) {
+	return defaultApp.WatchConfigYAML(filename, v)
+}
+
+// WatchConfig downloads the server level config file and calls fn with the content,
+// and then calls fn again when the content changes, which is found by tars.loadconfig,
+// AddConfig and polling the config file every ConfigPollInterval.
+// The watcher is kept for polling even if the first download fails.
+func (a *Application) WatchConfig(filename string, fn func(content string)) error {
+	return a.watchConfig(configKey{filename: filename}, fn)
+}
+
+// WatchAppConfig is like WatchConfig for the app level config file.
+func (a *Application) WatchAppConfig(filename string, fn func(content string)) error {
+	return a.watchConfig(configKey{appLevel: true, filename: filename}, fn)
+}
+
+// WatchConfigConf watches the server level config file of the tars config format, which is loaded as *conf.Conf.
+func (a *Application) WatchConfigConf(filename string) (*ConfigValue, error) {
+	return a.watchConfigValue(filename, func(content string) (interface{}, error) {
+		c := conf.New()
+		err := c.InitFromString(content)
+		return c, err
+	})
+}
+
+// WatchConfigJSON watches the server level config file of json, which is loaded as the new value of the type of v.
+func (a *Application) WatchConfigJSON(filename string, v interface{}) (*ConfigValue, error) {
+	return a.watchConfigValue(filename, func(content string) (interface{}, error) {
+		ptr := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		err := json.Unmarshal([]byte(content), ptr)
+		return ptr, err
+	})
+}
+
+// WatchConfigYAML watches the server level config file of yaml, which is loaded as the new value of the type of v.
+func (a *Application) WatchConfigYAML(filename string, v interface{}) (*ConfigValue, error) {
+	return a.watchConfigValue(filename, func(content string) (interface{}, error) {
+		ptr := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		err := yaml.Unmarshal([]byte(content), ptr)
+		return ptr, err
+	})
+}
+
+// watchConfigValue keeps the last valid value if the changed content is invalid.
+func (a *Application) watchConfigValue(filename string, parse func(content string) (interface{}, error)) (*ConfigValue, error) {
+	cv := &ConfigValue{}
+	err := a.WatchConfig(filename, func(content string) {
+		v, err := parse(content)
+		if err != nil {
+			TLOG.Errorf("parse config %s error: %v", filename, err)
+			return
+		}
+		cv.value.Store(v)
+	})
+	if err != nil {
+		return cv, err
+	}
+	if cv.Load() == nil {
+		return cv, fmt.Errorf("parse config %s error", filename)
+	}
+	return cv, nil
+}
+
+func (a *Application) watchConfig(key configKey, fn func(content string)) error {
+	w := &a.configWatcher
+	w.mu.Lock()
+	if w.watches == nil {
+		w.watches = make(map[configKey]*configWatch)
+	}
+	watch, ok := w.watches[key]
+	if !ok {
+		watch = &configWatch{}
+		w.watches[key] = watch
+		w.keys = append(w.keys, key)
+	}
+	watch.fns = append(watch.fns, fn)
+	loaded, content := watch.loaded, watch.content
+	w.mu.Unlock()
+
+	w.pollOnce.Do(func() {
+		go a.pollConfig()
+	})
+	if loaded {
+		callConfigWatcher(key, fn, content)
+		return nil
+	}
+	// the watchers are called by configLoaded
+	_, err := a.loadConfig(key)
+	return err
+}
+
+// loadConfig downloads the config file, the server is not serving until it is loaded once.
+func (a *Application) loadConfig(key configKey) (config string, err error) {
+	if key.appLevel {
+		config, err = a.GetRemoteConf().GetAppConfig(key.filename)
+	} else {
+		config, err = a.GetRemoteConf().GetConfig(key.filename)
+	}
+	// the success is recorded by configLoaded, which is also called by the retries in the background
+	if err != nil {
+		a.health.initialLoad(key.loadName(), err)
+	}
+	return config, err
+}
+
+// pollConfig downloads the watched config files periodically until the application shuts down.
+func (a *Application) pollConfig() {
+	interval := a.ServerConfig().ConfigPollInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if atomic.LoadInt32(&a.isShutdowning) == 1 {
+			return
+		}
+		a.configWatcher.mu.Lock()
+		keys := append([]configKey(nil), a.configWatcher.keys...)
+		a.configWatcher.mu.Unlock()
+		for _, key := range keys {
+			if _, err := a.loadConfig(key); err != nil {
+				TLOG.Errorf("poll config %s error: %v", key.filename, err)
+			}
+		}
+	}
+}
+
+// configLoaded calls the watchers of the config file of the application if the content changes.
+func (a *Application) configLoaded(info configf.ConfigInfo, content string) {
+	cfg := a.ServerConfig()
+	if info.Appname != cfg.App || (info.Servername != "" && info.Servername != cfg.Server) {
+		return
+	}
+	key := configKey{appLevel: info.Servername == "", filename: info.Filename}
+	a.health.initialLoad(key.loadName(), nil)
+	w := &a.configWatcher
+	w.mu.Lock()
+	watch, ok := w.watches[key]
+	if !ok || (watch.loaded && watch.content == content) {
+		w.mu.Unlock()
+		return
+	}
+	watch.loaded, watch.content = true, content
+	fns := append([]func(string){}, watch.fns...)
+	w.mu.Unlock()
+
+	TLOG.Infof("config %s changed", info.Filename)
+	for _, fn := range fns {
+		callConfigWatcher(key, fn, content)
+	}
+}
+
+func callConfigWatcher(key configKey, fn func(content string), content string) {
+	defer func() {
+		if err := recover(); err != nil {
+			TLOG.Errorf("config watcher of %s panic: %v", key.filename, err)
+		}
+	}()
+	fn(content)
+}
@@ -0,0 +1,141 @@
+package tars
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/configf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/healthf"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConfig struct {
+	configf.ConfigServant
+	mu    sync.Mutex
+	files map[string]string
+	// fails the invoking like an outage
+	err error
+}
+
+func (c *fakeConfig) LoadConfigByInfo(info *configf.ConfigInfo, config *string) (int32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	content, ok := c.files[info.Appname+"."+info.Servername+"/"+info.Filename]
+	if !ok {
+		return -1, nil
+	}
+	*config = content
+	return 0, nil
+}
+
+func (c *fakeConfig) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *fakeConfig) remove(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.files, file)
+}
+
+func (c *fakeConfig) set(file, content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[file] = content
+}
+
+// TestWatchConfig test calling the watchers when the remote config files change.
+func TestWatchConfig(t *testing.T) {
+	cfgSvr := &fakeConfig{files: map[string]string{
+		"TestApp.WatchServer/test.conf": "a=1",
+		"TestApp.WatchServer/app.json":  `{"n": 1}`,
+	}}
+	server := NewApplication(
+		WithServer("tars", "tarsconfig"),
+		WithAdapter("tars.tarsconfig.ConfigObj", "tcp -h 127.0.0.1 -p 17987 -t 60000"),
+		WithLogPath(t.TempDir()),
+	)
+	server.AddServant(new(configf.Config), cfgSvr, "tars.tarsconfig.ConfigObj")
+	go server.Run()
+	defer server.Shutdown()
+
+	basePath := t.TempDir()
+	app := NewApplication(WithServer("TestApp", "WatchServer"), configOption(func(a *Application) {
+		a.svrCfg.Config = "tars.tarsconfig.ConfigObj@tcp -h 127.0.0.1 -p 17987 -t 60000"
+		a.svrCfg.BasePath = basePath
+		a.svrCfg.ConfigPollInterval = 100 * time.Millisecond
+	}))
+	var err error
+	for retry := 0; retry < 50; retry++ {
+		if _, err = app.AddConfig("test.conf"); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+	changes := make(chan string, 10)
+	err = app.WatchConfig("test.conf", func(content string) { changes <- content })
+	assert.NoError(t, err)
+	assert.Equal(t, "a=1", <-changes)
+
+	type appConfig struct {
+		N int `json:"n"`
+	}
+	value, err := app.WatchConfigJSON("app.json", &appConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value.Load().(*appConfig).N)
+
+	// polling
+	cfgSvr.set("TestApp.WatchServer/test.conf", "a=2")
+	cfgSvr.set("TestApp.WatchServer/app.json", `{"n": 2}`)
+	select {
+	case content := <-changes:
+		assert.Equal(t, "a=2", content)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the watcher is not called")
+	}
+	assert.Eventually(t, func() bool { return value.Load().(*appConfig).N == 2 }, 3*time.Second, 50*time.Millisecond)
+
+	// the invalid content is ignored
+	cfgSvr.set("TestApp.WatchServer/app.json", `{"n":`)
+	_, err = app.AddConfig("app.json")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, value.Load().(*appConfig).N)
+
+	// the value is loaded once the file is valid
+	cfgSvr.set("TestApp.WatchServer/late.json", `{"n":`)
+	late, err := app.WatchConfigJSON("late.json", &appConfig{})
+	assert.Error(t, err)
+	assert.Nil(t, late.Load())
+	cfgSvr.set("TestApp.WatchServer/late.json", `{"n": 4}`)
+	assert.Eventually(t, func() bool {
+		v, ok := late.Load().(*appConfig)
+		return ok && v.N == 4
+	}, 3*time.Second, 50*time.Millisecond)
+
+	// not serving until the config file is loaded
+	_, err = app.AddConfig("new.conf")
+	assert.Error(t, err)
+	status, details := app.checkHealth(context.Background(), "")
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_NOT_SERVING), status)
+	assert.Contains(t, details, "config new.conf")
+	cfgSvr.set("TestApp.WatchServer/new.conf", "b=1")
+	_, err = app.AddConfig("new.conf")
+	assert.NoError(t, err)
+	status, _ = app.checkHealth(context.Background(), "")
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_SERVING), status)
+
+	// pushed by tars.loadconfig
+	cfgSvr.set("TestApp.WatchServer/test.conf", "a=3")
+	ret, err := (&Admin{app: app}).Notify("tars.loadconfig test.conf")
+	assert.NoError(t, err)
+	assert.Equal(t, "Getconfig Success!: test.conf", ret)
+	assert.Equal(t, "a=3", <-changes)
+}
@@ -0,0 +1,157 @@
+package tars
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/adminf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/util/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+type slowAdmin struct {
+	*Admin
+}
+
+func (a *slowAdmin) Notify(command string) (string, error) {
+	time.Sleep(500 * time.Millisecond)
+	return "done " + command, nil
+}
+
+// TestDrainStatus test finishing the invoking requests during the graceful shutdown.
+func TestDrainStatus(t *testing.T) {
+	server := NewApplication(
+		WithServer("TestApp", "DrainServer"),
+		WithAdapter("TestApp.DrainServer.SlowObj", "tcp -h 127.0.0.1 -p 17991 -t 60000"),
+		WithLogPath(t.TempDir()),
+	)
+	server.AddServant(new(adminf.AdminF), &slowAdmin{Admin: &Admin{app: server}}, "TestApp.DrainServer.SlowObj")
+	done := make(chan struct{})
+	go func() {
+		server.Run()
+		close(done)
+	}()
+
+	client := NewApplication(WithServer("TestApp", "DrainClient"), WithLogPath(t.TempDir()))
+	proxy := new(adminf.AdminF)
+	client.Communicator().StringToProxy("TestApp.DrainServer.SlowObj@tcp -h 127.0.0.1 -p 17991 -t 60000", proxy)
+	var err error
+	for retry := 0; retry < 50; retry++ {
+		if _, err = proxy.Notify("ping"); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+
+	type result struct {
+		ret string
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		ret, err := proxy.Notify("slow")
+		results <- result{ret, err}
+	}()
+	assert.Eventually(t, func() bool {
+		return server.goSvrs["TestApp.DrainServer.SlowObj"].DrainStat().NumInvoke == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	go server.Shutdown()
+	admin := &Admin{app: server}
+	assert.Eventually(t, func() bool {
+		status, _ := admin.Notify("tars.drainstatus")
+		return strings.Contains(status, "[TestApp.DrainServer.SlowObj] draining=true connections=1 invoke=1")
+	}, 3*time.Second, 10*time.Millisecond)
+
+	r := <-results
+	assert.NoError(t, r.err)
+	assert.Equal(t, "done slow", r.ret)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("shutdown is not done")
+	}
+}
+
+// rejectFirst serves the requests on the first connection of ln, which rejects the first request by the shutdown,
+// and answers each request after delay.
+func rejectFirst(ln net.Listener, delay time.Duration, requests chan<- int32) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	s := &Protocol{}
+	for n := 0; ; n++ {
+		head := make([]byte, 4)
+		if _, err := io.ReadFull(conn, head); err != nil {
+			return
+		}
+		pkg := make([]byte, binary.BigEndian.Uint32(head))
+		copy(pkg, head)
+		if _, err := io.ReadFull(conn, pkg[4:]); err != nil {
+			return
+		}
+		req := requestf.RequestPacket{}
+		req.ReadFrom(codec.NewReader(pkg[4:]))
+		requests <- req.IRequestId
+		time.Sleep(delay)
+		if n == 0 {
+			rsp, _ := s.RejectShutdown(pkg)
+			conn.Write(rsp)
+			continue
+		}
+		buf := codec.NewBuffer()
+		buf.WriteString("ok", 0)
+		conn.Write(s.rsp2Byte(&requestf.ResponsePacket{
+			IVersion:   req.IVersion,
+			IRequestId: req.IRequestId,
+			SBuffer:    tools.ByteToInt8(buf.ToBytes()),
+		}))
+	}
+}
+
+// TestShutdownRetry test retrying the request rejected by the server which is shutting down.
+func TestShutdownRetry(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	requests := make(chan int32, 10)
+	go rejectFirst(ln, 0, requests)
+
+	client := NewApplication(WithServer("TestApp", "RetryClient"), WithLogPath(t.TempDir()))
+	proxy := new(adminf.AdminF)
+	port := ln.Addr().(*net.TCPAddr).Port
+	client.Communicator().StringToProxy("TestApp.RetryServer.AdminObj@tcp -h 127.0.0.1 -p "+strconv.Itoa(port)+" -t 60000", proxy)
+	ret, err := proxy.Notify("retry")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", ret)
+	assert.Len(t, requests, 2)
+}
+
+// TestShutdownRetryTimeout test the retried request is timed out by the remaining timeout.
+func TestShutdownRetryTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	requests := make(chan int32, 10)
+	go rejectFirst(ln, 200*time.Millisecond, requests)
+
+	client := NewApplication(WithServer("TestApp", "RetryClient"), WithLogPath(t.TempDir()))
+	proxy := new(adminf.AdminF)
+	port := ln.Addr().(*net.TCPAddr).Port
+	client.Communicator().StringToProxy("TestApp.RetryServer.AdminObj@tcp -h 127.0.0.1 -p "+strconv.Itoa(port)+" -t 60000", proxy)
+	proxy.TarsSetTimeout(300)
+	start := time.Now()
+	_, err = proxy.Notify("retry")
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(400*time.Millisecond))
+	assert.Len(t, requests, 2)
+}
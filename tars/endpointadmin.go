@@ -0,0 +1,164 @@
+package tars
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/util/endpoint"
+)
+
+// managers returns the endpoint managers of obj sorted by the keys, or all of them if obj is empty.
+func (g *globalManager) managers(obj string) (keys []string, eps []*endpointManager) {
+	g.mlock.Lock()
+	defer g.mlock.Unlock()
+	for key, e := range g.eps {
+		if obj != "" && e.objName != obj {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		eps = append(eps, g.eps[key])
+	}
+	return keys, eps
+}
+
+// globalManager returns the global endpoint manager, or nil if no client is created.
+func (a *Application) globalManager() *globalManager {
+	a.gManagerMu.Lock()
+	defer a.gManagerMu.Unlock()
+	return a.gManager
+}
+
+// dumpEndpoints returns the endpoints and the adapter proxies of the endpoint managers of obj.
+func (a *Application) dumpEndpoints(obj string) string {
+	gManager := a.globalManager()
+	if gManager == nil {
+		return "no endpoint manager"
+	}
+	keys, eps := gManager.managers(obj)
+	if len(eps) == 0 {
+		return fmt.Sprintf("no endpoint manager of [%s]", obj)
+	}
+	var b strings.Builder
+	for i, e := range eps {
+		e.dump(&b, keys[i])
+	}
+	return b.String()
+}
+
+func (e *endpointManager) dump(b *strings.Builder, key string) {
+	e.epLock.Lock()
+	active := append([]endpoint.Endpoint(nil), e.activeEp...)
+	activeEpf := e.activeEpf
+	inactiveEpf := e.inactiveEpf
+	e.epLock.Unlock()
+
+	fmt.Fprintf(b, "[%s] obj=%s set=%s direct=%v invoking=%d", key, e.objName, e.setDivision, e.directProxy, atomic.LoadInt32(&e.invokeNum))
+	if t := atomic.LoadInt64(&e.lastInvoke); t > 0 {
+		fmt.Fprintf(b, " last-invoke=%s", time.Unix(t, 0).Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+	if !e.directProxy {
+		fmt.Fprintf(b, "registry active=%d inactive=%d\n", len(activeEpf), len(inactiveEpf))
+		for _, epf := range inactiveEpf {
+			fmt.Fprintf(b, "inactive %s\n", endpoint.Tars2endpoint(epf))
+		}
+	}
+	fmt.Fprintf(b, "selectable=%d\n", len(active))
+	for _, ep := range active {
+		fmt.Fprintf(b, "active %s\n", ep)
+	}
+
+	var adpKeys []string
+	e.epList.Range(func(k, v interface{}) bool {
+		adpKeys = append(adpKeys, k.(string))
+		return true
+	})
+	sort.Strings(adpKeys)
+	for _, k := range adpKeys {
+		v, ok := e.epList.Load(k)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(b, "adapter %s\n", v.(*AdapterProxy).dump())
+	}
+}
+
+// dump returns the status and the counters of the adapter proxy.
+func (c *AdapterProxy) dump() string {
+	status := "ok"
+	if atomic.LoadInt32(&c.status) == 0 {
+		status = "blocked"
+	}
+	if atomic.LoadInt32(&c.closed) == 1 {
+		status = "closed"
+	}
+	s := fmt.Sprintf("%s status=%s send=%d success=%d fail=%d continuous-fail=%d queue=%d",
+		endpoint.Tars2endpoint(*c.point), status,
+		atomic.LoadInt32(&c.sendCount), atomic.LoadInt32(&c.successCount), atomic.LoadInt32(&c.failCount),
+		atomic.LoadInt32(&c.lastFailCount), c.tarsClient.QueueLen())
+	if t := atomic.LoadInt64(&c.lastSuccessTime); t > 0 {
+		s += " last-success=" + time.Unix(t, 0).Format(time.RFC3339)
+	}
+	return s
+}
+
+// refreshEndpoints refreshes the endpoints of obj from the registry, and returns the number of the managers refreshed.
+func (a *Application) refreshEndpoints(obj string) (int, error) {
+	gManager := a.globalManager()
+	if gManager == nil {
+		return 0, nil
+	}
+	_, eps := gManager.managers(obj)
+	var n int
+	for _, e := range eps {
+		if e.directProxy {
+			continue
+		}
+		if err := e.doFresh(); err != nil {
+			return n, fmt.Errorf("refresh %s: %v", e.objName, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// resetEndpoints resets the counters of the adapter proxies of obj, and makes all the registered endpoints selectable,
+// returns the number of the managers reset.
+func (a *Application) resetEndpoints(obj string) int {
+	gManager := a.globalManager()
+	if gManager == nil {
+		return 0
+	}
+	_, eps := gManager.managers(obj)
+	for _, e := range eps {
+		e.reset()
+	}
+	return len(eps)
+}
+
+func (e *endpointManager) reset() {
+	e.epList.Range(func(k, v interface{}) bool {
+		adp := v.(*AdapterProxy)
+		adp.reset()
+		e.checkAdapterList.Delete(k)
+		return true
+	})
+	if e.directProxy {
+		return
+	}
+	e.freshLock.Lock()
+	defer e.freshLock.Unlock()
+	e.epLock.Lock()
+	defer e.epLock.Unlock()
+	eps := make([]endpoint.Endpoint, len(e.activeEpf))
+	for i, epf := range e.activeEpf {
+		eps[i] = endpoint.Tars2endpoint(epf)
+	}
+	e.firstUpdateActiveEp(eps)
+}
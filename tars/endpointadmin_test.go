@@ -0,0 +1,49 @@
+package tars
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/endpointf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpEndpoints(t *testing.T) {
+	comm := NewCommunicator()
+	em := GetManager(comm, "App.Server.DumpObj@tcp -h 127.0.0.1 -p 10015 -t 3000")
+	adp, _ := em.SelectAdapterProxy(&Message{})
+	assert.NotNil(t, adp)
+	adp.sendAdd()
+	adp.failAdd()
+
+	out := comm.app.dumpEndpoints("App.Server.DumpObj")
+	assert.Contains(t, out, "obj=App.Server.DumpObj set= direct=true invoking=0\n")
+	assert.Contains(t, out, "active tcp -h 127.0.0.1 -p 10015 -t 3000\n")
+	assert.Contains(t, out, "adapter tcp -h 127.0.0.1 -p 10015 -t 3000 status=ok send=1 success=0 fail=1 continuous-fail=1 queue=0\n")
+	assert.Equal(t, "no endpoint manager of [App.Server.NoObj]", comm.app.dumpEndpoints("App.Server.NoObj"))
+
+	assert.Equal(t, 1, comm.app.resetEndpoints("App.Server.DumpObj"))
+	assert.True(t, strings.Contains(comm.app.dumpEndpoints("App.Server.DumpObj"), "send=0 success=0 fail=0 continuous-fail=0"))
+	n, err := comm.app.refreshEndpoints("App.Server.DumpObj")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestResetEndpoints(t *testing.T) {
+	comm := NewCommunicator()
+	comm.SetProperty("locator", "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -t 60000 -p 17890")
+	e := newTarsEndpointManager("App.Server.ResetObj", comm)
+	e.activeEpf = []endpointf.EndpointF{{Host: "127.0.0.1", Port: 10015, Timeout: 3000}, {Host: "127.0.0.2", Port: 10015, Timeout: 3000}}
+	e.reset()
+	assert.Len(t, e.GetAllEndpoint(), 2)
+
+	// the blocked endpoint is selectable after reset
+	adp := NewAdapterProxy(e.objName, &e.activeEpf[0], comm)
+	atomic.StoreInt32(&adp.status, 0)
+	e.epList.Store(e.activeEp[0].Key, adp)
+	e.activeEp = e.activeEp[1:]
+	e.reset()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&adp.status))
+	assert.Len(t, e.GetAllEndpoint(), 2)
+}
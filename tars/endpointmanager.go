@@ -32,35 +32,33 @@ type EndpointManager interface {
 	addAliveEp(ep endpoint.Endpoint)
 }
 
-var (
-	gManager         *globalManager
-	gManagerInitOnce sync.Once
-)
-
 type globalManager struct {
 	eps                 map[string]*endpointManager
 	mlock               *sync.Mutex
-	app                 *application
+	app                 *Application
 	refreshInterval     int
 	checkStatusInterval int
 }
 
-func initOnceGManager(app *application) {
-	gManagerInitOnce.Do(func() {
+func initOnceGManager(app *Application) {
+	app.gManagerInitOnce.Do(func() {
 		cltCfg := app.ClientConfig()
-		gManager = &globalManager{app: app, refreshInterval: cltCfg.RefreshEndpointInterval, checkStatusInterval: cltCfg.CheckStatusInterval}
+		gManager := &globalManager{app: app, refreshInterval: cltCfg.RefreshEndpointInterval, checkStatusInterval: cltCfg.CheckStatusInterval}
 		gManager.eps = make(map[string]*endpointManager)
 		gManager.mlock = &sync.Mutex{}
+		app.gManagerMu.Lock()
+		app.gManager = gManager
+		app.gManagerMu.Unlock()
 		go gManager.updateEndpoints()
 		go gManager.checkEpStatus()
 	})
 }
 
-// GetManager return a endpoint manager from global endpoint manager
+// GetManager return a endpoint manager from the global endpoint manager of the application of comm
 func GetManager(comm *Communicator, objName string, opts ...EndpointManagerOption) EndpointManager {
 	// tars
 	initOnceGManager(comm.app)
-	g := gManager
+	g := comm.app.gManager
 	g.mlock.Lock()
 	key := objName + ":" + comm.hashKey()
 	for _, opt := range opts {
@@ -390,12 +388,15 @@ func (e *endpointManager) doFresh() error {
 	}
 	e.freshLock.Lock()
 	defer e.freshLock.Unlock()
-	return e.findAndSetObj(e.locator)
+	err := e.findAndSetObj(e.locator)
+	// the server is not serving until the endpoints are found once, the cached endpoints may be out of date
+	e.comm.app.health.initialLoad("endpoints "+e.objName, err)
+	return err
 }
 
 func (e *endpointManager) preInvoke() {
 	atomic.AddInt32(&e.invokeNum, 1)
-	e.lastInvoke = gtime.CurrUnixTime
+	atomic.StoreInt64(&e.lastInvoke, gtime.CurrUnixTime)
 }
 
 func (e *endpointManager) postInvoke() {
@@ -480,7 +481,7 @@ func (e *endpointManager) findAndSetObj(q *queryf.QueryF) error {
 	for _, ep := range newEps {
 		if v, ok := e.epList.Load(ep.Key); ok {
 			adp := v.(*AdapterProxy)
-			if adp.status {
+			if atomic.LoadInt32(&adp.status) == 1 {
 				sortedEps = append(sortedEps, ep)
 			}
 		} else {
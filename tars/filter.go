@@ -169,61 +169,61 @@ func UseServerFilterMiddleware(sfm ...ServerFilterMiddleware) {
 }
 
 // RegisterDispatchReporter registers the server dispatch reporter
-func (a *application) RegisterDispatchReporter(f DispatchReporter) {
+func (a *Application) RegisterDispatchReporter(f DispatchReporter) {
 	a.dispatchReporter = f
 }
 
 // GetDispatchReporter returns the dispatch reporter
-func (a *application) GetDispatchReporter() DispatchReporter {
+func (a *Application) GetDispatchReporter() DispatchReporter {
 	return a.dispatchReporter
 }
 
 // registerClientFilter  registers the Client filter , and will be executed in every request.
-func (a *application) registerClientFilter(f ClientFilter) {
+func (a *Application) registerClientFilter(f ClientFilter) {
 	a.allFilters.registerClientFilter(f)
 }
 
 // registerPreClientFilter registers the client filter, and will be executed in order before every request
-func (a *application) registerPreClientFilter(f ClientFilter) {
+func (a *Application) registerPreClientFilter(f ClientFilter) {
 	a.allFilters.registerPreClientFilter(f)
 }
 
 // registerPostClientFilter registers the client filter, and will be executed in order after every request
-func (a *application) registerPostClientFilter(f ClientFilter) {
+func (a *Application) registerPostClientFilter(f ClientFilter) {
 	a.allFilters.registerPostClientFilter(f)
 }
 
 // registerServerFilter register the server filter.
-func (a *application) registerServerFilter(f ServerFilter) {
+func (a *Application) registerServerFilter(f ServerFilter) {
 	a.allFilters.registerServerFilter(f)
 }
 
 // registerPreServerFilter registers the server filter, executed in order before every request
-func (a *application) registerPreServerFilter(f ServerFilter) {
+func (a *Application) registerPreServerFilter(f ServerFilter) {
 	a.allFilters.registerPreServerFilter(f)
 }
 
 // registerPostServerFilter registers the server filter, executed in order after every request
-func (a *application) registerPostServerFilter(f ServerFilter) {
+func (a *Application) registerPostServerFilter(f ServerFilter) {
 	a.allFilters.registerPostServerFilter(f)
 }
 
 // UseClientFilterMiddleware uses the client filter middleware.
-func (a *application) UseClientFilterMiddleware(cfm ...ClientFilterMiddleware) *application {
+func (a *Application) UseClientFilterMiddleware(cfm ...ClientFilterMiddleware) *Application {
 	a.allFilters.UseClientFilterMiddleware(cfm...)
 	return a
 }
 
-func (a *application) getMiddlewareClientFilter() ClientFilter {
+func (a *Application) getMiddlewareClientFilter() ClientFilter {
 	return a.allFilters.getMiddlewareClientFilter()
 }
 
 // UseServerFilterMiddleware uses the server filter middleware.
-func (a *application) UseServerFilterMiddleware(sfm ...ServerFilterMiddleware) *application {
+func (a *Application) UseServerFilterMiddleware(sfm ...ServerFilterMiddleware) *Application {
 	a.allFilters.UseServerFilterMiddleware(sfm...)
 	return a
 }
 
-func (a *application) getMiddlewareServerFilter() ServerFilter {
+func (a *Application) getMiddlewareServerFilter() ServerFilter {
 	return a.allFilters.getMiddlewareServerFilter()
 }
@@ -0,0 +1,267 @@
+package tars
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/healthf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/util/grace"
+)
+
+// healthFunc is the function of the built-in health servant, which is served by every tars servant like tars_ping.
+const healthFunc = "tars_health"
+
+// HealthCheck checks a dependency of the server, the server is not serving if it returns an error.
+type HealthCheck func(ctx context.Context) error
+
+type healthCheck struct {
+	name    string
+	timeout time.Duration
+	check   HealthCheck
+}
+
+// health keeps the health checks, the serving status set by the user and the failed initial loads.
+type health struct {
+	mu     sync.RWMutex
+	checks []healthCheck
+	status map[string]healthf.ServingStatus
+	// the initial loads which have not succeeded yet, such as the remote config and the endpoints of the clients
+	loading map[string]string
+	loaded  map[string]bool
+}
+
+func newHealth() *health {
+	return &health{
+		status:  make(map[string]healthf.ServingStatus),
+		loading: make(map[string]string),
+		loaded:  make(map[string]bool),
+	}
+}
+
+// initialLoad records the result of loading name, the server is not serving until it succeeds once.
+// The later failures are ignored, since the last loaded copy is kept serving.
+func (h *health) initialLoad(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.loaded[name] {
+		return
+	}
+	if err != nil {
+		h.loading[name] = err.Error()
+		return
+	}
+	delete(h.loading, name)
+	h.loaded[name] = true
+}
+
+// RegisterHealthCheck registers the health check, which fails if it is not done within timeout.
+// The process started by grace restart takes over after the health checks pass and the initial loads of
+// the remote config and the endpoints of the clients succeed, the other dependencies should be checked by them.
+func RegisterHealthCheck(name string, timeout time.Duration, check HealthCheck) {
+	defaultApp.RegisterHealthCheck(name, timeout, check)
+}
+
+// RegisterHealthCheck registers the health check, which fails if it is not done within timeout.
+// The process started by grace restart takes over after the health checks pass and the initial loads of
+// the remote config and the endpoints of the clients succeed, the other dependencies should be checked by them.
+func (a *Application) RegisterHealthCheck(name string, timeout time.Duration, check HealthCheck) {
+	a.health.mu.Lock()
+	defer a.health.mu.Unlock()
+	a.health.checks = append(a.health.checks, healthCheck{name: name, timeout: timeout, check: check})
+}
+
+// SetServingStatus sets the serving status of the servant, or the whole server if servant is empty.
+func SetServingStatus(servant string, status healthf.ServingStatus) {
+	defaultApp.SetServingStatus(servant, status)
+}
+
+// SetServingStatus sets the serving status of the servant, or the whole server if servant is empty.
+func (a *Application) SetServingStatus(servant string, status healthf.ServingStatus) {
+	a.health.mu.Lock()
+	defer a.health.mu.Unlock()
+	a.health.status[servant] = status
+}
+
+// checkLiveness returns whether the server is alive, which is not if any tars server is hanged by the requests.
+func (a *Application) checkLiveness() (bool, map[string]string) {
+	details := make(map[string]string)
+	live := true
+	for obj, s := range a.goSvrs {
+		if a.svrCfg != nil && s.IsZombie(a.svrCfg.ZombieTimeout) {
+			details[obj] = "zombie"
+			live = false
+		}
+	}
+	return live, details
+}
+
+// checkHealth returns the serving status of the servant or the whole server if servant is empty,
+// and the details of the checks.
+func (a *Application) checkHealth(ctx context.Context, servant string) (healthf.ServingStatus, map[string]string) {
+	if servant != "" {
+		_, isTars := a.goSvrs[servant]
+		if _, isHttp := a.httpSvrs[servant]; !isTars && !isHttp {
+			return healthf.ServingStatus_SERVICE_UNKNOWN, map[string]string{servant: "unknown servant"}
+		}
+	}
+	serving := true
+	details := make(map[string]string)
+	if atomic.LoadInt32(&a.isShutdowning) == 1 {
+		details["shutdown"] = "shutting down"
+		serving = false
+	}
+	for obj, s := range a.goSvrs {
+		if servant != "" && servant != obj {
+			continue
+		}
+		if a.svrCfg != nil && s.IsZombie(a.svrCfg.ZombieTimeout) {
+			details[obj] = "zombie"
+			serving = false
+		}
+	}
+
+	a.health.mu.RLock()
+	for _, obj := range []string{"", servant} {
+		if status, ok := a.health.status[obj]; ok && status != healthf.ServingStatus_SERVING {
+			details["status"] = servingStatusName(status)
+			serving = false
+		}
+	}
+	for name, err := range a.health.loading {
+		details[name] = err
+		serving = false
+	}
+	checks := a.health.checks
+	a.health.mu.RUnlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c healthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- c.check(ctx) }()
+			select {
+			case errs[i] = <-done:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	for i, c := range checks {
+		if errs[i] != nil {
+			details[c.name] = errs[i].Error()
+			serving = false
+		} else {
+			details[c.name] = "ok"
+		}
+	}
+
+	if !serving {
+		return healthf.ServingStatus_NOT_SERVING, details
+	}
+	return healthf.ServingStatus_SERVING, details
+}
+
+func servingStatusName(status healthf.ServingStatus) string {
+	switch status {
+	case healthf.ServingStatus_SERVING:
+		return "SERVING"
+	case healthf.ServingStatus_NOT_SERVING:
+		return "NOT_SERVING"
+	case healthf.ServingStatus_SERVICE_UNKNOWN:
+		return "SERVICE_UNKNOWN"
+	}
+	return "UNKNOWN"
+}
+
+// healthServant implements healthf.HealthFServantWithContext.
+type healthServant struct {
+	app *Application
+}
+
+// Tars_health returns the serving status of the servant.
+func (h *healthServant) Tars_health(ctx context.Context, servant string, details *map[string]string) (healthf.ServingStatus, error) {
+	status, d := h.app.checkHealth(ctx, servant)
+	*details = d
+	return status, nil
+}
+
+// dispatchHealth handles the request to the health servant.
+func (a *Application) dispatchHealth(ctx context.Context, req *requestf.RequestPacket, rsp *requestf.ResponsePacket) error {
+	return new(healthf.HealthF).Dispatch(ctx, &healthServant{app: a}, req, rsp, true)
+}
+
+// HealthHandler returns the http handler of /healthz for the liveness and /readyz for the readiness,
+// which responds 503 if the server is not alive or ready. /readyz?servant=obj checks the servant.
+func HealthHandler() http.Handler {
+	return defaultApp.healthHandler()
+}
+
+func (a *Application) healthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		live, details := a.checkLiveness()
+		writeHealth(w, live, details)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status, details := a.checkHealth(r.Context(), r.URL.Query().Get("servant"))
+		writeHealth(w, status == healthf.ServingStatus_SERVING, details)
+	})
+	return mux
+}
+
+func writeHealth(w http.ResponseWriter, ok bool, details map[string]string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "not ok")
+	}
+	names := make([]string, 0, len(details))
+	for name := range details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %s\n", name, strings.TrimSpace(details[name]))
+	}
+}
+
+// ServeHealth serves /healthz and /readyz on addr.
+func ServeHealth(addr string) error {
+	return defaultApp.ServeHealth(addr)
+}
+
+// ServeHealth serves /healthz and /readyz on addr, it returns nil after the application shuts down.
+func (a *Application) ServeHealth(addr string) error {
+	ln, err := grace.CreateListener("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return a.serveDebug(ln, a.healthHandler())
+}
+
+func (a *Application) serveHealth() {
+	addr := a.svrCfg.HealthAddress
+	TLOG.Infof("health server start on %s", addr)
+	if err := a.ServeHealth(addr); err != nil {
+		TLOG.Errorf("health server on %s stop: %v", addr, err)
+		return
+	}
+	TLOG.Infof("health server on %s stop", addr)
+}
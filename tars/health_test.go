@@ -0,0 +1,117 @@
+package tars
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/healthf"
+	"github.com/TarsCloud/TarsGo/tars/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	app := &Application{
+		svrCfg:   newServerConfig(),
+		goSvrs:   map[string]*transport.TarsServer{"App.Server.HelloObj": transport.NewTarsServer(nil, &transport.TarsServerConf{})},
+		httpSvrs: map[string]*http.Server{},
+		health:   newHealth(),
+	}
+	h := &healthServant{app: app}
+	var details map[string]string
+	status, err := h.Tars_health(context.Background(), "", &details)
+	assert.NoError(t, err)
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_SERVING), status)
+
+	status, _ = h.Tars_health(context.Background(), "App.Server.NoObj", &details)
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_SERVICE_UNKNOWN), status)
+
+	// the failed and timeout checks
+	app.RegisterHealthCheck("db", time.Second, func(ctx context.Context) error { return nil })
+	app.RegisterHealthCheck("cache", time.Second, func(ctx context.Context) error { return errors.New("refused") })
+	app.RegisterHealthCheck("mq", 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	status, _ = h.Tars_health(context.Background(), "App.Server.HelloObj", &details)
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_NOT_SERVING), status)
+	assert.Equal(t, map[string]string{"db": "ok", "cache": "refused", "mq": context.DeadlineExceeded.Error()}, details)
+
+	handler := app.healthHandler()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "not ok\ncache: refused\ndb: ok\nmq: "+context.DeadlineExceeded.Error()+"\n", w.Body.String())
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// not serving during the shutdown
+	app.health.checks = nil
+	app.SetServingStatus("App.Server.HelloObj", healthf.ServingStatus_SERVING)
+	status, _ = h.Tars_health(context.Background(), "", &details)
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_SERVING), status)
+	app.isShutdowning = 1
+	status, details = app.checkHealth(context.Background(), "")
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_NOT_SERVING), status)
+	assert.Equal(t, map[string]string{"shutdown": "shutting down"}, details)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// TestInitialLoads test the server is not serving until the initial loads succeed.
+func TestInitialLoads(t *testing.T) {
+	app := &Application{
+		svrCfg:   newServerConfig(),
+		goSvrs:   map[string]*transport.TarsServer{},
+		httpSvrs: map[string]*http.Server{},
+		health:   newHealth(),
+	}
+	app.health.initialLoad("config app.conf", errors.New("ret -1"))
+	app.health.initialLoad("endpoints App.Server.HelloObj", errors.New("timeout"))
+	status, details := app.checkHealth(context.Background(), "")
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_NOT_SERVING), status)
+	assert.Equal(t, map[string]string{"config app.conf": "ret -1", "endpoints App.Server.HelloObj": "timeout"}, details)
+
+	app.health.initialLoad("config app.conf", nil)
+	status, details = app.checkHealth(context.Background(), "")
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_NOT_SERVING), status)
+	assert.Equal(t, map[string]string{"endpoints App.Server.HelloObj": "timeout"}, details)
+
+	// the failures after the first success are ignored
+	app.health.initialLoad("endpoints App.Server.HelloObj", nil)
+	app.health.initialLoad("config app.conf", errors.New("ret -1"))
+	status, _ = app.checkHealth(context.Background(), "")
+	assert.Equal(t, healthf.ServingStatus(healthf.ServingStatus_SERVING), status)
+}
+
+// TestServeHealthShutdown test the health server is shut down with the application.
+func TestServeHealthShutdown(t *testing.T) {
+	app := NewApplication(WithServer("TestApp", "HealthServer"), WithLogPath(t.TempDir()))
+	app.init()
+	done := make(chan error, 1)
+	go func() {
+		done <- app.ServeHealth("127.0.0.1:17993")
+	}()
+	assert.Eventually(t, func() bool {
+		rsp, err := http.Get("http://127.0.0.1:17993/healthz")
+		if err != nil {
+			return false
+		}
+		rsp.Body.Close()
+		return rsp.StatusCode == http.StatusOK
+	}, 3*time.Second, 10*time.Millisecond)
+	app.shutdownDebugServers()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the health server is not shut down")
+	}
+	_, err := http.Get("http://127.0.0.1:17993/healthz")
+	assert.Error(t, err)
+}
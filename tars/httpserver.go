@@ -37,6 +37,7 @@ type TarsHttpConf struct {
 	Version                string
 	SetId                  string
 	ExceptionStatusChecker func(int) bool
+	app                    *Application
 }
 
 // TarsHttpMux is http.ServeMux for tars http server.
@@ -105,7 +106,7 @@ func (mux *TarsHttpMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (mux *TarsHttpMux) reportHttpStat(st *httpStatInfo) {
-	if mux.cfg == nil || StatReport == nil {
+	if mux.cfg == nil {
 		return
 	}
 	cfg := mux.cfg
@@ -142,10 +143,18 @@ func (mux *TarsHttpMux) reportHttpStat(st *httpStatInfo) {
 		statBody.MinRspTime = int32(st.costTime)
 	}
 
+	app := cfg.app
+	if app == nil {
+		app = defaultApp
+	}
+	app.metrics.observeStat(&statInfo, &statBody, true)
+	if app.statReport == nil || len(app.statSinks()) == 0 {
+		return
+	}
 	info := StatInfo{}
 	info.Head = statInfo
 	info.Body = statBody
-	StatReport.pushBackMsg(info, true)
+	app.statReport.pushBackMsg(info, true)
 }
 
 // SetConfig sets the cfg tho the TarsHttpMux.
@@ -0,0 +1,175 @@
+package tars
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// initializableImp is the servant implementation initialized before the servers listen, like initialize of Tars C++.
+type initializableImp interface {
+	Initialize() error
+}
+
+// Hook is the lifecycle hook of the application, ctx is done when the hook times out.
+type Hook func(ctx context.Context) error
+
+type hookStage int
+
+const (
+	hookInit hookStage = iota
+	hookStarted
+	hookShutdownBegin
+	hookShutdownComplete
+)
+
+var hookStageNames = [...]string{"OnInit", "OnStarted", "OnShutdownBegin", "OnShutdownComplete"}
+
+func (s hookStage) String() string {
+	return hookStageNames[s]
+}
+
+// lifecycle keeps the hooks and the initializable servants in the order they are added.
+type lifecycle struct {
+	mu               sync.Mutex
+	hooks            [len(hookStageNames)][]Hook
+	initializableObj []string
+	initializable    []initializableImp
+}
+
+// OnInit adds the hook called after the config is loaded and before the servers listen,
+// the application stops starting if the hook returns error.
+func OnInit(hook Hook) {
+	defaultApp.OnInit(hook)
+}
+
+// OnStarted adds the hook called after all the servers start serving.
+func OnStarted(hook Hook) {
+	defaultApp.OnStarted(hook)
+}
+
+// OnShutdownBegin adds the hook called before the connections are drained by the graceful shutdown,
+// such as deregistering the server or stopping the consumers.
+func OnShutdownBegin(hook Hook) {
+	defaultApp.OnShutdownBegin(hook)
+}
+
+// OnShutdownComplete adds the hook called after the connections are drained by the graceful shutdown.
+func OnShutdownComplete(hook Hook) {
+	defaultApp.OnShutdownComplete(hook)
+}
+
+// OnInit adds the hook called after the config is loaded and before the servers listen,
+// the hooks are called in order, and the application stops starting if the hook returns error or times out.
+// The servants which implement Initialize() error are initialized after the hooks.
+func (a *Application) OnInit(hook Hook) {
+	a.addHook(hookInit, hook)
+}
+
+// OnStarted adds the hook called after all the servers start serving, the errors are logged and reported.
+// The process started by grace restart calls the hooks after it takes over from the parent process,
+// and does not call them if it fails to be ready.
+func (a *Application) OnStarted(hook Hook) {
+	a.addHook(hookStarted, hook)
+}
+
+// OnShutdownBegin adds the hook called before the connections are drained by the graceful shutdown,
+// the errors are logged and the shutdown goes on.
+func (a *Application) OnShutdownBegin(hook Hook) {
+	a.addHook(hookShutdownBegin, hook)
+}
+
+// OnShutdownComplete adds the hook called after the connections are drained and the servants are destroyed
+// by the graceful shutdown, the errors are logged.
+func (a *Application) OnShutdownComplete(hook Hook) {
+	a.addHook(hookShutdownComplete, hook)
+}
+
+func (a *Application) addHook(stage hookStage, hook Hook) {
+	a.lifecycle.mu.Lock()
+	defer a.lifecycle.mu.Unlock()
+	a.lifecycle.hooks[stage] = append(a.lifecycle.hooks[stage], hook)
+}
+
+func (a *Application) addInitializable(obj string, imp initializableImp) {
+	a.lifecycle.mu.Lock()
+	defer a.lifecycle.mu.Unlock()
+	a.lifecycle.initializableObj = append(a.lifecycle.initializableObj, obj)
+	a.lifecycle.initializable = append(a.lifecycle.initializable, imp)
+}
+
+// runHooks calls the hooks of the stage in order, and returns the first error if stopOnError.
+func (a *Application) runHooks(stage hookStage, stopOnError bool) error {
+	a.lifecycle.mu.Lock()
+	hooks := append([]Hook(nil), a.lifecycle.hooks[stage]...)
+	a.lifecycle.mu.Unlock()
+	var firstErr error
+	for i, hook := range hooks {
+		name := fmt.Sprintf("%s hook %d", stage, i)
+		err := callWithTimeout(name, a.svrCfg.HookTimeout, hook)
+		if err == nil {
+			continue
+		}
+		if stopOnError {
+			return err
+		}
+		TLOG.Error(err)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// initServants calls Initialize of the servants in the order they are added.
+func (a *Application) initServants() error {
+	a.lifecycle.mu.Lock()
+	objs := append([]string(nil), a.lifecycle.initializableObj...)
+	imps := append([]initializableImp(nil), a.lifecycle.initializable...)
+	a.lifecycle.mu.Unlock()
+	for i, imp := range imps {
+		name := fmt.Sprintf("servant %s Initialize", objs[i])
+		err := callWithTimeout(name, a.svrCfg.HookTimeout, func(ctx context.Context) error {
+			return imp.Initialize()
+		})
+		if err != nil {
+			return err
+		}
+		TLOG.Infof("%s success", name)
+	}
+	return nil
+}
+
+// callWithTimeout calls fn and waits for it within the timeout if it is positive, the panic of fn is returned as error.
+func callWithTimeout(name string, timeout time.Duration, fn Hook) error {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				done <- fmt.Errorf("%s panic: %v", name, err)
+			}
+		}()
+		if err := fn(ctx); err != nil {
+			done <- fmt.Errorf("%s failed: %v", name, err)
+			return
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%s timeout within %v", name, timeout)
+	}
+}
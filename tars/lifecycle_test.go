@@ -0,0 +1,118 @@
+package tars
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/adminf"
+	"github.com/stretchr/testify/assert"
+)
+
+type initAdmin struct {
+	*Admin
+	initialize func() error
+}
+
+func (a *initAdmin) Initialize() error {
+	return a.initialize()
+}
+
+type lifecycleEvents struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *lifecycleEvents) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *lifecycleEvents) hook(event string) Hook {
+	return func(ctx context.Context) error {
+		e.add(event)
+		return nil
+	}
+}
+
+func (e *lifecycleEvents) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.events...)
+}
+
+// TestLifecycleHooks test calling the lifecycle hooks and Initialize of the servants in order.
+func TestLifecycleHooks(t *testing.T) {
+	events := &lifecycleEvents{}
+	app := NewApplication(
+		WithServer("TestApp", "HookServer"),
+		WithAdapter("TestApp.HookServer.AdminObj", "tcp -h 127.0.0.1 -p 17989 -t 60000"),
+		WithLogPath(t.TempDir()),
+	)
+	app.OnInit(events.hook("init1"))
+	app.OnInit(events.hook("init2"))
+	app.OnStarted(events.hook("started"))
+	app.OnShutdownBegin(func(ctx context.Context) error {
+		events.add("shutdown begin")
+		return errors.New("deregister failed")
+	})
+	app.OnShutdownComplete(events.hook("shutdown complete"))
+	app.AddServant(new(adminf.AdminF), &initAdmin{Admin: &Admin{app: app}, initialize: func() error {
+		events.add("initialize")
+		return nil
+	}}, "TestApp.HookServer.AdminObj")
+
+	done := make(chan struct{})
+	go func() {
+		app.Run()
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return len(events.get()) == 4 }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"init1", "init2", "initialize", "started"}, events.get())
+
+	app.Shutdown()
+	<-done
+	assert.Equal(t, []string{"init1", "init2", "initialize", "started", "shutdown begin", "shutdown complete"}, events.get())
+}
+
+// TestLifecycleInitError test stopping the application when the init hook fails or times out.
+func TestLifecycleInitError(t *testing.T) {
+	newApp := func(events *lifecycleEvents, initErr error) *Application {
+		app := NewApplication(
+			WithServer("TestApp", "HookErrorServer"),
+			WithAdapter("TestApp.HookErrorServer.AdminObj", "tcp -h 127.0.0.1 -p 17990 -t 60000"),
+			WithLogPath(t.TempDir()),
+			WithHookTimeout(100*time.Millisecond),
+		)
+		app.OnStarted(events.hook("started"))
+		app.AddServant(new(adminf.AdminF), &initAdmin{Admin: &Admin{app: app}, initialize: func() error {
+			events.add("initialize")
+			return initErr
+		}}, "TestApp.HookErrorServer.AdminObj")
+		return app
+	}
+
+	// the servant fails
+	events := &lifecycleEvents{}
+	app := newApp(events, errors.New("open db failed"))
+	app.OnInit(events.hook("init"))
+	app.Run()
+	assert.Equal(t, []string{"init", "initialize"}, events.get())
+
+	// the hook times out
+	events = &lifecycleEvents{}
+	app = newApp(events, nil)
+	app.OnInit(func(ctx context.Context) error {
+		events.add("init")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	app.OnInit(events.hook("next init"))
+	start := time.Now()
+	app.Run()
+	assert.Less(t, time.Since(start), 3*time.Second)
+	assert.Equal(t, []string{"init"}, events.get())
+}
@@ -36,7 +36,12 @@ func Trace(traceKey, annotation, client, server, funcName string, ret int32, dat
 
 // GetLogger Get a logger
 func GetLogger(name string) *rogger.Logger {
-	logPath, cfg, lg := getLogger(name)
+	return defaultApp.GetLogger(name)
+}
+
+// GetLogger Get a logger in the log path of the application
+func (a *Application) GetLogger(name string) *rogger.Logger {
+	logPath, cfg, lg := a.getLogger(name)
 	// if the default writer is not ConsoleWriter, the writer has already been configured
 	if !lg.IsConsoleWriter() {
 		return lg
@@ -48,8 +53,8 @@ func GetLogger(name string) *rogger.Logger {
 	return lg
 }
 
-func getLogger(name string) (logPath string, cfg *serverConfig, lg *rogger.Logger) {
-	cfg = GetServerConfig()
+func (a *Application) getLogger(name string) (logPath string, cfg *serverConfig, lg *rogger.Logger) {
+	cfg = a.ServerConfig()
 	if cfg == nil {
 		return "", nil, rogger.GetLogger(name)
 	}
@@ -65,7 +70,12 @@ func getLogger(name string) (logPath string, cfg *serverConfig, lg *rogger.Logge
 
 // GetDayLogger Get a logger roll by day
 func GetDayLogger(name string, numDay int) *rogger.Logger {
-	logPath, _, lg := getLogger(name)
+	return defaultApp.GetDayLogger(name, numDay)
+}
+
+// GetDayLogger Get a logger roll by day in the log path of the application
+func (a *Application) GetDayLogger(name string, numDay int) *rogger.Logger {
+	logPath, _, lg := a.getLogger(name)
 	// if the default writer is not ConsoleWriter, the writer has already been configured
 	if !lg.IsConsoleWriter() {
 		return lg
@@ -76,7 +86,12 @@ func GetDayLogger(name string, numDay int) *rogger.Logger {
 
 // GetHourLogger Get a logger roll by hour
 func GetHourLogger(name string, numHour int) *rogger.Logger {
-	logPath, _, lg := getLogger(name)
+	return defaultApp.GetHourLogger(name, numHour)
+}
+
+// GetHourLogger Get a logger roll by hour in the log path of the application
+func (a *Application) GetHourLogger(name string, numHour int) *rogger.Logger {
+	logPath, _, lg := a.getLogger(name)
 	// if the default writer is not ConsoleWriter, the writer has already been configured
 	if !lg.IsConsoleWriter() {
 		return lg
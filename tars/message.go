@@ -31,6 +31,8 @@ type Message struct {
 	hashCode uint32
 	hashType HashType
 	isHash   bool
+	// rejected shows the request is rejected by the server which is shutting down
+	rejected bool
 }
 
 // Init define the beginTime
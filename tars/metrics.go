@@ -0,0 +1,408 @@
+package tars
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/statf"
+	"github.com/TarsCloud/TarsGo/tars/util/grace"
+)
+
+const (
+	contentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// MetricsHandler returns the handler which exports the rpc stats and the property reports
+// in the prometheus text format, or OpenMetrics if it is accepted by the scraper.
+// The data is collected from the first call, whether the tars stat and property servants are configured or not.
+func MetricsHandler() http.Handler {
+	return defaultApp.MetricsHandler()
+}
+
+// MetricsHandler returns the handler which exports the rpc stats and the property reports of the application.
+func (a *Application) MetricsHandler() http.Handler {
+	atomic.StoreInt32(&a.metrics.enabled, 1)
+	return a.metrics
+}
+
+// ServeMetrics serves MetricsHandler on path of addr, the listener is inherited by grace restart.
+func ServeMetrics(addr, path string) error {
+	return defaultApp.ServeMetrics(addr, path)
+}
+
+// ServeMetrics serves MetricsHandler on path of addr, the listener is inherited by grace restart.
+// It returns nil after the application shuts down.
+func (a *Application) ServeMetrics(addr, path string) error {
+	mux := http.NewServeMux()
+	mux.Handle(path, a.MetricsHandler())
+	ln, err := grace.CreateListener("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return a.serveDebug(ln, mux)
+}
+
+func (a *Application) serveMetrics() {
+	cfg := a.cltCfg
+	TLOG.Infof("metrics server start on %s%s", cfg.MetricsAddress, cfg.MetricsPath)
+	if err := a.ServeMetrics(cfg.MetricsAddress, cfg.MetricsPath); err != nil {
+		TLOG.Errorf("metrics server on %s stop: %v", cfg.MetricsAddress, err)
+		return
+	}
+	TLOG.Infof("metrics server on %s stop", cfg.MetricsAddress)
+}
+
+type statKey struct {
+	role      string
+	master    string
+	slave     string
+	iface     string
+	slaveIP   string
+	slavePort int32
+	ret       int32
+}
+
+type statMetric struct {
+	success   int64
+	timeout   int64
+	exception int64
+	// buckets counts the response time in the intervals of timePoint, and the last one is beyond all
+	buckets []int64
+	rspTime int64
+	count   int64
+}
+
+type propMetric struct {
+	policies [ReportPolicyPercentile + 1]bool
+	sum      int64
+	count    int64
+	avgSum   int64
+	avgCount int64
+	// max and min are reset on scraping
+	max, min       int
+	hasMax, hasMin bool
+	distr          []int
+	distrCounts    []int64
+	distrSum       int64
+	distrCount     int64
+	// percentile is reset on scraping
+	percentile      *Percentile
+	percentileSum   int64
+	percentileCount int64
+}
+
+type metricsExporter struct {
+	enabled int32
+	mu      sync.Mutex
+	stats   map[statKey]*statMetric
+	props   map[string]*propMetric
+}
+
+func newMetricsExporter() *metricsExporter {
+	return &metricsExporter{
+		stats: make(map[statKey]*statMetric),
+		props: make(map[string]*propMetric),
+	}
+}
+
+func (e *metricsExporter) isEnabled() bool {
+	return atomic.LoadInt32(&e.enabled) == 1
+}
+
+// observeStat mirrors a call reported by ReportStatBase.
+func (e *metricsExporter) observeStat(head *statf.StatMicMsgHead, body *statf.StatMicMsgBody, fromServer bool) {
+	if !e.isEnabled() {
+		return
+	}
+	key := statKey{
+		role:      "client",
+		master:    head.MasterName,
+		slave:     head.SlaveName,
+		iface:     head.InterfaceName,
+		slaveIP:   head.SlaveIp,
+		slavePort: head.SlavePort,
+		ret:       head.ReturnValue,
+	}
+	if fromServer {
+		key.role = "server"
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	m, ok := e.stats[key]
+	if !ok {
+		m = &statMetric{buckets: make([]int64, len(timePoint)+1)}
+		e.stats[key] = m
+	}
+	m.success += int64(body.Count)
+	m.timeout += int64(body.TimeoutCount)
+	m.exception += int64(body.ExecCount)
+	i := sort.Search(len(timePoint), func(i int) bool {
+		return body.TotalRspTime <= int64(timePoint[i])
+	})
+	m.buckets[i]++
+	m.rspTime += body.TotalRspTime
+	m.count++
+}
+
+// removeProperty forgets the property, which is evicted from the reports.
+func (e *metricsExporter) removeProperty(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.props, key)
+}
+
+// observeProperty mirrors a value set to the report method m of the property key.
+func (e *metricsExporter) observeProperty(key string, m ReportMethod, in int) {
+	if !e.isEnabled() {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.props[key]
+	if !ok {
+		p = &propMetric{}
+		e.props[key] = p
+	}
+	policy := m.Enum()
+	if policy <= ReportPolicyUnknown || policy > ReportPolicyPercentile {
+		return
+	}
+	p.policies[policy] = true
+	switch policy {
+	case ReportPolicySum:
+		p.sum += int64(in)
+	case ReportPolicyAvg:
+		p.avgSum += int64(in)
+		p.avgCount++
+	case ReportPolicyDistr:
+		d, ok := m.(*Distr)
+		if !ok {
+			return
+		}
+		if len(p.distr) != len(d.dataRange) {
+			p.distr = d.dataRange
+			p.distrCounts = make([]int64, len(d.dataRange)+1)
+		}
+		p.distrCounts[sort.SearchInts(p.distr, in)]++
+		p.distrSum += int64(in)
+		p.distrCount++
+	case ReportPolicyMax:
+		if !p.hasMax || in > p.max {
+			p.max, p.hasMax = in, true
+		}
+	case ReportPolicyMin:
+		if !p.hasMin || in < p.min {
+			p.min, p.hasMin = in, true
+		}
+	case ReportPolicyCount:
+		p.count++
+	case ReportPolicyPercentile:
+		if p.percentile == nil {
+			var percentiles []float64
+			if pm, ok := m.(*Percentile); ok {
+				percentiles = pm.percentiles
+			}
+			p.percentile = NewPercentile(percentiles...)
+		}
+		p.percentile.Set(in)
+		p.percentileSum += int64(in)
+		p.percentileCount++
+	}
+}
+
+// ServeHTTP writes the metrics.
+func (e *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mw := &metricsWriter{openMetrics: strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")}
+	e.mu.Lock()
+	e.writeStats(mw)
+	e.writeProps(mw)
+	e.mu.Unlock()
+	if mw.openMetrics {
+		mw.buf.WriteString("# EOF\n")
+		w.Header().Set("Content-Type", contentTypeOpenMetrics)
+	} else {
+		w.Header().Set("Content-Type", contentTypeText)
+	}
+	_, _ = w.Write(mw.buf.Bytes())
+}
+
+func (e *metricsExporter) writeStats(mw *metricsWriter) {
+	keys := make([]statKey, 0, len(e.stats))
+	for k := range e.stats {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.role != b.role {
+			return a.role < b.role
+		}
+		if a.master != b.master {
+			return a.master < b.master
+		}
+		if a.slave != b.slave {
+			return a.slave < b.slave
+		}
+		if a.iface != b.iface {
+			return a.iface < b.iface
+		}
+		if a.slaveIP != b.slaveIP {
+			return a.slaveIP < b.slaveIP
+		}
+		if a.slavePort != b.slavePort {
+			return a.slavePort < b.slavePort
+		}
+		return a.ret < b.ret
+	})
+	labels := func(k statKey) []string {
+		return []string{
+			"role", k.role,
+			"master", k.master,
+			"slave", k.slave,
+			"interface", k.iface,
+			"slave_ip", k.slaveIP,
+			"slave_port", strconv.Itoa(int(k.slavePort)),
+			"return_value", strconv.Itoa(int(k.ret)),
+		}
+	}
+	counters := []struct {
+		name, help string
+		get        func(m *statMetric) int64
+	}{
+		{"tars_rpc_success", "Number of the successful calls.", func(m *statMetric) int64 { return m.success }},
+		{"tars_rpc_timeout", "Number of the timeout calls.", func(m *statMetric) int64 { return m.timeout }},
+		{"tars_rpc_exception", "Number of the exceptional calls.", func(m *statMetric) int64 { return m.exception }},
+	}
+	if len(keys) == 0 {
+		return
+	}
+	for _, c := range counters {
+		mw.family(c.name, "counter", c.help)
+		for _, k := range keys {
+			mw.sample(c.name+"_total", labels(k), float64(c.get(e.stats[k])))
+		}
+	}
+
+	name := "tars_rpc_latency_milliseconds"
+	mw.family(name, "histogram", "Response time of the calls in milliseconds.")
+	for _, k := range keys {
+		m := e.stats[k]
+		var n int64
+		for i, point := range timePoint {
+			n += m.buckets[i]
+			mw.sample(name+"_bucket", append(labels(k), "le", strconv.Itoa(int(point))), float64(n))
+		}
+		mw.sample(name+"_bucket", append(labels(k), "le", "+Inf"), float64(m.count))
+		mw.sample(name+"_sum", labels(k), float64(m.rspTime))
+		mw.sample(name+"_count", labels(k), float64(m.count))
+	}
+}
+
+func (e *metricsExporter) writeProps(mw *metricsWriter) {
+	keys := make([]string, 0, len(e.props))
+	for k := range e.props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	family := func(policy ReportPolicy, name, typ, help string, write func(key string, p *propMetric)) {
+		var written bool
+		for _, key := range keys {
+			p := e.props[key]
+			if !p.policies[policy] {
+				continue
+			}
+			if !written {
+				mw.family(name, typ, help)
+				written = true
+			}
+			write(key, p)
+		}
+	}
+	family(ReportPolicySum, "tars_property_sum", "counter", "Sum of the property values.", func(key string, p *propMetric) {
+		mw.sample("tars_property_sum_total", []string{"property", key}, float64(p.sum))
+	})
+	family(ReportPolicyCount, "tars_property_count", "counter", "Number of the property values.", func(key string, p *propMetric) {
+		mw.sample("tars_property_count_total", []string{"property", key}, float64(p.count))
+	})
+	family(ReportPolicyAvg, "tars_property_avg", "summary", "Sum and count of the property values to average.", func(key string, p *propMetric) {
+		mw.sample("tars_property_avg_sum", []string{"property", key}, float64(p.avgSum))
+		mw.sample("tars_property_avg_count", []string{"property", key}, float64(p.avgCount))
+	})
+	family(ReportPolicyMax, "tars_property_max", "gauge", "Max of the property values since the last scrape.", func(key string, p *propMetric) {
+		if p.hasMax {
+			mw.sample("tars_property_max", []string{"property", key}, float64(p.max))
+			p.hasMax = false
+		}
+	})
+	family(ReportPolicyMin, "tars_property_min", "gauge", "Min of the property values since the last scrape.", func(key string, p *propMetric) {
+		if p.hasMin {
+			mw.sample("tars_property_min", []string{"property", key}, float64(p.min))
+			p.hasMin = false
+		}
+	})
+	family(ReportPolicyPercentile, "tars_property_percentile", "summary", "Percentiles of the property values since the last scrape.", func(key string, p *propMetric) {
+		pm := p.percentile
+		pm.mlock.Lock()
+		if pm.count > 0 {
+			for i, v := range pm.values() {
+				quantile := strconv.FormatFloat(pm.percentiles[i]/100, 'f', -1, 64)
+				mw.sample("tars_property_percentile", []string{"property", key, "quantile", quantile}, float64(v))
+			}
+			pm.clear()
+		}
+		pm.mlock.Unlock()
+		mw.sample("tars_property_percentile_sum", []string{"property", key}, float64(p.percentileSum))
+		mw.sample("tars_property_percentile_count", []string{"property", key}, float64(p.percentileCount))
+	})
+	family(ReportPolicyDistr, "tars_property_distr", "histogram", "Distribution of the property values.", func(key string, p *propMetric) {
+		var n int64
+		for i, point := range p.distr {
+			n += p.distrCounts[i]
+			mw.sample("tars_property_distr_bucket", []string{"property", key, "le", strconv.Itoa(point)}, float64(n))
+		}
+		mw.sample("tars_property_distr_bucket", []string{"property", key, "le", "+Inf"}, float64(p.distrCount))
+		mw.sample("tars_property_distr_sum", []string{"property", key}, float64(p.distrSum))
+		mw.sample("tars_property_distr_count", []string{"property", key}, float64(p.distrCount))
+	})
+}
+
+// metricsWriter writes the metrics in the prometheus text format or OpenMetrics.
+type metricsWriter struct {
+	buf         bytes.Buffer
+	openMetrics bool
+}
+
+// family writes the metadata of the metric family, the counters are named with _total in the prometheus text format.
+func (w *metricsWriter) family(name, typ, help string) {
+	if typ == "counter" && !w.openMetrics {
+		name += "_total"
+	}
+	w.buf.WriteString("# HELP " + name + " " + help + "\n")
+	w.buf.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// sample writes a sample with the labels in name value pairs.
+func (w *metricsWriter) sample(name string, labels []string, value float64) {
+	w.buf.WriteString(name)
+	if len(labels) > 0 {
+		w.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.buf.WriteString(labels[i] + `="` + labelEscaper.Replace(labels[i+1]) + `"`)
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(' ')
+	w.buf.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	w.buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,89 @@
+package tars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/statf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsExporter(t *testing.T) {
+	e := newMetricsExporter()
+	e.enabled = 1
+	head := &statf.StatMicMsgHead{MasterName: "App.Client", SlaveName: "App.Server", InterfaceName: "Add", SlaveIp: "127.0.0.1", SlavePort: 10015}
+	e.observeStat(head, &statf.StatMicMsgBody{Count: 1, TotalRspTime: 7}, false)
+	e.observeStat(head, &statf.StatMicMsgBody{TimeoutCount: 1, TotalRspTime: 5000}, false)
+	e.observeProperty("qps", NewSum(), 2)
+	e.observeProperty("qps", NewSum(), 3)
+	e.observeProperty("size", NewDistr([]int{10, 100}), 50)
+	e.observeProperty("size", NewMax(), 50)
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, contentTypeText, w.Header().Get("Content-Type"))
+	labels := `role="client",master="App.Client",slave="App.Server",interface="Add",slave_ip="127.0.0.1",slave_port="10015",return_value="0"`
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE tars_rpc_success_total counter\n")
+	assert.Contains(t, body, "tars_rpc_success_total{"+labels+"} 1\n")
+	assert.Contains(t, body, "tars_rpc_timeout_total{"+labels+"} 1\n")
+	assert.Contains(t, body, "tars_rpc_latency_milliseconds_bucket{"+labels+`,le="5"} 0`+"\n")
+	assert.Contains(t, body, "tars_rpc_latency_milliseconds_bucket{"+labels+`,le="10"} 1`+"\n")
+	assert.Contains(t, body, "tars_rpc_latency_milliseconds_bucket{"+labels+`,le="3000"} 1`+"\n")
+	assert.Contains(t, body, "tars_rpc_latency_milliseconds_bucket{"+labels+`,le="+Inf"} 2`+"\n")
+	assert.Contains(t, body, "tars_rpc_latency_milliseconds_sum{"+labels+"} 5007\n")
+	assert.Contains(t, body, `tars_property_sum_total{property="qps"} 5`+"\n")
+	assert.Contains(t, body, `tars_property_distr_bucket{property="size",le="10"} 0`+"\n")
+	assert.Contains(t, body, `tars_property_distr_bucket{property="size",le="100"} 1`+"\n")
+	assert.Contains(t, body, `tars_property_max{property="size"} 50`+"\n")
+
+	// OpenMetrics, and the max is reset by the last scrape
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	r.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	e.ServeHTTP(w, r)
+	assert.Equal(t, contentTypeOpenMetrics, w.Header().Get("Content-Type"))
+	body = w.Body.String()
+	assert.Contains(t, body, "# TYPE tars_rpc_success counter\n")
+	assert.Contains(t, body, "tars_rpc_success_total{"+labels+"} 1\n")
+	assert.NotContains(t, body, `tars_property_max{property="size"}`)
+	assert.Contains(t, body, "# EOF\n")
+}
+
+func TestMetricsWithoutPropertyServant(t *testing.T) {
+	h := MetricsHandler()
+	CreatePropertyReport("test.metrics", NewCount()).Report(1)
+	ReportCount("test.metrics", 1)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `tars_property_count_total{property="test.metrics"} 2`+"\n")
+}
+
+// TestServeMetricsShutdown test the metrics server is shut down with the application, so the address can be served again.
+func TestServeMetricsShutdown(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		app := NewApplication(WithServer("TestApp", "MetricsServer"), WithLogPath(t.TempDir()))
+		done := make(chan error, 1)
+		go func() {
+			done <- app.ServeMetrics("127.0.0.1:17992", "/metrics")
+		}()
+		assert.Eventually(t, func() bool {
+			rsp, err := http.Get("http://127.0.0.1:17992/metrics")
+			if err != nil {
+				return false
+			}
+			rsp.Body.Close()
+			return rsp.StatusCode == http.StatusOK
+		}, 3*time.Second, 10*time.Millisecond)
+		app.shutdownDebugServers()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(3 * time.Second):
+			t.Fatal("the metrics server is not shut down")
+		}
+	}
+}
@@ -48,11 +48,16 @@ func (n *NotifyHelper) ReportNotifyInfo(level int32, info string) {
 
 // ReportNotifyInfo reports notify information with level and info
 func ReportNotifyInfo(level int32, info string) {
-	svrCfg := GetServerConfig()
+	defaultApp.ReportNotifyInfo(level, info)
+}
+
+// ReportNotifyInfo reports notify information with level and info
+func (a *Application) ReportNotifyInfo(level int32, info string) {
+	svrCfg := a.ServerConfig()
 	if svrCfg.Notify == "" {
 		return
 	}
-	comm := GetCommunicator()
+	comm := a.Communicator()
 	ha := new(NotifyHelper)
 	ha.SetNotifyInfo(comm, svrCfg.Notify, svrCfg.App, svrCfg.Server, svrCfg.Container)
 	defer func() {
@@ -0,0 +1,185 @@
+package tars
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/util/endpoint"
+	"github.com/TarsCloud/TarsGo/tars/util/ssl"
+)
+
+// Option configures the Application created by NewApplication.
+type Option func(*Application)
+
+// Configure sets the options of the default application, which must be called before
+// the config is used, such as at the beginning of main.
+func Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(defaultApp)
+	}
+}
+
+// WithConfigFile sets the path of the tars config file of the application.
+func WithConfigFile(path string) Option {
+	return func(a *Application) {
+		a.configPath = path
+	}
+}
+
+func withConfigFlag() Option {
+	return func(a *Application) {
+		a.configFlag = true
+	}
+}
+
+// configOption returns the option setting the config, which is applied after the config file is loaded,
+// so the options override the config file and the application can run without the config file.
+func configOption(f func(a *Application)) Option {
+	return func(a *Application) {
+		a.configOpts = append(a.configOpts, f)
+	}
+}
+
+// WithServer sets the app and server name.
+func WithServer(app, server string) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.App = app
+		a.svrCfg.Server = server
+	})
+}
+
+// WithAdapter adds the adapter serving obj on the endpoint, such as "tcp -h 127.0.0.1 -p 10015 -t 60000",
+// the adapter of obj in the config file is replaced.
+func WithAdapter(obj, end string, opts ...ServerConfOption) Option {
+	return configOption(func(a *Application) {
+		adapter := obj + "Adapter"
+		for _, name := range a.serList {
+			if a.svrCfg.Adapters[name].Obj == obj {
+				adapter = name
+				break
+			}
+		}
+		if _, ok := a.svrCfg.Adapters[adapter]; !ok {
+			a.serList = append(a.serList, adapter)
+		}
+		a.svrCfg.Adapters[adapter] = adapterConfig{endpoint.Parse(end), "tars", obj, 0}
+		a.adapterOpts[adapter] = opts
+	})
+}
+
+// WithLocal sets the endpoint of the admin servant, such as "tcp -h 127.0.0.1 -p 10014 -t 3000".
+func WithLocal(end string) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.Local = end
+	})
+}
+
+// WithLogPath sets the path of the log files, the logs are written to LogPath/App/Server.
+func WithLogPath(path string) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.LogPath = path
+	})
+}
+
+// WithLogLevel sets the log level, such as "DEBUG" or "INFO".
+func WithLogLevel(level string) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.LogLevel = level
+	})
+}
+
+// WithDataPath sets the path of the data files, such as the app cache.
+func WithDataPath(path string) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.DataPath = path
+	})
+}
+
+// WithLocator sets the obj of the registry, such as "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890".
+func WithLocator(locator string) Option {
+	return configOption(func(a *Application) {
+		a.cltCfg.Locator = locator
+	})
+}
+
+// WithServerTimeout sets the timeouts of reading, writing and handling the requests,
+// and closing the idle connections of the servers, the zero ones are not changed.
+func WithServerTimeout(read, write, handle, idle time.Duration) Option {
+	return configOption(func(a *Application) {
+		if read > 0 {
+			a.svrCfg.ReadTimeout = read
+		}
+		if write > 0 {
+			a.svrCfg.WriteTimeout = write
+		}
+		if handle > 0 {
+			a.svrCfg.HandleTimeout = handle
+		}
+		if idle > 0 {
+			a.svrCfg.IdleTimeout = idle
+		}
+	})
+}
+
+// WithGracedownTimeout sets the timeout of the graceful shutdown.
+func WithGracedownTimeout(timeout time.Duration) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.GracedownTimeout = timeout
+	})
+}
+
+// WithHookTimeout sets the timeout of each lifecycle hook and servant Initialize.
+func WithHookTimeout(timeout time.Duration) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.HookTimeout = timeout
+	})
+}
+
+// WithGraceRestartTimeout sets the timeout for the new process of grace restart to be ready,
+// which is ready once the initial loads succeed and the health checks registered by RegisterHealthCheck pass.
+func WithGraceRestartTimeout(timeout time.Duration) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.GraceRestartTimeout = timeout
+	})
+}
+
+// WithInvokeTimeout sets the default timeout of the requests of the clients.
+func WithInvokeTimeout(timeout time.Duration) Option {
+	return configOption(func(a *Application) {
+		a.cltCfg.ReqDefaultTimeout = int32(timeout / time.Millisecond)
+	})
+}
+
+// WithServerTLS sets the files of the common tls config of the ssl adapters,
+// which is overridden by WithTlsConfig of the adapter.
+func WithServerTLS(ca, cert, key string, verifyClient bool, ciphers string) Option {
+	return configOption(func(a *Application) {
+		a.svrCfg.CA = ca
+		a.svrCfg.Cert = cert
+		a.svrCfg.Key = key
+		a.svrCfg.VerifyClient = verifyClient
+		a.svrCfg.Ciphers = ciphers
+	})
+}
+
+// WithClientTLS sets the tls config of the clients, which panics if the files are invalid.
+func WithClientTLS(ca, cert, key, ciphers string) Option {
+	return configOption(func(a *Application) {
+		tlsConfig, err := ssl.NewClientTlsConfig(ca, cert, key, ciphers)
+		if err != nil {
+			panic(err)
+		}
+		a.clientTlsConfig = tlsConfig
+	})
+}
+
+// WithClientTlsConfig sets the tls config of the clients of obj, or all the clients if obj is empty.
+func WithClientTlsConfig(obj string, tlsConfig *tls.Config) Option {
+	return configOption(func(a *Application) {
+		if obj == "" {
+			a.clientTlsConfig = tlsConfig
+			return
+		}
+		a.clientObjTlsConfig[obj] = tlsConfig
+	})
+}
@@ -0,0 +1,210 @@
+package tars
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/TarsCloud/TarsGo/tars/util/conf"
+	"gopkg.in/yaml.v3"
+)
+
+// The tars config file is overlaid by the yaml overlay file and then the environment variables,
+// and the options of the application override them all.
+const (
+	// ConfigOverlayEnv is the environment variable of the path of the yaml overlay file.
+	ConfigOverlayEnv = "TARS_CONFIG_OVERLAY"
+
+	// the environment variables like TARS_SERVER_MAXROUTINE override /tars/application/server<maxroutine>
+	applicationEnvPrefix = "TARS_APPLICATION_"
+	serverEnvPrefix      = "TARS_SERVER_"
+	clientEnvPrefix      = "TARS_CLIENT_"
+)
+
+var (
+	applicationConfigKeys = conf.ApplicationKeys
+	serverConfigKeys      = conf.ServerKeys
+	clientConfigKeys      = conf.ClientKeys
+
+	// the secrets are not printed by tars.viewconfig
+	secretConfigFields = map[string]bool{"AdminHttpToken": true, "AdminHttpPassword": true}
+)
+
+// ConfigKeys returns the known keys of the domains of the tars config, which is used by conf.Lint to find the unknown keys.
+func ConfigKeys() map[string][]string {
+	return conf.TarsKeys()
+}
+
+// configOverride is the config value overridden by the overlays.
+type configOverride struct {
+	path   string
+	value  string
+	source string
+}
+
+// WithConfigOverlay sets the path of the yaml overlay file, which overrides the tars config file like
+//
+//	server:
+//	  maxroutine: 1000
+//	  App.Server.HelloObjAdapter:
+//	    threads: 4
+//	client:
+//	  locator: tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890
+//
+// the keys are relative to /tars/application, and the path is also read from TARS_CONFIG_OVERLAY.
+func WithConfigOverlay(path string) Option {
+	return func(a *Application) {
+		a.overlayPath = path
+	}
+}
+
+func (a *Application) configOverlayPath() string {
+	if a.overlayPath != "" {
+		return a.overlayPath
+	}
+	return os.Getenv(ConfigOverlayEnv)
+}
+
+// hasConfigOverlay returns whether the config is overridden by the overlay file or the environment variables.
+func (a *Application) hasConfigOverlay() bool {
+	if a.configOverlayPath() != "" {
+		return true
+	}
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, applicationEnvPrefix) || strings.HasPrefix(env, serverEnvPrefix) || strings.HasPrefix(env, clientEnvPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlayConf overrides the config by the yaml overlay file and then the environment variables.
+func (a *Application) overlayConf(c *conf.Conf) error {
+	if path := a.configOverlayPath(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read config overlay %s error: %v", path, err)
+		}
+		var overlay map[string]interface{}
+		if err = yaml.Unmarshal(data, &overlay); err != nil {
+			return fmt.Errorf("parse config overlay %s error: %v", path, err)
+		}
+		if err = a.overlayYaml(c, "/tars/application", overlay, "yaml "+path); err != nil {
+			return err
+		}
+	}
+
+	envs := os.Environ()
+	sort.Strings(envs)
+	for _, env := range envs {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		var domain, name string
+		var keys []string
+		switch {
+		case strings.HasPrefix(kv[0], applicationEnvPrefix):
+			domain, name, keys = "/tars/application", strings.TrimPrefix(kv[0], applicationEnvPrefix), applicationConfigKeys
+		case strings.HasPrefix(kv[0], serverEnvPrefix):
+			domain, name, keys = "/tars/application/server", strings.TrimPrefix(kv[0], serverEnvPrefix), serverConfigKeys
+		case strings.HasPrefix(kv[0], clientEnvPrefix):
+			domain, name, keys = "/tars/application/client", strings.TrimPrefix(kv[0], clientEnvPrefix), clientConfigKeys
+		default:
+			continue
+		}
+		key, ok := findConfigKey(keys, name)
+		if !ok {
+			TLOG.Warnf("unknown config key of the environment variable %s", kv[0])
+			continue
+		}
+		a.overrideConf(c, domain+"<"+key+">", kv[1], "env "+kv[0])
+	}
+	return nil
+}
+
+func (a *Application) overlayYaml(c *conf.Conf, domain string, overlay map[string]interface{}, source string) error {
+	keys := make([]string, 0, len(overlay))
+	for k := range overlay {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := overlay[k].(type) {
+		case map[string]interface{}:
+			if err := a.overlayYaml(c, domain+"/"+k, v, source); err != nil {
+				return err
+			}
+		case []interface{}:
+			return fmt.Errorf("config overlay %s: unsupported list of %s/%s", source, domain, k)
+		case nil:
+			a.overrideConf(c, domain+"<"+k+">", "", source)
+		default:
+			a.overrideConf(c, domain+"<"+k+">", fmt.Sprint(v), source)
+		}
+	}
+	return nil
+}
+
+func (a *Application) overrideConf(c *conf.Conf, path, value, source string) {
+	c.Set(path, value)
+	a.configOverrides = append(a.configOverrides, configOverride{path: path, value: value, source: source})
+}
+
+// findConfigKey finds the key matching the name of the environment variable, ignoring the case, dashes and underscores.
+func findConfigKey(keys []string, name string) (string, bool) {
+	normalize := strings.NewReplacer("-", "", "_", "")
+	name = normalize.Replace(strings.ToLower(name))
+	for _, key := range keys {
+		if normalize.Replace(strings.ToLower(key)) == name {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// effectiveConfig returns the effective server and client config and the values overridden by the overlays.
+func (a *Application) effectiveConfig() string {
+	var sb strings.Builder
+	if len(a.configOverrides) > 0 {
+		sb.WriteString("[overrides]\n")
+		for _, o := range a.configOverrides {
+			value := o.value
+			if key := o.path[strings.LastIndex(o.path, "<")+1 : len(o.path)-1]; strings.Contains(key, "password") || strings.Contains(key, "token") {
+				value = "******"
+			}
+			fmt.Fprintf(&sb, "%s=%s from %s\n", o.path, value, o.source)
+		}
+	}
+	sb.WriteString("[server]\n")
+	writeConfigFields(&sb, reflect.ValueOf(a.ServerConfig()).Elem())
+	names := make([]string, 0, len(a.svrCfg.Adapters))
+	for name := range a.svrCfg.Adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		adapter := a.svrCfg.Adapters[name]
+		fmt.Fprintf(&sb, "Adapters.%s=%s %s\n", name, adapter.Obj, adapter.Endpoint.String())
+	}
+	sb.WriteString("[client]\n")
+	writeConfigFields(&sb, reflect.ValueOf(a.ClientConfig()).Elem())
+	return sb.String()
+}
+
+func writeConfigFields(sb *strings.Builder, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Map {
+			continue
+		}
+		value := fmt.Sprint(v.Field(i).Interface())
+		if secretConfigFields[field.Name] && value != "" {
+			value = "******"
+		}
+		fmt.Fprintf(sb, "%s=%s\n", field.Name, value)
+	}
+}
@@ -0,0 +1,54 @@
+package tars
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfigOverlay test overriding the config file by the yaml overlay file and the environment variables.
+func TestConfigOverlay(t *testing.T) {
+	overlay := filepath.Join(t.TempDir(), "overlay.yaml")
+	err := os.WriteFile(overlay, []byte(`
+server:
+  maxroutine: 200
+  handletimeout: 5000
+  admin-http-password: secret
+  TestApp.OverlayServer.AdminObjAdapter:
+    threads: 4
+client:
+  locator: tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890
+`), 0644)
+	assert.NoError(t, err)
+	t.Setenv(serverEnvPrefix+"MAXROUTINE", "300")
+	t.Setenv(serverEnvPrefix+"LOG_LEVEL", "DEBUG")
+	t.Setenv(clientEnvPrefix+"ASYNC_INVOKE_TIMEOUT", "2000")
+	t.Setenv(serverEnvPrefix+"NO_SUCH_KEY", "1")
+
+	app := newTestApplication(t, "TestApp", "OverlayServer", 17986, WithConfigOverlay(overlay), WithGracedownTimeout(time.Second))
+	svrCfg := app.ServerConfig()
+	assert.Equal(t, int32(300), svrCfg.MaxInvoke)
+	assert.Equal(t, 5*time.Second, svrCfg.HandleTimeout)
+	assert.Equal(t, "DEBUG", svrCfg.LogLevel)
+	assert.Equal(t, time.Second, svrCfg.GracedownTimeout)
+	assert.Equal(t, 4, svrCfg.Adapters["TestApp.OverlayServer.AdminObjAdapter"].Threads)
+	assert.Equal(t, "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890", app.ClientConfig().Locator)
+	assert.Equal(t, 2000, app.ClientConfig().AsyncInvokeTimeout)
+
+	adm := &Admin{app: app}
+	out, err := adm.Notify("tars.viewconfig")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "[overrides]\n/tars/application/client<locator>=tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890 from yaml "+overlay+"\n")
+	assert.Contains(t, out, "/tars/application/server<maxroutine>=200 from yaml "+overlay+"\n")
+	assert.Contains(t, out, "/tars/application/server<maxroutine>=300 from env TARS_SERVER_MAXROUTINE\n")
+	assert.Contains(t, out, "/tars/application/server<admin-http-password>=****** from yaml")
+	assert.Contains(t, out, "[server]\n")
+	assert.Contains(t, out, "MaxInvoke=300\n")
+	assert.Contains(t, out, "AdminHttpPassword=******\n")
+	assert.Contains(t, out, "Adapters.TestApp.OverlayServer.AdminObjAdapter=TestApp.OverlayServer.AdminObj tcp -h 127.0.0.1 -p 17986 -t 60000\n")
+	assert.Contains(t, out, "[client]\nLocator=tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890\n")
+	assert.NotContains(t, out, "secret")
+}
@@ -2,15 +2,17 @@ package tars
 
 import (
 	"fmt"
+	"math"
+	"math/bits"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/TarsCloud/TarsGo/tars/protocol/res/propertyf"
-	utilSync "github.com/TarsCloud/TarsGo/tars/util/sync"
 	"github.com/TarsCloud/TarsGo/tars/util/tools"
 )
 
@@ -18,13 +20,14 @@ import (
 type ReportPolicy int
 
 const (
-	ReportPolicyUnknown ReportPolicy = iota
-	ReportPolicySum                  // 1
-	ReportPolicyAvg                  // 2
-	ReportPolicyDistr                // 3
-	ReportPolicyMax                  // 4
-	ReportPolicyMin                  // 5
-	ReportPolicyCount                // 6
+	ReportPolicyUnknown    ReportPolicy = iota
+	ReportPolicySum                     // 1
+	ReportPolicyAvg                     // 2
+	ReportPolicyDistr                   // 3
+	ReportPolicyMax                     // 4
+	ReportPolicyMin                     // 5
+	ReportPolicyCount                   // 6
+	ReportPolicyPercentile              // 7
 )
 
 func (p ReportPolicy) String() string {
@@ -41,17 +44,22 @@ func (p ReportPolicy) String() string {
 		return "Min"
 	case ReportPolicyCount:
 		return "Count"
+	case ReportPolicyPercentile:
+		return "Percentile"
 	default:
 		return "Unknown"
 	}
 }
 
 // ReportMethod is the interface for all kinds of report methods.
+// The methods other than the built-in policies are reported with the name of String() if they implement fmt.Stringer.
 type ReportMethod interface {
 	Enum() ReportPolicy
 	Set(int)
+	// Get returns the result and clears it for the next interval.
 	Get() string
-	clear()
+	// IsDefault shows whether there is nothing to report in the interval, it is called before Get.
+	IsDefault() bool
 }
 
 // Sum report methods.
@@ -86,6 +94,13 @@ func (s *Sum) clear() {
 	s.data = 0
 }
 
+// IsDefault shows whether the sum is zero.
+func (s *Sum) IsDefault() bool {
+	s.mlock.Lock()
+	defer s.mlock.Unlock()
+	return s.data == 0
+}
+
 // Set sets a value tho the sum method.
 func (s *Sum) Set(in int) {
 	s.mlock.Lock()
@@ -140,6 +155,13 @@ func (a *Avg) clear() {
 	a.sum = 0
 }
 
+// IsDefault shows whether no value is set.
+func (a *Avg) IsDefault() bool {
+	a.mlock.Lock()
+	defer a.mlock.Unlock()
+	return a.count == 0
+}
+
 // Max struct is for counting the Max value for the reporting value.
 type Max struct {
 	data  int
@@ -180,6 +202,13 @@ func (m *Max) clear() {
 	m.data = -9999999
 }
 
+// IsDefault shows whether no value is set.
+func (m *Max) IsDefault() bool {
+	m.mlock.Lock()
+	defer m.mlock.Unlock()
+	return m.data == -9999999
+}
+
 // Min is the struct for counting the min value.
 type Min struct {
 	data  int
@@ -221,6 +250,13 @@ func (m *Min) clear() {
 	m.data = 0
 }
 
+// IsDefault shows whether no value other than zero is set.
+func (m *Min) IsDefault() bool {
+	m.mlock.Lock()
+	defer m.mlock.Unlock()
+	return m.data == 0
+}
+
 // Distr is used for counting the distribution of the reporting values.
 type Distr struct {
 	dataRange []int
@@ -273,6 +309,11 @@ func (d *Distr) clear() {
 	}
 }
 
+// IsDefault shows whether the distribution has no range.
+func (d *Distr) IsDefault() bool {
+	return len(d.dataRange) == 0
+}
+
 // Count is for counting the total of reporting
 type Count struct {
 	mlock *sync.Mutex
@@ -312,41 +353,183 @@ func (c *Count) clear() {
 	c.data = 0
 }
 
+// IsDefault shows whether no value is set.
+func (c *Count) IsDefault() bool {
+	c.mlock.Lock()
+	defer c.mlock.Unlock()
+	return c.data == 0
+}
+
+// Percentile reports the percentiles of the values in the interval, such as p50, p90, p99 and p999.
+// The values are counted in log-linear buckets like HdrHistogram, the error of the percentiles is less than 1/64,
+// and the negative values are counted as 0.
+type Percentile struct {
+	percentiles []float64
+	buckets     map[int]int
+	count       int
+	min, max    int
+	mlock       *sync.Mutex
+}
+
+// percentileSubBits is the bits of the sub buckets, the values below 1<<percentileSubBits are counted exactly.
+const percentileSubBits = 7
+
+// NewPercentile new and init the Percentile with the percentiles in (0, 100], default to 50, 90, 99 and 99.9.
+func NewPercentile(percentiles ...float64) *Percentile {
+	if len(percentiles) == 0 {
+		percentiles = []float64{50, 90, 99, 99.9}
+	}
+	return &Percentile{
+		percentiles: percentiles,
+		buckets:     make(map[int]int),
+		mlock:       new(sync.Mutex),
+	}
+}
+
+// Enum return the report policy
+func (p *Percentile) Enum() ReportPolicy {
+	return ReportPolicyPercentile
+}
+
+// Set sets the value for counting percentiles.
+func (p *Percentile) Set(in int) {
+	p.mlock.Lock()
+	defer p.mlock.Unlock()
+	if in < 0 {
+		in = 0
+	}
+	if p.count == 0 || in < p.min {
+		p.min = in
+	}
+	if p.count == 0 || in > p.max {
+		p.max = in
+	}
+	p.buckets[percentileIndex(in)]++
+	p.count++
+}
+
+// Get gets the percentiles as "50|v1,90|v2,99|v3,99.9|v4".
+func (p *Percentile) Get() string {
+	p.mlock.Lock()
+	defer p.mlock.Unlock()
+	var s string
+	for i, v := range p.values() {
+		if i != 0 {
+			s += ","
+		}
+		s = s + strconv.FormatFloat(p.percentiles[i], 'f', -1, 64) + "|" + strconv.Itoa(v)
+	}
+	p.clear()
+	return s
+}
+
+// IsDefault shows whether no value is set.
+func (p *Percentile) IsDefault() bool {
+	p.mlock.Lock()
+	defer p.mlock.Unlock()
+	return p.count == 0
+}
+
+func (p *Percentile) clear() {
+	p.buckets = make(map[int]int)
+	p.count = 0
+}
+
+// values returns the value of each percentile, which is the middle of its bucket between min and max.
+func (p *Percentile) values() []int {
+	indexes := make([]int, 0, len(p.buckets))
+	for index := range p.buckets {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	values := make([]int, len(p.percentiles))
+	for i, percentile := range p.percentiles {
+		rank := int(math.Ceil(percentile / 100 * float64(p.count)))
+		var n int
+		for _, index := range indexes {
+			n += p.buckets[index]
+			if n >= rank {
+				values[i] = percentileValue(index)
+				break
+			}
+		}
+		if values[i] < p.min {
+			values[i] = p.min
+		}
+		if values[i] > p.max {
+			values[i] = p.max
+		}
+	}
+	return values
+}
+
+// percentileIndex returns the bucket of v. The values below 1<<percentileSubBits have their own buckets,
+// and the others are split into 1<<(percentileSubBits-1) buckets between each power of 2.
+func percentileIndex(v int) int {
+	if v < 1<<percentileSubBits {
+		return v
+	}
+	shift := bits.Len(uint(v)) - percentileSubBits
+	return 1<<percentileSubBits + (shift-1)<<(percentileSubBits-1) + v>>shift - 1<<(percentileSubBits-1)
+}
+
+// percentileValue returns the middle value of the bucket index.
+func percentileValue(index int) int {
+	if index < 1<<percentileSubBits {
+		return index
+	}
+	index -= 1 << percentileSubBits
+	shift := index>>(percentileSubBits-1) + 1
+	lower := (index&(1<<(percentileSubBits-1)-1) + 1<<(percentileSubBits-1)) << shift
+	return lower + (1<<shift-1)/2
+}
+
 // PropertyReportHelper is helper struct for property report.
 type PropertyReportHelper struct {
 	reportPtrs *sync.Map //string -> *PropertyReport
+	vecs       sync.Map  //string -> *PropertyVec
+	app        *Application
 	comm       *Communicator
-	pf         *propertyf.PropertyF
 	node       string
 }
 
-var (
-	// ProHelper is global PropertyReportHelper instance
-	ProHelper *PropertyReportHelper
-	proOnce   utilSync.Once
-)
+// ProHelper is the PropertyReportHelper instance of the default application
+var ProHelper *PropertyReportHelper
 
-func initProReport() error {
-	cfg := GetClientConfig()
+func initProReport(app *Application) error {
+	if app.proHelper == nil {
+		// the reports are kept for the metrics exporter and the sinks without the property servant
+		app.proHelper = &PropertyReportHelper{reportPtrs: new(sync.Map), app: app}
+		if app == defaultApp {
+			ProHelper = app.proHelper
+		}
+		go app.proHelper.Run()
+	}
+	cfg := app.ClientConfig()
 	if cfg.Property == "" || !strings.Contains(cfg.Property, "@") {
 		return fmt.Errorf("property emptry")
 	}
-	comm := GetCommunicator()
-	ProHelper = new(PropertyReportHelper)
-	ProHelper.Init(comm, GetClientConfig().Property)
-	go ProHelper.Run()
+	app.proHelper.Init(app.Communicator(), cfg.Property)
 	return nil
 }
 
-// ReportToServer report to the remote propertyreport server.
+// propertyHelper returns the PropertyReportHelper of the application.
+func (a *Application) propertyHelper() *PropertyReportHelper {
+	_ = a.proOnce.Do(func() error {
+		return initProReport(a)
+	})
+	return a.proHelper
+}
+
+// ReportToServer report to the remote propertyreport server and the other property sinks.
 func (p *PropertyReportHelper) ReportToServer() {
 	statMsg := make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody)
 
 	var head propertyf.StatPropMsgHead
 	head.IPropertyVer = 2
-	head.ModuleName = GetClientConfig().ModuleName
+	head.ModuleName = p.app.ClientConfig().ModuleName
 	head.Ip = tools.GetLocalIP()
-	if cfg := GetServerConfig(); cfg != nil {
+	if cfg := p.app.ServerConfig(); cfg != nil {
 		head.Ip = cfg.LocalIP
 		if cfg.Enableset {
 			setList := strings.Split(cfg.Setdivision, ".")
@@ -361,89 +544,48 @@ func (p *PropertyReportHelper) ReportToServer() {
 	p.reportPtrs.Range(func(key, val interface{}) bool {
 		v := val.(*PropertyReport)
 		head.PropertyName = v.key
-
-		var body propertyf.StatPropMsgBody
-		body.VInfo = make([]propertyf.StatPropInfo, 0)
-		for _, m := range v.reportMethods {
-			if nil == m {
-				continue
-			}
-
-			var info propertyf.StatPropInfo
-			bflag := false
-			desc := m.Enum().String()
-			result := m.Get()
-
-			// todo: use interface method IsDefault() bool
-			switch desc {
-			case "Sum":
-				if result != "0" {
-					bflag = true
-				}
-			case "Avg":
-				if result != "0" {
-					bflag = true
-				}
-			case "Distr":
-				if result != "" {
-					bflag = true
-				}
-			case "Max":
-				if result != "-9999999" {
-					bflag = true
-				}
-			case "Min":
-				if result != "0" {
-					bflag = true
-				}
-			case "Count":
-				if result != "0" {
-					bflag = true
-				}
-			default:
-				bflag = true
-			}
-
-			if !bflag {
-				continue
-			}
-			info.Policy = desc
-			info.Value = result
-			body.VInfo = append(body.VInfo, info)
-		}
-		statMsg[head] = body
-
+		statMsg[head] = propertyBody(v)
+		return true
+	})
+	p.vecs.Range(func(key, val interface{}) bool {
+		val.(*PropertyVec).collect(head, statMsg)
 		return true
 	})
 
-	var cnt int
-	var tmpStatMsg = make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody)
-	for k, v := range statMsg {
-		cnt++
-		if cnt >= 20 {
-			_, err := p.pf.ReportPropMsg(tmpStatMsg)
-			if err != nil {
-				TLOG.Error("Send to property server Error", reflect.TypeOf(err), err)
-			}
-			tmpStatMsg = make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody)
-		}
-		tmpStatMsg[k] = v
+	if len(statMsg) == 0 {
+		return
 	}
-	if len(tmpStatMsg) > 0 {
-		_, err := p.pf.ReportPropMsg(tmpStatMsg)
-		if err != nil {
+	for _, sink := range p.app.propertySinks() {
+		if err := sink.ReportProperty(statMsg); err != nil {
 			TLOG.Error("Send to property server Error", reflect.TypeOf(err), err)
 		}
 	}
 }
 
-// Init inits the PropertyReportHelper
+// isBuiltinPolicy shows whether the policy is one of the built-in policies.
+func isBuiltinPolicy(policy ReportPolicy) bool {
+	return policy > ReportPolicyUnknown && policy <= ReportPolicyPercentile
+}
+
+func policyName(m ReportMethod) string {
+	if policy := m.Enum(); isBuiltinPolicy(policy) {
+		return policy.String()
+	}
+	if s, ok := m.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return m.Enum().String()
+}
+
+// Init inits the PropertyReportHelper, and adds the sink of the property servant.
 func (p *PropertyReportHelper) Init(comm *Communicator, node string) {
 	p.node = node
 	p.comm = comm
-	p.pf = new(propertyf.PropertyF)
-	p.reportPtrs = new(sync.Map)
-	p.comm.StringToProxy(p.node, p.pf)
+	p.app = comm.app
+	if p.reportPtrs == nil {
+		p.reportPtrs = new(sync.Map)
+	}
+	comm.app.AddPropertySink(NewTarsPropertySink(comm, node))
 }
 
 // AddToReport adds the user's PropertyReport to the PropertyReportHelper
@@ -454,7 +596,7 @@ func (p *PropertyReportHelper) AddToReport(pr *PropertyReport) {
 // Run start the properting report goroutine.
 func (p *PropertyReportHelper) Run() {
 	// todo , get report interval from config
-	loop := time.NewTicker(GetServerConfig().PropertyReportInterval)
+	loop := time.NewTicker(p.app.ServerConfig().PropertyReportInterval)
 	for range loop.C {
 		p.ReportToServer()
 	}
@@ -462,24 +604,100 @@ func (p *PropertyReportHelper) Run() {
 
 // PropertyReport property report struct
 type PropertyReport struct {
+	lastReport    int64 // unix nano of the last report of the series in a PropertyVec
 	key           string
 	reportMethods []ReportMethod
+	series        bool
+	metrics       *metricsExporter
+
+	// mu guards the report methods, custom are the ones other than the built-in policies whose Enum may be any value
+	mu     sync.Mutex
+	custom []ReportMethod
+}
+
+// addMethod adds the report method, which replaces the built-in one of the same policy.
+func (p *PropertyReport) addMethod(m ReportMethod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if policy := m.Enum(); isBuiltinPolicy(policy) {
+		p.reportMethods[policy] = m
+		return
+	}
+	for _, c := range p.custom {
+		if reflect.TypeOf(c).Comparable() && c == m {
+			return
+		}
+	}
+	p.custom = append(p.custom, m)
+}
+
+// builtinMethod returns the report method of the built-in policy, which is created by newMethod if it is not set.
+func (p *PropertyReport) builtinMethod(policy ReportPolicy, newMethod func() ReportMethod) ReportMethod {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reportMethods[policy] == nil {
+		p.reportMethods[policy] = newMethod()
+	}
+	return p.reportMethods[policy]
+}
+
+// methods returns the built-in report methods set and the custom ones.
+func (p *PropertyReport) methods() []ReportMethod {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	methods := make([]ReportMethod, 0, len(p.reportMethods)+len(p.custom))
+	for _, m := range p.reportMethods {
+		if m != nil {
+			methods = append(methods, m)
+		}
+	}
+	return append(methods, p.custom...)
+}
+
+// propertyBody returns the values of the report methods which have been reported.
+func propertyBody(v *PropertyReport) propertyf.StatPropMsgBody {
+	var body propertyf.StatPropMsgBody
+	body.VInfo = make([]propertyf.StatPropInfo, 0)
+	for _, m := range v.methods() {
+		if m.IsDefault() {
+			continue
+		}
+		var info propertyf.StatPropInfo
+		info.Policy = policyName(m)
+		info.Value = m.Get()
+		body.VInfo = append(body.VInfo, info)
+	}
+	return body
 }
 
 // Report reports a value.
 func (p *PropertyReport) Report(in int) {
-	for _, v := range p.reportMethods {
-		if v != nil {
-			v.Set(in)
-		}
+	if p.series {
+		atomic.StoreInt64(&p.lastReport, time.Now().UnixNano())
+	}
+	for _, v := range p.methods() {
+		p.set(v, in)
+	}
+}
+
+// set sets the value to the report method m, and mirrors it to the metrics exporter.
+func (p *PropertyReport) set(m ReportMethod, in int) {
+	m.Set(in)
+	if p.metrics != nil {
+		p.metrics.observeProperty(p.key, m, in)
 	}
 }
 
 // CreatePropertyReport creates the property report instance with the key.
 func CreatePropertyReport(key string, argv ...ReportMethod) *PropertyReport {
-	ptr := GetPropertyReport(key)
+	return defaultApp.CreatePropertyReport(key, argv...)
+}
+
+// CreatePropertyReport creates the property report instance with the key.
+func (a *Application) CreatePropertyReport(key string, argv ...ReportMethod) *PropertyReport {
+	ptr := a.GetPropertyReport(key)
 	for _, v := range argv {
-		ptr.reportMethods[v.Enum()] = v
+		ptr.addMethod(v)
 	}
 
 	return ptr
@@ -487,8 +705,13 @@ func CreatePropertyReport(key string, argv ...ReportMethod) *PropertyReport {
 
 // GetPropertyReport gets the property report instance with the key.
 func GetPropertyReport(key string) *PropertyReport {
-	proOnce.Do(initProReport)
-	if val, ok := ProHelper.reportPtrs.Load(key); ok {
+	return defaultApp.GetPropertyReport(key)
+}
+
+// GetPropertyReport gets the property report instance with the key.
+func (a *Application) GetPropertyReport(key string) *PropertyReport {
+	helper := a.propertyHelper()
+	if val, ok := helper.reportPtrs.Load(key); ok {
 		if pr, ok := val.(*PropertyReport); ok {
 			return pr
 		}
@@ -496,68 +719,56 @@ func GetPropertyReport(key string) *PropertyReport {
 
 	ptr := new(PropertyReport)
 	ptr.key = key
-	ptr.reportMethods = make([]ReportMethod, 7)
-	ProHelper.AddToReport(ptr)
+	ptr.reportMethods = make([]ReportMethod, ReportPolicyPercentile+1)
+	ptr.metrics = a.metrics
+	helper.AddToReport(ptr)
 
 	return ptr
 }
 
 // ReportSum sum report
 func ReportSum(key string, i int) {
-	ptr := GetPropertyReport(key)
-	policy := ReportPolicySum
-	if nil == ptr.reportMethods[policy] {
-		ptr.reportMethods[policy] = NewSum()
-	}
-	ptr.reportMethods[policy].Set(i)
+	defaultApp.ReportSum(key, i)
+}
+
+// ReportSum reports i to the Sum of the property key, which is created at the first report.
+func (a *Application) ReportSum(key string, i int) {
+	ptr := a.GetPropertyReport(key)
+	ptr.set(ptr.builtinMethod(ReportPolicySum, func() ReportMethod { return NewSum() }), i)
 }
 
 // ReportAvg avg report
 func ReportAvg(key string, i int) {
 	ptr := GetPropertyReport(key)
-	policy := ReportPolicyAvg
-	if nil == ptr.reportMethods[policy] {
-		ptr.reportMethods[policy] = NewAvg()
-	}
-	ptr.reportMethods[policy].Set(i)
+	ptr.set(ptr.builtinMethod(ReportPolicyAvg, func() ReportMethod { return NewAvg() }), i)
 }
 
 // ReportMax max report
 func ReportMax(key string, i int) {
 	ptr := GetPropertyReport(key)
-	policy := ReportPolicyMax
-	if nil == ptr.reportMethods[policy] {
-		ptr.reportMethods[policy] = NewMax()
-	}
-	ptr.reportMethods[policy].Set(i)
+	ptr.set(ptr.builtinMethod(ReportPolicyMax, func() ReportMethod { return NewMax() }), i)
 }
 
 // ReportMin min report
 func ReportMin(key string, i int) {
 	ptr := GetPropertyReport(key)
-	policy := ReportPolicyMin
-	if nil == ptr.reportMethods[policy] {
-		ptr.reportMethods[policy] = NewMin()
-	}
-	ptr.reportMethods[policy].Set(i)
+	ptr.set(ptr.builtinMethod(ReportPolicyMin, func() ReportMethod { return NewMin() }), i)
 }
 
 // ReportDistr distr report
 func ReportDistr(key string, in []int, i int) {
 	ptr := GetPropertyReport(key)
-	policy := ReportPolicyDistr
-	if nil == ptr.reportMethods[policy] {
-		ptr.reportMethods[policy] = NewDistr(in)
-	}
-	ptr.reportMethods[policy].Set(i)
+	ptr.set(ptr.builtinMethod(ReportPolicyDistr, func() ReportMethod { return NewDistr(in) }), i)
 }
 
 // ReportCount count report
 func ReportCount(key string, i int) {
 	ptr := GetPropertyReport(key)
-	policy := ReportPolicyCount
-	if nil == ptr.reportMethods[policy] {
-		ptr.reportMethods[policy] = NewCount()
-	}
-	ptr.reportMethods[policy].Set(i)
+	ptr.set(ptr.builtinMethod(ReportPolicyCount, func() ReportMethod { return NewCount() }), i)
+}
+
+// ReportPercentile percentile report
+func ReportPercentile(key string, i int) {
+	ptr := GetPropertyReport(key)
+	ptr.set(ptr.builtinMethod(ReportPolicyPercentile, func() ReportMethod { return NewPercentile() }), i)
 }
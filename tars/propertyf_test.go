@@ -0,0 +1,120 @@
+package tars
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	p := NewPercentile()
+	assert.True(t, p.IsDefault())
+	for i := 1; i <= 1000; i++ {
+		p.Set(i)
+	}
+	assert.False(t, p.IsDefault())
+	want := map[string]int{"50": 500, "90": 900, "99": 990, "99.9": 999}
+	for _, kv := range strings.Split(p.Get(), ",") {
+		pair := strings.Split(kv, "|")
+		v, err := strconv.Atoi(pair[1])
+		assert.NoError(t, err)
+		assert.InDelta(t, want[pair[0]], v, float64(want[pair[0]])/64, "p%s", pair[0])
+	}
+	assert.True(t, p.IsDefault())
+
+	// the error is less than 1/64 for the large values
+	for _, v := range []int{0, 1, 127, 128, 129, 1000, 123456, 1 << 40} {
+		got := percentileValue(percentileIndex(v))
+		assert.InDelta(t, v, got, float64(v)/64, "value %d", v)
+	}
+}
+
+// lastValue is a custom report method.
+type lastValue struct {
+	mu    sync.Mutex
+	value int
+	set   bool
+}
+
+func (l *lastValue) Enum() ReportPolicy { return ReportPolicy(100) }
+
+func (l *lastValue) String() string { return "Last" }
+
+func (l *lastValue) Set(in int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.value, l.set = in, true
+}
+
+func (l *lastValue) Get() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.set = false
+	return strconv.Itoa(l.value)
+}
+
+func (l *lastValue) IsDefault() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !l.set
+}
+
+// firstValue is a custom report method whose Enum is negative and conflicts with lastValue's.
+type firstValue struct {
+	lastValue
+	enum ReportPolicy
+}
+
+func (f *firstValue) Enum() ReportPolicy { return f.enum }
+
+func (f *firstValue) String() string { return "First" }
+
+func (f *firstValue) Set(in int) {
+	if f.IsDefault() {
+		f.lastValue.Set(in)
+	}
+}
+
+func TestCustomPolicies(t *testing.T) {
+	sink := NewMemorySink()
+	AddPropertySink(sink)
+
+	last := &lastValue{}
+	p := CreatePropertyReport("test.custom", &firstValue{enum: -1}, last, &firstValue{enum: 100})
+	CreatePropertyReport("test.custom", last)
+	p.Report(3)
+	p.Report(5)
+	ProHelper.ReportToServer()
+	assert.Equal(t, map[string]string{"First": "3", "Last": "5"}, sink.Property("test.custom"))
+}
+
+func TestReportToServerPolicies(t *testing.T) {
+	sink := NewMemorySink()
+	AddPropertySink(sink)
+
+	p := CreatePropertyReport("test.policies", NewSum(), NewMax(), NewPercentile(50), &lastValue{})
+	p.Report(3)
+	ReportPercentile("test.policies", 5)
+	ProHelper.ReportToServer()
+	assert.Equal(t, map[string]string{"Sum": "3", "Max": "3", "Percentile": "50|3", "Last": "3"}, sink.Property("test.policies"))
+
+	// nothing is reported in the interval
+	ProHelper.ReportToServer()
+	assert.Equal(t, map[string]string{}, sink.Property("test.policies"))
+}
+
+func TestApplicationReportSum(t *testing.T) {
+	app := NewApplication(WithServer("TestApp", "SumServer"), WithLogPath(t.TempDir()))
+	sink := NewMemorySink()
+	app.AddPropertySink(sink)
+
+	// the reports are added to the same Sum until it is reported
+	app.ReportSum("test.dropped", 2)
+	app.ReportSum("test.dropped", 3)
+	app.propertyHelper().ReportToServer()
+	assert.Equal(t, map[string]string{"Sum": "5"}, sink.Property("test.dropped"))
+	assert.Nil(t, GetPropertyReport("test.dropped").reportMethods[ReportPolicySum])
+}
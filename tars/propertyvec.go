@@ -0,0 +1,182 @@
+package tars
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/propertyf"
+)
+
+const (
+	// PropertyVecMaxSeries is the default max number of the series of a PropertyVec.
+	PropertyVecMaxSeries = 1000
+	// PropertyVecIdleTimeout is the default time to evict the series without report.
+	PropertyVecIdleTimeout = 10 * time.Minute
+
+	// propertyOverflow is the label value of the series which the reports beyond the max series go to.
+	propertyOverflow = "__overflow__"
+)
+
+// PropertyVec is a family of property reports with the same name partitioned by the label values.
+// Each series is reported as the property named like name{label1=value1,label2=value2}.
+type PropertyVec struct {
+	name        string
+	labelNames  []string
+	methods     []ReportMethod
+	maxSeries   int
+	idleTimeout time.Duration
+	metrics     *metricsExporter
+
+	mu     sync.Mutex
+	series map[string]*PropertyReport
+}
+
+// CreatePropertyVec creates the property reports of name partitioned by labelNames.
+// The vec with the same name is shared, and each series is reported by Sum unless WithMethods is set.
+func CreatePropertyVec(name string, labelNames ...string) *PropertyVec {
+	return defaultApp.CreatePropertyVec(name, labelNames...)
+}
+
+// CreatePropertyVec creates the property reports of name partitioned by labelNames.
+// The vec with the same name is shared, and each series is reported by Sum unless WithMethods is set.
+func (a *Application) CreatePropertyVec(name string, labelNames ...string) *PropertyVec {
+	helper := a.propertyHelper()
+	vec := &PropertyVec{
+		name:        name,
+		labelNames:  labelNames,
+		methods:     []ReportMethod{NewSum()},
+		maxSeries:   PropertyVecMaxSeries,
+		idleTimeout: PropertyVecIdleTimeout,
+		metrics:     a.metrics,
+		series:      make(map[string]*PropertyReport),
+	}
+	val, _ := helper.vecs.LoadOrStore(name, vec)
+	return val.(*PropertyVec)
+}
+
+// WithMethods sets the report methods of the series, each series has its own methods like argv.
+// The methods other than the built-in ones must implement New() ReportMethod to create the methods of the series.
+func (v *PropertyVec) WithMethods(argv ...ReportMethod) *PropertyVec {
+	for _, m := range argv {
+		// panic on the methods which can not be created
+		newReportMethod(m)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.methods = argv
+	return v
+}
+
+// WithMaxSeries sets the max number of the series, the reports of the new label values beyond it
+// go to the series whose label values are all __overflow__.
+func (v *PropertyVec) WithMaxSeries(n int) *PropertyVec {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.maxSeries = n
+	return v
+}
+
+// WithIdleTimeout sets the time to evict the series without report.
+func (v *PropertyVec) WithIdleTimeout(d time.Duration) *PropertyVec {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.idleTimeout = d
+	return v
+}
+
+// With returns the property report of the labels, the missing labels are empty and the unknown ones are ignored.
+func (v *PropertyVec) With(labels map[string]string) *PropertyReport {
+	values := make([]string, len(v.labelNames))
+	for i, name := range v.labelNames {
+		values[i] = labels[name]
+	}
+	return v.WithLabelValues(values...)
+}
+
+// WithLabelValues returns the property report of the label values in the order of the label names.
+func (v *PropertyVec) WithLabelValues(values ...string) *PropertyReport {
+	key := v.seriesName(values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if p, ok := v.series[key]; ok {
+		return p
+	}
+	if len(v.series) >= v.maxSeries {
+		overflow := make([]string, len(v.labelNames))
+		for i := range overflow {
+			overflow[i] = propertyOverflow
+		}
+		key = v.seriesName(overflow)
+		if p, ok := v.series[key]; ok {
+			return p
+		}
+		TLOG.Errorf("property %s has more than %d series, the new ones are reported as %s", v.name, v.maxSeries, key)
+	}
+	p := &PropertyReport{key: key, reportMethods: make([]ReportMethod, ReportPolicyPercentile+1), series: true, metrics: v.metrics}
+	for _, m := range v.methods {
+		p.addMethod(newReportMethod(m))
+	}
+	atomic.StoreInt64(&p.lastReport, time.Now().UnixNano())
+	v.series[key] = p
+	return p
+}
+
+// seriesName returns the property name of the series as name{label1=value1,label2=value2}.
+func (v *PropertyVec) seriesName(values []string) string {
+	var b strings.Builder
+	b.WriteString(v.name)
+	b.WriteByte('{')
+	for i, name := range v.labelNames {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		if i < len(values) {
+			b.WriteString(values[i])
+		}
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// collect adds the bodies of the series to statMsg, and evicts the idle series.
+func (v *PropertyVec) collect(head propertyf.StatPropMsgHead, statMsg map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	idle := time.Now().Add(-v.idleTimeout).UnixNano()
+	for key, p := range v.series {
+		head.PropertyName = key
+		statMsg[head] = propertyBody(p)
+		if atomic.LoadInt64(&p.lastReport) < idle {
+			delete(v.series, key)
+			v.metrics.removeProperty(key)
+		}
+	}
+}
+
+// newReportMethod creates a method of the same policy as m.
+func newReportMethod(m ReportMethod) ReportMethod {
+	switch method := m.(type) {
+	case *Sum:
+		return NewSum()
+	case *Avg:
+		return NewAvg()
+	case *Max:
+		return NewMax()
+	case *Min:
+		return NewMin()
+	case *Count:
+		return NewCount()
+	case *Distr:
+		return NewDistr(method.dataRange)
+	case *Percentile:
+		return NewPercentile(method.percentiles...)
+	case interface{ New() ReportMethod }:
+		return method.New()
+	}
+	panic(fmt.Errorf("report method %T of policy %s has no New() ReportMethod", m, policyName(m)))
+}
@@ -0,0 +1,37 @@
+package tars
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertyVec(t *testing.T) {
+	sink := NewMemorySink()
+	AddPropertySink(sink)
+
+	vec := CreatePropertyVec("test.vec", "tenant", "method").WithMethods(NewSum(), NewDistr([]int{10, 100})).WithMaxSeries(2)
+	assert.Equal(t, vec, CreatePropertyVec("test.vec", "tenant", "method"))
+	vec.With(map[string]string{"tenant": "a", "method": "Add"}).Report(5)
+	vec.WithLabelValues("a", "Add").Report(50)
+	vec.With(map[string]string{"tenant": "b"}).Report(1)
+	// beyond the max series
+	vec.WithLabelValues("c", "Add").Report(2)
+	vec.WithLabelValues("d", "Add").Report(3)
+	assert.Panics(t, func() { vec.WithMethods(&lastValue{}) })
+
+	ProHelper.ReportToServer()
+	assert.Equal(t, map[string]string{"Sum": "55", "Distr": "10|1,100|1"}, sink.Property("test.vec{tenant=a,method=Add}"))
+	assert.Equal(t, map[string]string{"Sum": "1", "Distr": "10|1,100|0"}, sink.Property("test.vec{tenant=b,method=}"))
+	assert.Equal(t, map[string]string{"Sum": "5", "Distr": "10|2,100|0"}, sink.Property("test.vec{tenant=__overflow__,method=__overflow__}"))
+	assert.Nil(t, sink.Property("test.vec{tenant=c,method=Add}"))
+
+	// the idle series are evicted after they are reported
+	vec.WithIdleTimeout(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	ProHelper.ReportToServer()
+	vec.mu.Lock()
+	assert.Empty(t, vec.series)
+	vec.mu.Unlock()
+}
@@ -512,6 +512,21 @@ func (b *Reader) skipField(ty byte) error {
 	return nil
 }
 
+// ReadHead reads the type and tag of the next field.
+func (b *Reader) ReadHead() (ty, tag byte, err error) {
+	return b.readHead()
+}
+
+// UnreadHead puts back the head read by ReadHead, tag is the tag it returned.
+func (b *Reader) UnreadHead(tag byte) {
+	b.unreadHead(tag)
+}
+
+// SkipField skips the value of a field whose head has been read.
+func (b *Reader) SkipField(ty byte) error {
+	return b.skipField(ty)
+}
+
 // SkipToStructEnd for skip to the StructEnd tag.
 func (b *Reader) SkipToStructEnd() error {
 	for {
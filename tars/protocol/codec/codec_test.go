@@ -645,3 +645,39 @@ func TestReader_SkipToNoCheck(t *testing.T) {
 		t.Errorf("SkipToNoCheck error. wantType;%v, gotType:%v \n", FLOAT, gotType)
 	}
 }
+
+// TestReadHead tests reading heads and skipping fields without knowing the schema.
+func TestReadHead(t *testing.T) {
+	b := NewBuffer()
+	if err := b.WriteString("hello", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteInt32(0, 20); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteInt64(math.MaxInt64, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := r(b)
+	ty, tag, err := reader.ReadHead()
+	if err != nil || ty != STRING1 || tag != 1 {
+		t.Fatalf("ReadHead got type:%d tag:%d err:%v", ty, tag, err)
+	}
+	if err = reader.SkipField(ty); err != nil {
+		t.Fatal(err)
+	}
+	ty, tag, err = reader.ReadHead()
+	if err != nil || ty != ZeroTag || tag != 20 {
+		t.Fatalf("ReadHead got type:%d tag:%d err:%v", ty, tag, err)
+	}
+	ty, tag, err = reader.ReadHead()
+	if err != nil || ty != LONG || tag != 3 {
+		t.Fatalf("ReadHead got type:%d tag:%d err:%v", ty, tag, err)
+	}
+	reader.UnreadHead(tag)
+	var data int64
+	if err = reader.ReadInt64(&data, 3, true); err != nil || data != math.MaxInt64 {
+		t.Fatalf("ReadInt64 after UnreadHead got %d err:%v", data, err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package healthf comment
+// This file was generated by tars2go 1.2.1
+// Generated from HealthF.tars
+package healthf
+
+import (
+	"fmt"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = fmt.Errorf
+var _ = codec.FromInt8
+
+type ServingStatus int32
+
+const (
+	ServingStatus_UNKNOWN         = 0
+	ServingStatus_SERVING         = 1
+	ServingStatus_NOT_SERVING     = 2
+	ServingStatus_SERVICE_UNKNOWN = 3
+)
@@ -0,0 +1,426 @@
+// Package healthf comment
+// This file was generated by tars2go 1.2.1
+// Generated from HealthF.tars
+package healthf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	m "github.com/TarsCloud/TarsGo/tars/model"
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/basef"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/tup"
+	"github.com/TarsCloud/TarsGo/tars/util/current"
+	"github.com/TarsCloud/TarsGo/tars/util/endpoint"
+	"github.com/TarsCloud/TarsGo/tars/util/tools"
+	"unsafe"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = fmt.Errorf
+	_ = codec.FromInt8
+	_ = unsafe.Pointer(nil)
+	_ = bytes.ErrTooLarge
+)
+
+// HealthF struct
+type HealthF struct {
+	servant m.Servant
+}
+
+// SetServant sets servant for the service.
+func (obj *HealthF) SetServant(servant m.Servant) {
+	obj.servant = servant
+}
+
+// TarsSetTimeout sets the timeout for the servant which is in ms.
+func (obj *HealthF) TarsSetTimeout(timeout int) {
+	obj.servant.TarsSetTimeout(timeout)
+}
+
+// TarsSetProtocol sets the protocol for the servant.
+func (obj *HealthF) TarsSetProtocol(p m.Protocol) {
+	obj.servant.TarsSetProtocol(p)
+}
+
+// Endpoints returns all active endpoint.Endpoint
+func (obj *HealthF) Endpoints() []*endpoint.Endpoint {
+	return obj.servant.Endpoints()
+}
+
+// Tars_health is the proxy function for the method defined in the tars file, with the context
+func (obj *HealthF) Tars_health(servant string, details *map[string]string, opts ...map[string]string) (ServingStatus, error) {
+	return obj.Tars_healthWithContext(context.Background(), servant, details, opts...)
+}
+
+// Tars_healthWithContext is the proxy function for the method defined in the tars file, with the context
+func (obj *HealthF) Tars_healthWithContext(tarsCtx context.Context, servant string, details *map[string]string, opts ...map[string]string) (ret ServingStatus, err error) {
+	var (
+		length int32
+		have   bool
+		ty     byte
+	)
+	buf := codec.NewBuffer()
+	err = buf.WriteString(servant, 1)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteHead(codec.MAP, 2)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteInt32(int32(len(*details)), 0)
+	if err != nil {
+		return ret, err
+	}
+
+	for k0, v0 := range *details {
+
+		err = buf.WriteString(k0, 0)
+		if err != nil {
+			return ret, err
+		}
+
+		err = buf.WriteString(v0, 1)
+		if err != nil {
+			return ret, err
+		}
+
+	}
+
+	var statusMap map[string]string
+	var contextMap map[string]string
+	if len(opts) == 1 {
+		contextMap = opts[0]
+	} else if len(opts) == 2 {
+		contextMap = opts[0]
+		statusMap = opts[1]
+	}
+
+	tarsResp := new(requestf.ResponsePacket)
+	err = obj.servant.TarsInvoke(tarsCtx, 0, "tars_health", buf.ToBytes(), statusMap, contextMap, tarsResp)
+	if err != nil {
+		return ret, err
+	}
+
+	readBuf := codec.NewReader(tools.Int8ToByte(tarsResp.SBuffer))
+	err = readBuf.ReadInt32((*int32)(&ret), 0, true)
+	if err != nil {
+		return ret, err
+	}
+
+	_, err = readBuf.SkipTo(codec.MAP, 2, true)
+	if err != nil {
+		return ret, err
+	}
+
+	err = readBuf.ReadInt32(&length, 0, true)
+	if err != nil {
+		return ret, err
+	}
+
+	*details = make(map[string]string)
+	for i1, e1 := int32(0), length; i1 < e1; i1++ {
+		var k1 string
+		var v1 string
+
+		err = readBuf.ReadString(&k1, 0, false)
+		if err != nil {
+			return ret, err
+		}
+
+		err = readBuf.ReadString(&v1, 1, false)
+		if err != nil {
+			return ret, err
+		}
+
+		(*details)[k1] = v1
+	}
+
+	if len(opts) == 1 {
+		for k := range contextMap {
+			delete(contextMap, k)
+		}
+		for k, v := range tarsResp.Context {
+			contextMap[k] = v
+		}
+	} else if len(opts) == 2 {
+		for k := range contextMap {
+			delete(contextMap, k)
+		}
+		for k, v := range tarsResp.Context {
+			contextMap[k] = v
+		}
+		for k := range statusMap {
+			delete(statusMap, k)
+		}
+		for k, v := range tarsResp.Status {
+			statusMap[k] = v
+		}
+	}
+	_ = length
+	_ = have
+	_ = ty
+	return ret, nil
+}
+
+// Tars_healthOneWayWithContext is the proxy function for the method defined in the tars file, with the context
+func (obj *HealthF) Tars_healthOneWayWithContext(tarsCtx context.Context, servant string, details *map[string]string, opts ...map[string]string) (ret ServingStatus, err error) {
+	var (
+		length int32
+		have   bool
+		ty     byte
+	)
+	buf := codec.NewBuffer()
+	err = buf.WriteString(servant, 1)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteHead(codec.MAP, 2)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteInt32(int32(len(*details)), 0)
+	if err != nil {
+		return ret, err
+	}
+
+	for k2, v2 := range *details {
+
+		err = buf.WriteString(k2, 0)
+		if err != nil {
+			return ret, err
+		}
+
+		err = buf.WriteString(v2, 1)
+		if err != nil {
+			return ret, err
+		}
+
+	}
+
+	var statusMap map[string]string
+	var contextMap map[string]string
+	if len(opts) == 1 {
+		contextMap = opts[0]
+	} else if len(opts) == 2 {
+		contextMap = opts[0]
+		statusMap = opts[1]
+	}
+
+	tarsResp := new(requestf.ResponsePacket)
+	err = obj.servant.TarsInvoke(tarsCtx, 1, "tars_health", buf.ToBytes(), statusMap, contextMap, tarsResp)
+	if err != nil {
+		return ret, err
+	}
+
+	_ = length
+	_ = have
+	_ = ty
+	return ret, nil
+}
+
+type HealthFServant interface {
+	Tars_health(servant string, details *map[string]string) (ret ServingStatus, err error)
+}
+type HealthFServantWithContext interface {
+	Tars_health(tarsCtx context.Context, servant string, details *map[string]string) (ret ServingStatus, err error)
+}
+
+// Dispatch is used to call the server side implement for the method defined in the tars file. withContext shows using context or not.
+func (obj *HealthF) Dispatch(tarsCtx context.Context, val interface{}, tarsReq *requestf.RequestPacket, tarsResp *requestf.ResponsePacket, withContext bool) (err error) {
+	var (
+		length int32
+		have   bool
+		ty     byte
+	)
+	readBuf := codec.NewReader(tools.Int8ToByte(tarsReq.SBuffer))
+	buf := codec.NewBuffer()
+	switch tarsReq.SFuncName {
+	case "tars_health":
+		var servant string
+		var details map[string]string
+		details = make(map[string]string)
+
+		if tarsReq.IVersion == basef.TARSVERSION {
+
+			err = readBuf.ReadString(&servant, 1, true)
+			if err != nil {
+				return err
+			}
+
+		} else if tarsReq.IVersion == basef.TUPVERSION {
+			reqTup := tup.NewUniAttribute()
+			reqTup.Decode(readBuf)
+
+			var tupBuffer []byte
+
+			reqTup.GetBuffer("servant", &tupBuffer)
+			readBuf.Reset(tupBuffer)
+			err = readBuf.ReadString(&servant, 0, true)
+			if err != nil {
+				return err
+			}
+
+		} else if tarsReq.IVersion == basef.JSONVERSION {
+			var jsonData map[string]interface{}
+			decoder := json.NewDecoder(bytes.NewReader(readBuf.ToBytes()))
+			decoder.UseNumber()
+			err = decoder.Decode(&jsonData)
+			if err != nil {
+				return fmt.Errorf("decode reqpacket failed, error: %+v", err)
+			}
+			{
+				jsonStr, _ := json.Marshal(jsonData["servant"])
+				if err = json.Unmarshal(jsonStr, &servant); err != nil {
+					return err
+				}
+			}
+
+		} else {
+			err = fmt.Errorf("decode reqpacket fail, error version: %d", tarsReq.IVersion)
+			return err
+		}
+
+		var funRet ServingStatus
+		if !withContext {
+			imp := val.(HealthFServant)
+			funRet, err = imp.Tars_health(servant, &details)
+		} else {
+			imp := val.(HealthFServantWithContext)
+			funRet, err = imp.Tars_health(tarsCtx, servant, &details)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if tarsReq.IVersion == basef.TARSVERSION {
+			buf.Reset()
+
+			err = buf.WriteInt32(int32(funRet), 0)
+			if err != nil {
+				return err
+			}
+
+			err = buf.WriteHead(codec.MAP, 2)
+			if err != nil {
+				return err
+			}
+
+			err = buf.WriteInt32(int32(len(details)), 0)
+			if err != nil {
+				return err
+			}
+
+			for k3, v3 := range details {
+
+				err = buf.WriteString(k3, 0)
+				if err != nil {
+					return err
+				}
+
+				err = buf.WriteString(v3, 1)
+				if err != nil {
+					return err
+				}
+
+			}
+
+		} else if tarsReq.IVersion == basef.TUPVERSION {
+			rspTup := tup.NewUniAttribute()
+
+			err = buf.WriteInt32(int32(funRet), 0)
+			if err != nil {
+				return err
+			}
+
+			rspTup.PutBuffer("", buf.ToBytes())
+			rspTup.PutBuffer("tars_ret", buf.ToBytes())
+
+			buf.Reset()
+			err = buf.WriteHead(codec.MAP, 0)
+			if err != nil {
+				return err
+			}
+
+			err = buf.WriteInt32(int32(len(details)), 0)
+			if err != nil {
+				return err
+			}
+
+			for k4, v4 := range details {
+
+				err = buf.WriteString(k4, 0)
+				if err != nil {
+					return err
+				}
+
+				err = buf.WriteString(v4, 1)
+				if err != nil {
+					return err
+				}
+
+			}
+			rspTup.PutBuffer("details", buf.ToBytes())
+
+			buf.Reset()
+			err = rspTup.Encode(buf)
+			if err != nil {
+				return err
+			}
+		} else if tarsReq.IVersion == basef.JSONVERSION {
+			rspJson := map[string]interface{}{}
+			rspJson["tars_ret"] = funRet
+			rspJson["details"] = details
+
+			var rspByte []byte
+			if rspByte, err = json.Marshal(rspJson); err != nil {
+				return err
+			}
+
+			buf.Reset()
+			err = buf.WriteSliceUint8(rspByte)
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("func mismatch")
+	}
+	var statusMap map[string]string
+	if status, ok := current.GetResponseStatus(tarsCtx); ok && status != nil {
+		statusMap = status
+	}
+	var contextMap map[string]string
+	if ctx, ok := current.GetResponseContext(tarsCtx); ok && ctx != nil {
+		contextMap = ctx
+	}
+	*tarsResp = requestf.ResponsePacket{
+		IVersion:     tarsReq.IVersion,
+		CPacketType:  0,
+		IRequestId:   tarsReq.IRequestId,
+		IMessageType: 0,
+		IRet:         0,
+		SBuffer:      tools.ByteToInt8(buf.ToBytes()),
+		Status:       statusMap,
+		SResultDesc:  "",
+		Context:      contextMap,
+	}
+
+	_ = readBuf
+	_ = buf
+	_ = length
+	_ = have
+	_ = ty
+	return nil
+}
@@ -0,0 +1,442 @@
+// Package reflectionf comment
+// This file was generated by tars2go 1.2.1
+// Generated from ReflectionF.tars
+package reflectionf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	m "github.com/TarsCloud/TarsGo/tars/model"
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/basef"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/tup"
+	"github.com/TarsCloud/TarsGo/tars/util/current"
+	"github.com/TarsCloud/TarsGo/tars/util/endpoint"
+	"github.com/TarsCloud/TarsGo/tars/util/tools"
+	"unsafe"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = fmt.Errorf
+	_ = codec.FromInt8
+	_ = unsafe.Pointer(nil)
+	_ = bytes.ErrTooLarge
+)
+
+// ReflectionF struct
+type ReflectionF struct {
+	servant m.Servant
+}
+
+// SetServant sets servant for the service.
+func (obj *ReflectionF) SetServant(servant m.Servant) {
+	obj.servant = servant
+}
+
+// TarsSetTimeout sets the timeout for the servant which is in ms.
+func (obj *ReflectionF) TarsSetTimeout(timeout int) {
+	obj.servant.TarsSetTimeout(timeout)
+}
+
+// TarsSetProtocol sets the protocol for the servant.
+func (obj *ReflectionF) TarsSetProtocol(p m.Protocol) {
+	obj.servant.TarsSetProtocol(p)
+}
+
+// Endpoints returns all active endpoint.Endpoint
+func (obj *ReflectionF) Endpoints() []*endpoint.Endpoint {
+	return obj.servant.Endpoints()
+}
+
+// Tars_reflection is the proxy function for the method defined in the tars file, with the context
+func (obj *ReflectionF) Tars_reflection(servant string, info *ServantInfo, servants *[]string, opts ...map[string]string) (int32, error) {
+	return obj.Tars_reflectionWithContext(context.Background(), servant, info, servants, opts...)
+}
+
+// Tars_reflectionWithContext is the proxy function for the method defined in the tars file, with the context
+func (obj *ReflectionF) Tars_reflectionWithContext(tarsCtx context.Context, servant string, info *ServantInfo, servants *[]string, opts ...map[string]string) (ret int32, err error) {
+	var (
+		length int32
+		have   bool
+		ty     byte
+	)
+	buf := codec.NewBuffer()
+	err = buf.WriteString(servant, 1)
+	if err != nil {
+		return ret, err
+	}
+
+	err = (*info).WriteBlock(buf, 2)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteHead(codec.LIST, 3)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteInt32(int32(len(*servants)), 0)
+	if err != nil {
+		return ret, err
+	}
+
+	for _, v := range *servants {
+
+		err = buf.WriteString(v, 0)
+		if err != nil {
+			return ret, err
+		}
+
+	}
+
+	var statusMap map[string]string
+	var contextMap map[string]string
+	if len(opts) == 1 {
+		contextMap = opts[0]
+	} else if len(opts) == 2 {
+		contextMap = opts[0]
+		statusMap = opts[1]
+	}
+
+	tarsResp := new(requestf.ResponsePacket)
+	err = obj.servant.TarsInvoke(tarsCtx, 0, "tars_reflection", buf.ToBytes(), statusMap, contextMap, tarsResp)
+	if err != nil {
+		return ret, err
+	}
+
+	readBuf := codec.NewReader(tools.Int8ToByte(tarsResp.SBuffer))
+	err = readBuf.ReadInt32(&ret, 0, true)
+	if err != nil {
+		return ret, err
+	}
+
+	err = (*info).ReadBlock(readBuf, 2, true)
+	if err != nil {
+		return ret, err
+	}
+
+	_, ty, err = readBuf.SkipToNoCheck(3, true)
+	if err != nil {
+		return ret, err
+	}
+
+	if ty == codec.LIST {
+		err = readBuf.ReadInt32(&length, 0, true)
+		if err != nil {
+			return ret, err
+		}
+
+		*servants = make([]string, length)
+		for i0, e0 := int32(0), length; i0 < e0; i0++ {
+
+			err = readBuf.ReadString(&(*servants)[i0], 0, false)
+			if err != nil {
+				return ret, err
+			}
+
+		}
+	} else if ty == codec.SimpleList {
+		err = fmt.Errorf("not support SimpleList type")
+		if err != nil {
+			return ret, err
+		}
+
+	} else {
+		err = fmt.Errorf("require vector, but not")
+		if err != nil {
+			return ret, err
+		}
+
+	}
+
+	if len(opts) == 1 {
+		for k := range contextMap {
+			delete(contextMap, k)
+		}
+		for k, v := range tarsResp.Context {
+			contextMap[k] = v
+		}
+	} else if len(opts) == 2 {
+		for k := range contextMap {
+			delete(contextMap, k)
+		}
+		for k, v := range tarsResp.Context {
+			contextMap[k] = v
+		}
+		for k := range statusMap {
+			delete(statusMap, k)
+		}
+		for k, v := range tarsResp.Status {
+			statusMap[k] = v
+		}
+	}
+	_ = length
+	_ = have
+	_ = ty
+	return ret, nil
+}
+
+// Tars_reflectionOneWayWithContext is the proxy function for the method defined in the tars file, with the context
+func (obj *ReflectionF) Tars_reflectionOneWayWithContext(tarsCtx context.Context, servant string, info *ServantInfo, servants *[]string, opts ...map[string]string) (ret int32, err error) {
+	var (
+		length int32
+		have   bool
+		ty     byte
+	)
+	buf := codec.NewBuffer()
+	err = buf.WriteString(servant, 1)
+	if err != nil {
+		return ret, err
+	}
+
+	err = (*info).WriteBlock(buf, 2)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteHead(codec.LIST, 3)
+	if err != nil {
+		return ret, err
+	}
+
+	err = buf.WriteInt32(int32(len(*servants)), 0)
+	if err != nil {
+		return ret, err
+	}
+
+	for _, v := range *servants {
+
+		err = buf.WriteString(v, 0)
+		if err != nil {
+			return ret, err
+		}
+
+	}
+
+	var statusMap map[string]string
+	var contextMap map[string]string
+	if len(opts) == 1 {
+		contextMap = opts[0]
+	} else if len(opts) == 2 {
+		contextMap = opts[0]
+		statusMap = opts[1]
+	}
+
+	tarsResp := new(requestf.ResponsePacket)
+	err = obj.servant.TarsInvoke(tarsCtx, 1, "tars_reflection", buf.ToBytes(), statusMap, contextMap, tarsResp)
+	if err != nil {
+		return ret, err
+	}
+
+	_ = length
+	_ = have
+	_ = ty
+	return ret, nil
+}
+
+type ReflectionFServant interface {
+	Tars_reflection(servant string, info *ServantInfo, servants *[]string) (ret int32, err error)
+}
+type ReflectionFServantWithContext interface {
+	Tars_reflection(tarsCtx context.Context, servant string, info *ServantInfo, servants *[]string) (ret int32, err error)
+}
+
+// Dispatch is used to call the server side implement for the method defined in the tars file. withContext shows using context or not.
+func (obj *ReflectionF) Dispatch(tarsCtx context.Context, val interface{}, tarsReq *requestf.RequestPacket, tarsResp *requestf.ResponsePacket, withContext bool) (err error) {
+	var (
+		length int32
+		have   bool
+		ty     byte
+	)
+	readBuf := codec.NewReader(tools.Int8ToByte(tarsReq.SBuffer))
+	buf := codec.NewBuffer()
+	switch tarsReq.SFuncName {
+	case "tars_reflection":
+		var servant string
+		var info ServantInfo
+		var servants []string
+		servants = make([]string, 0)
+
+		if tarsReq.IVersion == basef.TARSVERSION {
+
+			err = readBuf.ReadString(&servant, 1, true)
+			if err != nil {
+				return err
+			}
+
+		} else if tarsReq.IVersion == basef.TUPVERSION {
+			reqTup := tup.NewUniAttribute()
+			reqTup.Decode(readBuf)
+
+			var tupBuffer []byte
+
+			reqTup.GetBuffer("servant", &tupBuffer)
+			readBuf.Reset(tupBuffer)
+			err = readBuf.ReadString(&servant, 0, true)
+			if err != nil {
+				return err
+			}
+
+		} else if tarsReq.IVersion == basef.JSONVERSION {
+			var jsonData map[string]interface{}
+			decoder := json.NewDecoder(bytes.NewReader(readBuf.ToBytes()))
+			decoder.UseNumber()
+			err = decoder.Decode(&jsonData)
+			if err != nil {
+				return fmt.Errorf("decode reqpacket failed, error: %+v", err)
+			}
+			{
+				jsonStr, _ := json.Marshal(jsonData["servant"])
+				if err = json.Unmarshal(jsonStr, &servant); err != nil {
+					return err
+				}
+			}
+
+		} else {
+			err = fmt.Errorf("decode reqpacket fail, error version: %d", tarsReq.IVersion)
+			return err
+		}
+
+		var funRet int32
+		if !withContext {
+			imp := val.(ReflectionFServant)
+			funRet, err = imp.Tars_reflection(servant, &info, &servants)
+		} else {
+			imp := val.(ReflectionFServantWithContext)
+			funRet, err = imp.Tars_reflection(tarsCtx, servant, &info, &servants)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if tarsReq.IVersion == basef.TARSVERSION {
+			buf.Reset()
+
+			err = buf.WriteInt32(funRet, 0)
+			if err != nil {
+				return err
+			}
+
+			err = info.WriteBlock(buf, 2)
+			if err != nil {
+				return err
+			}
+
+			err = buf.WriteHead(codec.LIST, 3)
+			if err != nil {
+				return err
+			}
+
+			err = buf.WriteInt32(int32(len(servants)), 0)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range servants {
+
+				err = buf.WriteString(v, 0)
+				if err != nil {
+					return err
+				}
+
+			}
+
+		} else if tarsReq.IVersion == basef.TUPVERSION {
+			rspTup := tup.NewUniAttribute()
+
+			err = buf.WriteInt32(funRet, 0)
+			if err != nil {
+				return err
+			}
+
+			rspTup.PutBuffer("", buf.ToBytes())
+			rspTup.PutBuffer("tars_ret", buf.ToBytes())
+
+			buf.Reset()
+			err = info.WriteBlock(buf, 0)
+			if err != nil {
+				return err
+			}
+
+			rspTup.PutBuffer("info", buf.ToBytes())
+
+			buf.Reset()
+			err = buf.WriteHead(codec.LIST, 0)
+			if err != nil {
+				return err
+			}
+
+			err = buf.WriteInt32(int32(len(servants)), 0)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range servants {
+
+				err = buf.WriteString(v, 0)
+				if err != nil {
+					return err
+				}
+
+			}
+			rspTup.PutBuffer("servants", buf.ToBytes())
+
+			buf.Reset()
+			err = rspTup.Encode(buf)
+			if err != nil {
+				return err
+			}
+		} else if tarsReq.IVersion == basef.JSONVERSION {
+			rspJson := map[string]interface{}{}
+			rspJson["tars_ret"] = funRet
+			rspJson["info"] = info
+			rspJson["servants"] = servants
+
+			var rspByte []byte
+			if rspByte, err = json.Marshal(rspJson); err != nil {
+				return err
+			}
+
+			buf.Reset()
+			err = buf.WriteSliceUint8(rspByte)
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("func mismatch")
+	}
+	var statusMap map[string]string
+	if status, ok := current.GetResponseStatus(tarsCtx); ok && status != nil {
+		statusMap = status
+	}
+	var contextMap map[string]string
+	if ctx, ok := current.GetResponseContext(tarsCtx); ok && ctx != nil {
+		contextMap = ctx
+	}
+	*tarsResp = requestf.ResponsePacket{
+		IVersion:     tarsReq.IVersion,
+		CPacketType:  0,
+		IRequestId:   tarsReq.IRequestId,
+		IMessageType: 0,
+		IRet:         0,
+		SBuffer:      tools.ByteToInt8(buf.ToBytes()),
+		Status:       statusMap,
+		SResultDesc:  "",
+		Context:      contextMap,
+	}
+
+	_ = readBuf
+	_ = buf
+	_ = length
+	_ = have
+	_ = ty
+	return nil
+}
@@ -1,8 +1,15 @@
 package tars
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/TarsCloud/TarsGo/tars/protocol/res/configf"
 )
@@ -14,6 +21,12 @@ type RConf struct {
 	comm   *Communicator
 	tc     *configf.Config
 	path   string
+	// onLoad is called after the config is downloaded or loaded from the local cache
+	onLoad func(info configf.ConfigInfo, content string)
+
+	retryInterval time.Duration
+	retryMu       sync.Mutex
+	retrying      map[string]bool
 }
 
 // GetRConf returns a default RConf
@@ -36,37 +49,42 @@ func AddConfig(filename string) (config string, err error) {
 	return defaultApp.AddConfig(filename)
 }
 
-func (a *application) GetRemoteConf() *RConf {
+func (a *Application) GetRemoteConf() *RConf {
 	a.onceRConf.Do(func() {
 		cfg := a.ServerConfig()
-		a.defaultRConf = NewRConf(cfg.App, cfg.Server, cfg.BasePath)
+		a.defaultRConf = a.newRConf(cfg.App, cfg.Server, cfg.BasePath)
 	})
 	return a.defaultRConf
 }
 
 // GetConfigList get server level config list
-func (a *application) GetConfigList() (fList []string, err error) {
+func (a *Application) GetConfigList() (fList []string, err error) {
 	return a.GetRemoteConf().GetConfigList()
 }
 
 // AddAppConfig add app level config
-func (a *application) AddAppConfig(filename string) (config string, err error) {
-	return a.GetRemoteConf().GetAppConfig(filename)
+func (a *Application) AddAppConfig(filename string) (config string, err error) {
+	return a.loadConfig(configKey{appLevel: true, filename: filename})
 }
 
 // AddConfig add server level config
-func (a *application) AddConfig(filename string) (config string, err error) {
-	return a.GetRemoteConf().GetConfig(filename)
+func (a *Application) AddConfig(filename string) (config string, err error) {
+	return a.loadConfig(configKey{filename: filename})
 }
 
 // NewRConf init a RConf, path should be getting from GetServerConfig().BasePath
 func NewRConf(app string, server string, path string) *RConf {
-	comm := GetCommunicator()
-	obj := comm.app.ServerConfig().Config
+	return defaultApp.newRConf(app, server, path)
+}
+
+func (a *Application) newRConf(app string, server string, path string) *RConf {
+	comm := a.Communicator()
+	obj := a.ServerConfig().Config
 
 	tc := new(configf.Config)
 	comm.StringToProxy(obj, tc)
-	return &RConf{app, server, comm, tc, path}
+	return &RConf{app: app, server: server, comm: comm, tc: tc, path: path, onLoad: a.configLoaded,
+		retryInterval: configRetryInterval, retrying: make(map[string]bool)}
 }
 
 // GetConfigList is discarded.
@@ -110,16 +128,52 @@ func (c *RConf) GetConfig(filename string) (config string, err error) {
 	return c.getConfig(info)
 }
 
-// GetConfig gets the remote config and save it to the path, also return the content.
+// getConfig gets the remote config and save it to the path, also return the content.
+// The last fetched copy in the path is returned if the config servant fails to be invoked,
+// and the remote config is fetched in the background until the config servant answers.
+// The cache is not used if the config servant answers with an error, such as the config is removed.
 func (c *RConf) getConfig(info configf.ConfigInfo) (config string, err error) {
 	var set string
 	if v, ok := c.comm.GetProperty("setdivision"); ok {
 		set = v
 	}
 	info.Setdivision = set
+	config, err = c.fetchConfig(info)
+	var invokeErr *configInvokeError
+	if err == nil || !errors.As(err, &invokeErr) {
+		return config, err
+	}
+	cached, cache, cacheErr := c.loadCache(info)
+	if cacheErr != nil {
+		TLOG.Debugf("load config %s from local cache error: %v", info.Filename, cacheErr)
+		return config, err
+	}
+	msg := fmt.Sprintf("load config %s from local cache of %s: %v", info.Filename, cache.ModifyTime, err)
+	TLOG.Warn(msg)
+	if c.comm.app != nil {
+		go c.comm.app.ReportNotifyInfo(NotifyWarn, msg)
+	}
+	if c.onLoad != nil {
+		c.onLoad(info, cached)
+	}
+	c.retryConfig(info)
+	return cached, nil
+}
+
+// configInvokeError is the error of invoking the config servant, such as the config servant is unreachable.
+type configInvokeError struct {
+	err error
+}
+
+func (e *configInvokeError) Error() string {
+	return e.err.Error()
+}
+
+// fetchConfig fetches the config from the config servant and saves it with the metadata to the path.
+func (c *RConf) fetchConfig(info configf.ConfigInfo) (config string, err error) {
 	ret, err := c.tc.LoadConfigByInfo(&info, &config)
 	if err != nil {
-		return config, err
+		return config, &configInvokeError{err}
 	}
 	if ret != 0 {
 		return config, fmt.Errorf("ret %d", ret)
@@ -128,9 +182,88 @@ func (c *RConf) getConfig(info configf.ConfigInfo) (config string, err error) {
 	if err != nil {
 		return config, err
 	}
+	if err = c.saveCache(info, config); err != nil {
+		TLOG.Errorf("save cache of config %s error: %v", info.Filename, err)
+	}
+	if c.onLoad != nil {
+		c.onLoad(info, config)
+	}
 	return config, nil
 }
 
+func (c *RConf) saveCache(info configf.ConfigInfo, content string) error {
+	sum := md5.Sum([]byte(content))
+	cache := ConfigCache{
+		TarsVersion: Version,
+		ModifyTime:  time.Now().Format("2006-01-02 15:04:05"),
+		App:         info.Appname,
+		Server:      info.Servername,
+		Filename:    info.Filename,
+		MD5:         hex.EncodeToString(sum[:]),
+	}
+	data, _ := json.MarshalIndent(&cache, "", "    ")
+	return saveFile(c.path, info.Filename+".tarsdat", string(data))
+}
+
+// loadCache loads the config saved by fetchConfig, which is checked by the metadata.
+func (c *RConf) loadCache(info configf.ConfigInfo) (string, ConfigCache, error) {
+	var cache ConfigCache
+	data, err := os.ReadFile(fmt.Sprintf("%s/%s.tarsdat", c.path, info.Filename))
+	if err != nil {
+		return "", cache, err
+	}
+	if err = json.Unmarshal(data, &cache); err != nil {
+		return "", cache, err
+	}
+	if cache.App != info.Appname || cache.Server != info.Servername || cache.Filename != info.Filename {
+		return "", cache, fmt.Errorf("cache of %s.%s/%s mismatch", cache.App, cache.Server, cache.Filename)
+	}
+	content, err := os.ReadFile(fmt.Sprintf("%s/%s", c.path, info.Filename))
+	if err != nil {
+		return "", cache, err
+	}
+	if sum := md5.Sum(content); hex.EncodeToString(sum[:]) != cache.MD5 {
+		return "", cache, fmt.Errorf("md5 of %s mismatch", info.Filename)
+	}
+	return string(content), cache, nil
+}
+
+// retryConfig fetches the config in the background until the config servant answers or the application shuts down.
+func (c *RConf) retryConfig(info configf.ConfigInfo) {
+	c.retryMu.Lock()
+	defer c.retryMu.Unlock()
+	key := info.Servername + "/" + info.Filename
+	if c.retrying[key] {
+		return
+	}
+	c.retrying[key] = true
+	go func() {
+		defer func() {
+			c.retryMu.Lock()
+			delete(c.retrying, key)
+			c.retryMu.Unlock()
+		}()
+		for {
+			time.Sleep(c.retryInterval)
+			if c.comm.app != nil && atomic.LoadInt32(&c.comm.app.isShutdowning) == 1 {
+				return
+			}
+			_, err := c.fetchConfig(info)
+			var invokeErr *configInvokeError
+			if errors.As(err, &invokeErr) {
+				TLOG.Debugf("retry config %s error: %v", info.Filename, err)
+				continue
+			}
+			if err != nil {
+				TLOG.Errorf("stop retrying config %s: %v", info.Filename, err)
+				return
+			}
+			TLOG.Infof("config %s is fetched from the config servant", info.Filename)
+			return
+		}
+	}()
+}
+
 func saveFile(path string, filename string, content string) error {
 	err := os.WriteFile(fmt.Sprintf("%s/%s", path, filename), []byte(content), 0644)
 	if err != nil {
@@ -0,0 +1,87 @@
+package tars
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/configf"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfigCache test loading the last fetched config when the config servant fails.
+func TestConfigCache(t *testing.T) {
+	cfgSvr := &fakeConfig{files: map[string]string{"TestApp.CacheServer/test.conf": "a=1"}}
+	server := NewApplication(
+		WithServer("tars", "tarsconfig"),
+		WithAdapter("tars.tarsconfig.ConfigObj", "tcp -h 127.0.0.1 -p 17988 -t 60000"),
+		WithLogPath(t.TempDir()),
+	)
+	server.AddServant(new(configf.Config), cfgSvr, "tars.tarsconfig.ConfigObj")
+	go server.Run()
+	defer server.Shutdown()
+
+	basePath := t.TempDir()
+	app := NewApplication(WithServer("TestApp", "CacheServer"), configOption(func(a *Application) {
+		a.svrCfg.Config = "tars.tarsconfig.ConfigObj@tcp -h 127.0.0.1 -p 17988 -t 60000"
+		a.svrCfg.BasePath = basePath
+	}))
+	rconf := app.GetRemoteConf()
+	rconf.retryInterval = 50 * time.Millisecond
+	var err error
+	for retry := 0; retry < 50; retry++ {
+		if _, err = app.AddConfig("test.conf"); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(basePath, "test.conf.tarsdat"))
+	assert.NoError(t, err)
+
+	// no cache of the other file
+	_, err = app.AddConfig("other.conf")
+	assert.Error(t, err)
+
+	// the cache is used until the config is fetched again
+	cfgSvr.setErr(errors.New("config servant is down"))
+	changes := make(chan string, 10)
+	assert.NoError(t, app.WatchConfig("test.conf", func(content string) { changes <- content }))
+	assert.Equal(t, "a=1", <-changes)
+	cfgSvr.set("TestApp.CacheServer/test.conf", "a=2")
+	cfgSvr.setErr(nil)
+	select {
+	case content := <-changes:
+		assert.Equal(t, "a=2", content)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the config is not fetched in the background")
+	}
+
+	// the config removed from the config servant is not loaded from the cache
+	cfgSvr.remove("TestApp.CacheServer/test.conf")
+	_, err = app.AddConfig("test.conf")
+	assert.EqualError(t, err, "ret -1")
+
+	// retrying stops once the config servant answers
+	cfgSvr.set("TestApp.CacheServer/test.conf", "a=3")
+	_, err = app.AddConfig("test.conf")
+	assert.NoError(t, err)
+	cfgSvr.setErr(errors.New("config servant is down"))
+	_, err = app.AddConfig("test.conf")
+	assert.NoError(t, err)
+	cfgSvr.remove("TestApp.CacheServer/test.conf")
+	cfgSvr.setErr(nil)
+	assert.Eventually(t, func() bool {
+		rconf.retryMu.Lock()
+		defer rconf.retryMu.Unlock()
+		return len(rconf.retrying) == 0
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// the modified copy is not used
+	cfgSvr.setErr(errors.New("config servant is down"))
+	assert.NoError(t, os.WriteFile(filepath.Join(basePath, "test.conf"), []byte("a=4"), 0644))
+	_, err = app.AddConfig("test.conf")
+	assert.Error(t, err)
+}
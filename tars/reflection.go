@@ -0,0 +1,48 @@
+package tars
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/reflectionf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+)
+
+// reflectionFunc is the function of the built-in reflection servant, which is served by every tars servant
+// like tars_ping when the reflection is enabled.
+const reflectionFunc = "tars_reflection"
+
+// descriptor is implemented by the code generated by tars2go, which embeds the interface description.
+type descriptor interface {
+	TarsDescriptor() string
+}
+
+// reflection implements reflectionf.ReflectionFServant for the servant obj.
+type reflection struct {
+	app *Application
+	obj string
+}
+
+// Tars_reflection returns the interface description of the servant, and all the servants which have one.
+func (r *reflection) Tars_reflection(servant string, info *reflectionf.ServantInfo, servants *[]string) (int32, error) {
+	if servant == "" {
+		servant = r.obj
+	}
+	for obj := range r.app.descriptors {
+		*servants = append(*servants, obj)
+	}
+	sort.Strings(*servants)
+	desc, ok := r.app.descriptors[servant]
+	if !ok {
+		return -1, fmt.Errorf("no descriptor for servant %s", servant)
+	}
+	info.Servant = servant
+	info.Descriptor = desc
+	return 0, nil
+}
+
+// dispatchReflection handles the request to the reflection servant.
+func (a *Application) dispatchReflection(ctx context.Context, obj string, req *requestf.RequestPacket, rsp *requestf.ResponsePacket) error {
+	return new(reflectionf.ReflectionF).Dispatch(ctx, &reflection{app: a, obj: obj}, req, rsp, false)
+}
@@ -0,0 +1,29 @@
+package tars
+
+import (
+	"testing"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/reflectionf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReflection(t *testing.T) {
+	app := &Application{descriptors: map[string]string{
+		"App.Server.BObj": `{"module":"B"}`,
+		"App.Server.AObj": `{"module":"A"}`,
+	}}
+	r := &reflection{app: app, obj: "App.Server.BObj"}
+
+	var info reflectionf.ServantInfo
+	var servants []string
+	ret, err := r.Tars_reflection("", &info, &servants)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), ret)
+	assert.Equal(t, "App.Server.BObj", info.Servant)
+	assert.Equal(t, `{"module":"B"}`, info.Descriptor)
+	assert.Equal(t, []string{"App.Server.AObj", "App.Server.BObj"}, servants)
+
+	servants = nil
+	_, err = r.Tars_reflection("App.Server.CObj", &info, &servants)
+	assert.Error(t, err)
+}
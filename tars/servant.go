@@ -263,11 +263,22 @@ func (s *ServantProxy) doInvoke(ctx context.Context, msg *Message, timeout time.
 		if needCheck {
 			go func() {
 				adp.reset()
-				ep := endpoint.Tars2endpoint(*msg.Adp.point)
+				ep := endpoint.Tars2endpoint(*adp.point)
 				s.manager.addAliveEp(ep)
 			}()
 		}
 		adp.successAdd()
+		if msg.Resp != nil && msg.Resp.IRet == basef.TARSSERVEROVERLOAD && msg.Resp.SResultDesc == shutdownMsg && !msg.rejected {
+			// the request is not invoked by the server, retry it once within the remaining timeout,
+			// which may be sent to the same server if it restarts gracefully
+			remain := timeout - time.Duration(time.Now().UnixNano()/1e6-msg.BeginTime)*time.Millisecond
+			if remain > 0 {
+				TLOG.Infof("retry the request rejected by the shutdown of %s:%d, reqid:%d", adp.point.Host, adp.point.Port, msg.Req.IRequestId)
+				msg.rejected = true
+				msg.Resp = nil
+				return s.doInvoke(ctx, msg, remain)
+			}
+		}
 		if msg.Resp != nil {
 			if msg.Status != basef.TARSSERVERSUCCESS || msg.Resp.IRet != 0 {
 				if msg.Resp.SResultDesc == "" {
@@ -36,21 +36,21 @@ func AddServantWithProtocol(proto transport.ServerProtocol, obj string) {
 }
 
 // AddServant add dispatch and interface for object.
-func (a *application) AddServant(v dispatch, f interface{}, obj string) {
+func (a *Application) AddServant(v dispatch, f interface{}, obj string) {
 	a.addServantCommon(v, f, obj, false)
 }
 
 // AddServantWithContext add dispatch and interface for object, which have ctx,context
-func (a *application) AddServantWithContext(v dispatch, f interface{}, obj string) {
+func (a *Application) AddServantWithContext(v dispatch, f interface{}, obj string) {
 	a.addServantCommon(v, f, obj, true)
 }
 
-func (a *application) addServantCommon(v dispatch, f interface{}, obj string, withContext bool) {
-	a.objRunList = append(a.objRunList, obj)
+func (a *Application) addServantCommon(v dispatch, f interface{}, obj string, withContext bool) {
+	a.init()
 	cfg, ok := a.tarsConfig[obj]
 	if !ok {
 		msg := fmt.Sprintf("tars servant obj name not found: %s", obj)
-		ReportNotifyInfo(NotifyError, msg)
+		a.ReportNotifyInfo(NotifyError, msg)
 		TLOG.Debug(msg)
 		panic(errors.New(msg))
 	}
@@ -58,30 +58,38 @@ func (a *application) addServantCommon(v dispatch, f interface{}, obj string, wi
 		TLOG.Debugf("add destroyable obj %s", obj)
 		a.destroyableObjs = append(a.destroyableObjs, v)
 	}
+	if v, ok := f.(initializableImp); ok {
+		TLOG.Debugf("add initializable obj %s", obj)
+		a.addInitializable(obj, v)
+	}
+	if d, ok := v.(descriptor); ok {
+		a.descriptors[obj] = d.TarsDescriptor()
+	}
 	TLOG.Debug("add tars protocol server: %+v", cfg)
 
 	jp := NewTarsProtocol(v, f, withContext)
 	jp.app = a
+	jp.obj = obj
 	s := transport.NewTarsServer(jp, cfg)
-	a.goSvrs[obj] = s
+	a.addTarsServer(obj, s)
 }
 
 // AddHttpServant add http servant handler with default exceptionStatusChecker for obj.
-func (a *application) AddHttpServant(mux HttpHandler, obj string) {
+func (a *Application) AddHttpServant(mux HttpHandler, obj string) {
 	a.AddHttpServantWithExceptionStatusChecker(mux, obj, DefaultExceptionStatusChecker)
 }
 
 // AddHttpServantWithExceptionStatusChecker add http servant handler with exceptionStatusChecker for obj.
-func (a *application) AddHttpServantWithExceptionStatusChecker(mux HttpHandler, obj string, exceptionStatusChecker func(int) bool) {
+func (a *Application) AddHttpServantWithExceptionStatusChecker(mux HttpHandler, obj string, exceptionStatusChecker func(int) bool) {
+	a.init()
 	cfg, ok := a.tarsConfig[obj]
 	if !ok {
 		msg := fmt.Sprintf("http servant obj name not found: %s", obj)
-		ReportNotifyInfo(NotifyError, msg)
+		a.ReportNotifyInfo(NotifyError, msg)
 		TLOG.Debug(msg)
 		panic(errors.New(msg))
 	}
 	TLOG.Debugf("add http protocol server: %+v", cfg)
-	a.objRunList = append(a.objRunList, obj)
 	addrInfo := strings.SplitN(cfg.Address, ":", 2)
 	var port int64
 	if len(addrInfo) == 2 {
@@ -100,23 +108,40 @@ func (a *application) AddHttpServantWithExceptionStatusChecker(mux HttpHandler,
 		Port:                   int32(port),
 		SetId:                  svrCfg.Setdivision,
 		ExceptionStatusChecker: exceptionStatusChecker,
+		app:                    a,
 	}
 	mux.SetConfig(httpConf)
 	s := &http.Server{Addr: cfg.Address, Handler: mux, TLSConfig: cfg.TlsConfig}
 	a.httpSvrs[obj] = s
+	if ts, ok := a.goSvrs[obj]; ok {
+		// tars and http servants of the same obj share the port
+		ts.SetHttpServer(s)
+		return
+	}
+	a.objRunList = append(a.objRunList, obj)
 }
 
 // AddServantWithProtocol adds a servant with protocol and obj
-func (a *application) AddServantWithProtocol(proto transport.ServerProtocol, obj string) {
-	a.objRunList = append(a.objRunList, obj)
+func (a *Application) AddServantWithProtocol(proto transport.ServerProtocol, obj string) {
+	a.init()
 	cfg, ok := a.tarsConfig[obj]
 	if !ok {
 		msg := fmt.Sprintf("custom protocol servant obj name not found: %s", obj)
-		ReportNotifyInfo(NotifyError, msg)
+		a.ReportNotifyInfo(NotifyError, msg)
 		TLOG.Debug(msg)
 		panic(errors.New(msg))
 	}
 	TLOG.Debugf("add custom protocol server: %+v", cfg)
 	s := transport.NewTarsServer(proto, cfg)
+	a.addTarsServer(obj, s)
+}
+
+// addTarsServer adds the server of obj, which also serves the http servant of obj if there is one.
+func (a *Application) addTarsServer(obj string, s *transport.TarsServer) {
 	a.goSvrs[obj] = s
+	if hs, ok := a.httpSvrs[obj]; ok {
+		s.SetHttpServer(hs)
+		return
+	}
+	a.objRunList = append(a.objRunList, obj)
 }
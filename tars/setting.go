@@ -52,6 +52,8 @@ const (
 	ClientDialTimeout = 3000
 	// ObjQueueMax obj queue max number
 	ObjQueueMax int32 = 100000
+	// MetricsPath is the http path of the metrics exporter
+	MetricsPath = "/metrics"
 
 	// log
 	defaultRotateN      = 10
@@ -75,6 +77,11 @@ const (
 	// StatReportChannelBufLen stat report channel len
 	StatReportChannelBufLen = 100000
 
+	// ConfigPollInterval is the interval of polling the watched remote config files,default value is 60000 milliseconds
+	ConfigPollInterval = 60000
+	// configRetryInterval is the interval of fetching the remote config file loaded from the local cache
+	configRetryInterval = 5 * time.Second
+
 	// mainloop
 
 	// MainLoopTicker main loop ticker,default value is 10000 milliseconds
@@ -117,6 +124,10 @@ const (
 
 	// GracedownTimeout set timeout (milliseconds) for grace shutdown
 	GracedownTimeout = 60000
+	// HookTimeout set timeout (milliseconds) for each lifecycle hook and servant Initialize
+	HookTimeout = 30000
+	// GraceRestartTimeout set timeout (milliseconds) for the new process of grace restart to be ready
+	GraceRestartTimeout = 60000
 
 	// MaxPackageLength maximum length of the request
 	MaxPackageLength = 10485760
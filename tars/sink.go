@@ -0,0 +1,230 @@
+package tars
+
+import (
+	"sync"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/propertyf"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/statf"
+)
+
+// StatSink receives the rpc stats aggregated by StatFHelper in each stat report interval.
+type StatSink interface {
+	ReportStat(stats map[statf.StatMicMsgHead]statf.StatMicMsgBody, fromClient bool) error
+}
+
+// PropertySink receives the property reports aggregated by PropertyReportHelper in each property report interval.
+type PropertySink interface {
+	ReportProperty(props map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody) error
+}
+
+// appSinks keeps the sinks of an application.
+type appSinks struct {
+	sync.RWMutex
+	stat     []StatSink
+	property []PropertySink
+}
+
+// AddStatSink adds a sink for the rpc stats, the tars stat servant is added by default when it is configured.
+func AddStatSink(sink StatSink) {
+	defaultApp.AddStatSink(sink)
+}
+
+// AddPropertySink adds a sink for the property reports, the tars property servant is added by default when it is configured.
+func AddPropertySink(sink PropertySink) {
+	defaultApp.AddPropertySink(sink)
+}
+
+// AddStatSink adds a sink for the rpc stats, the tars stat servant is added by default when it is configured.
+func (a *Application) AddStatSink(sink StatSink) {
+	a.sinks.Lock()
+	defer a.sinks.Unlock()
+	a.sinks.stat = append(a.sinks.stat, sink)
+}
+
+// AddPropertySink adds a sink for the property reports, the tars property servant is added by default when it is configured.
+func (a *Application) AddPropertySink(sink PropertySink) {
+	a.sinks.Lock()
+	defer a.sinks.Unlock()
+	a.sinks.property = append(a.sinks.property, sink)
+}
+
+func (a *Application) statSinks() []StatSink {
+	a.sinks.RLock()
+	defer a.sinks.RUnlock()
+	return a.sinks.stat
+}
+
+func (a *Application) propertySinks() []PropertySink {
+	a.sinks.RLock()
+	defer a.sinks.RUnlock()
+	return a.sinks.property
+}
+
+// TarsStatSink reports the stats to the tars stat servant.
+type TarsStatSink struct {
+	sf *statf.StatF
+}
+
+// NewTarsStatSink creates the sink reporting to the stat servant.
+func NewTarsStatSink(comm *Communicator, servant string) *TarsStatSink {
+	s := &TarsStatSink{sf: new(statf.StatF)}
+	comm.StringToProxy(servant, s.sf)
+	return s
+}
+
+// ReportStat reports the stats to the stat servant.
+func (s *TarsStatSink) ReportStat(stats map[statf.StatMicMsgHead]statf.StatMicMsgBody, fromClient bool) error {
+	_, err := s.sf.ReportMicMsg(stats, fromClient)
+	return err
+}
+
+// TarsPropertySink reports the properties to the tars property servant.
+type TarsPropertySink struct {
+	pf *propertyf.PropertyF
+}
+
+// NewTarsPropertySink creates the sink reporting to the property servant.
+func NewTarsPropertySink(comm *Communicator, servant string) *TarsPropertySink {
+	s := &TarsPropertySink{pf: new(propertyf.PropertyF)}
+	comm.StringToProxy(servant, s.pf)
+	return s
+}
+
+// ReportProperty reports the properties to the property servant, 20 properties at most in a request.
+func (s *TarsPropertySink) ReportProperty(props map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody) error {
+	var cnt int
+	var err error
+	var tmpStatMsg = make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody)
+	for k, v := range props {
+		cnt++
+		if cnt >= 20 {
+			if _, e := s.pf.ReportPropMsg(tmpStatMsg); e != nil {
+				err = e
+			}
+			tmpStatMsg = make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody)
+		}
+		tmpStatMsg[k] = v
+	}
+	if len(tmpStatMsg) > 0 {
+		if _, e := s.pf.ReportPropMsg(tmpStatMsg); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// MemorySink keeps the stats and properties in memory, which is useful to assert the reports in tests.
+// The stats of the same head are merged, and the latest body of each property is kept.
+type MemorySink struct {
+	mu         sync.Mutex
+	clientStat map[statf.StatMicMsgHead]statf.StatMicMsgBody
+	serverStat map[statf.StatMicMsgHead]statf.StatMicMsgBody
+	props      map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody
+}
+
+// NewMemorySink creates an empty MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		clientStat: make(map[statf.StatMicMsgHead]statf.StatMicMsgBody),
+		serverStat: make(map[statf.StatMicMsgHead]statf.StatMicMsgBody),
+		props:      make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody),
+	}
+}
+
+// ReportStat merges the stats.
+func (s *MemorySink) ReportStat(stats map[statf.StatMicMsgHead]statf.StatMicMsgBody, fromClient bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.serverStat
+	if fromClient {
+		m = s.clientStat
+	}
+	for head, body := range stats {
+		old, ok := m[head]
+		if !ok {
+			// the interval counts are merged later, which must not change the map of the reporter
+			intervalCount := make(map[int32]int32, len(body.IntervalCount))
+			for point, n := range body.IntervalCount {
+				intervalCount[point] = n
+			}
+			body.IntervalCount = intervalCount
+			m[head] = body
+			continue
+		}
+		old.Count += body.Count
+		old.TimeoutCount += body.TimeoutCount
+		old.ExecCount += body.ExecCount
+		old.TotalRspTime += body.TotalRspTime
+		if old.MaxRspTime < body.MaxRspTime {
+			old.MaxRspTime = body.MaxRspTime
+		}
+		if old.MinRspTime > body.MinRspTime {
+			old.MinRspTime = body.MinRspTime
+		}
+		for point, n := range body.IntervalCount {
+			old.IntervalCount[point] += n
+		}
+		m[head] = old
+	}
+	return nil
+}
+
+// ReportProperty keeps the latest properties.
+func (s *MemorySink) ReportProperty(props map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for head, body := range props {
+		s.props[head] = body
+	}
+	return nil
+}
+
+// Stat returns the merged stat of the calls to the interface of slave, which is the app.server name.
+func (s *MemorySink) Stat(slave, interfaceName string, fromClient bool) (body statf.StatMicMsgBody, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.serverStat
+	if fromClient {
+		m = s.clientStat
+	}
+	for head, b := range m {
+		if head.SlaveName != slave || head.InterfaceName != interfaceName {
+			continue
+		}
+		if !ok {
+			body, ok = b, true
+			continue
+		}
+		body.Count += b.Count
+		body.TimeoutCount += b.TimeoutCount
+		body.ExecCount += b.ExecCount
+		body.TotalRspTime += b.TotalRspTime
+	}
+	return body, ok
+}
+
+// Property returns the latest values of the property by the policy names.
+func (s *MemorySink) Property(name string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for head, body := range s.props {
+		if head.PropertyName != name {
+			continue
+		}
+		values := make(map[string]string)
+		for _, info := range body.VInfo {
+			values[info.Policy] = info.Value
+		}
+		return values
+	}
+	return nil
+}
+
+// Reset clears the reports kept.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientStat = make(map[statf.StatMicMsgHead]statf.StatMicMsgBody)
+	s.serverStat = make(map[statf.StatMicMsgHead]statf.StatMicMsgBody)
+	s.props = make(map[propertyf.StatPropMsgHead]propertyf.StatPropMsgBody)
+}
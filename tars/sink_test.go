@@ -0,0 +1,49 @@
+package tars
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/statf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	AddStatSink(sink)
+	AddPropertySink(sink)
+
+	s := new(StatFHelper)
+	s.init(defaultApp)
+	// no Run to report
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, s.Flush(ctx))
+	go s.Run()
+	head := statf.StatMicMsgHead{MasterName: "App.Client", SlaveName: "App.Server", InterfaceName: "Add"}
+	s.ReportMicMsg(StatInfo{Head: head, Body: statf.StatMicMsgBody{Count: 1, TotalRspTime: 3}}, false)
+	s.ReportMicMsg(StatInfo{Head: head, Body: statf.StatMicMsgBody{ExecCount: 1, TotalRspTime: 5}}, false)
+	s.ReportMicMsg(StatInfo{Head: head, Body: statf.StatMicMsgBody{TimeoutCount: 1}}, true)
+	assert.NoError(t, s.Flush(context.Background()))
+
+	body, ok := sink.Stat("App.Server", "Add", false)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), body.TimeoutCount)
+	body, ok = sink.Stat("App.Server", "Add", true)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), body.Count)
+	assert.Equal(t, int32(1), body.ExecCount)
+	assert.Equal(t, int64(8), body.TotalRspTime)
+	_, ok = sink.Stat("App.Server", "Sub", false)
+	assert.False(t, ok)
+
+	ReportSum("test.sink", 2)
+	ReportSum("test.sink", 3)
+	ReportMax("test.sink", 3)
+	ProHelper.ReportToServer()
+	assert.Equal(t, map[string]string{"Sum": "5", "Max": "3"}, sink.Property("test.sink"))
+
+	sink.Reset()
+	assert.Nil(t, sink.Property("test.sink"))
+}
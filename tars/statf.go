@@ -1,12 +1,12 @@
 package tars
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/TarsCloud/TarsGo/tars/protocol/res/statf"
-	"github.com/TarsCloud/TarsGo/tars/util/sync"
 	"github.com/TarsCloud/TarsGo/tars/util/tools"
 )
 
@@ -29,28 +29,32 @@ type StatFHelper struct {
 	chStatInfo           chan StatInfo
 	mStatInfo            map[statf.StatMicMsgHead]statf.StatMicMsgBody
 	mStatCount           map[statf.StatMicMsgHead]int
-	app                  *application
+	app                  *Application
 	comm                 *Communicator
-	sf                   *statf.StatF
 	servant              string
 	chStatInfoFromServer chan StatInfo
 	mStatInfoFromServer  map[statf.StatMicMsgHead]statf.StatMicMsgBody
 	mStatCountFromServer map[statf.StatMicMsgHead]int
+	chFlush              chan chan struct{}
 }
 
-// Init the StatFHelper
+// Init the StatFHelper, and add the sink of the stat servant.
 func (s *StatFHelper) Init(comm *Communicator, servant string) {
+	s.init(comm.app)
 	s.servant = servant
-	s.app = comm.app
+	s.comm = comm
+	comm.app.AddStatSink(NewTarsStatSink(comm, servant))
+}
+
+func (s *StatFHelper) init(app *Application) {
+	s.app = app
 	s.chStatInfo = make(chan StatInfo, s.app.ServerConfig().StatReportChannelBufLen)
 	s.chStatInfoFromServer = make(chan StatInfo, s.app.ServerConfig().StatReportChannelBufLen)
 	s.mStatInfo = make(map[statf.StatMicMsgHead]statf.StatMicMsgBody)
 	s.mStatCount = make(map[statf.StatMicMsgHead]int)
 	s.mStatInfoFromServer = make(map[statf.StatMicMsgHead]statf.StatMicMsgBody)
 	s.mStatCountFromServer = make(map[statf.StatMicMsgHead]int)
-	s.comm = comm
-	s.sf = new(statf.StatF)
-	s.comm.StringToProxy(s.servant, s.sf)
+	s.chFlush = make(chan chan struct{})
 }
 
 func (s *StatFHelper) collectMsg(statInfo StatInfo, mStatInfo map[statf.StatMicMsgHead]statf.StatMicMsgBody, mStatCount map[statf.StatMicMsgHead]int) {
@@ -110,24 +114,26 @@ func (s *StatFHelper) getIntervCount(totalRspTime int32, intervalCount map[int32
 func (s *StatFHelper) reportAndClear(mStat string, bFromClient bool) {
 	// report mStatInfo
 	if mStat == "mStatInfo" {
-		_, err := s.sf.ReportMicMsg(s.mStatInfo, bFromClient)
-		if err != nil {
-			TLOG.Debug("mStatInfo report err:", err.Error())
-		}
+		s.report(mStat, s.mStatInfo, bFromClient)
 		s.mStatInfo = make(map[statf.StatMicMsgHead]statf.StatMicMsgBody)
 		s.mStatCount = make(map[statf.StatMicMsgHead]int)
 	}
 	// report mStatInfoFromServer
 	if mStat == "mStatInfoFromServer" {
-		_, err := s.sf.ReportMicMsg(s.mStatInfoFromServer, bFromClient)
-		if err != nil {
-			TLOG.Debug("mStatInfoFromServer report err:", err.Error())
-		}
+		s.report(mStat, s.mStatInfoFromServer, bFromClient)
 		s.mStatInfoFromServer = make(map[statf.StatMicMsgHead]statf.StatMicMsgBody)
 		s.mStatCountFromServer = make(map[statf.StatMicMsgHead]int)
 	}
 }
 
+func (s *StatFHelper) report(mStat string, stats map[statf.StatMicMsgHead]statf.StatMicMsgBody, bFromClient bool) {
+	for _, sink := range s.app.statSinks() {
+		if err := sink.ReportStat(stats, bFromClient); err != nil {
+			TLOG.Debug(mStat, " report err:", err.Error())
+		}
+	}
+}
+
 // Run stat report loop
 func (s *StatFHelper) Run() {
 	ticker := time.NewTicker(s.app.ServerConfig().StatReportInterval)
@@ -138,16 +144,55 @@ func (s *StatFHelper) Run() {
 		case stStatInfoFromServer := <-s.chStatInfoFromServer:
 			s.collectMsg(stStatInfoFromServer, s.mStatInfoFromServer, s.mStatCountFromServer)
 		case <-ticker.C:
-			if len(s.mStatInfo) > 0 {
-				s.reportAndClear("mStatInfo", true)
-			}
-			if len(s.mStatInfoFromServer) > 0 {
-				s.reportAndClear("mStatInfoFromServer", false)
-			}
+			s.reportAll()
+		case done := <-s.chFlush:
+			s.collectAll()
+			s.reportAll()
+			close(done)
 		}
 	}
 }
 
+func (s *StatFHelper) reportAll() {
+	if len(s.mStatInfo) > 0 {
+		s.reportAndClear("mStatInfo", true)
+	}
+	if len(s.mStatInfoFromServer) > 0 {
+		s.reportAndClear("mStatInfoFromServer", false)
+	}
+}
+
+// collectAll collects the stats in the channels.
+func (s *StatFHelper) collectAll() {
+	for {
+		select {
+		case stStatInfo := <-s.chStatInfo:
+			s.collectMsg(stStatInfo, s.mStatInfo, s.mStatCount)
+		case stStatInfoFromServer := <-s.chStatInfoFromServer:
+			s.collectMsg(stStatInfoFromServer, s.mStatInfoFromServer, s.mStatCountFromServer)
+		default:
+			return
+		}
+	}
+}
+
+// Flush reports the stats collected to the sinks at once, without waiting for the report interval,
+// it returns the error of ctx if the report is not done before ctx is done, such as Run is not running.
+func (s *StatFHelper) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case s.chFlush <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (s *StatFHelper) pushBackMsg(stStatInfo StatInfo, fromServer bool) {
 	if fromServer {
 		s.chStatInfoFromServer <- stStatInfo
@@ -161,45 +206,60 @@ func (s *StatFHelper) ReportMicMsg(stStatInfo StatInfo, fromServer bool) {
 	s.pushBackMsg(stStatInfo, fromServer)
 }
 
-// StatReport instance pointer of StatFHelper
-var (
-	StatReport   *StatFHelper
-	statInited   = make(chan struct{}, 1)
-	statInitOnce sync.Once
-)
+// StatReport instance pointer of StatFHelper of the default application
+var StatReport *StatFHelper
 
-func initReport(app *application) error {
+func initReport(app *Application) error {
 	cfg := app.ClientConfig()
+	statReport := new(StatFHelper)
 	if cfg.Stat == "" || !strings.Contains(cfg.Stat, "@") {
-		statInited <- struct{}{}
-		return fmt.Errorf("stat init error")
+		// the stats are still aggregated for the sinks added by users
+		statReport.init(app)
+	} else {
+		statReport.Init(app.Communicator(), cfg.Stat)
+	}
+	app.statReport = statReport
+	if app == defaultApp {
+		StatReport = statReport
 	}
-	comm := app.Communicator()
-	StatReport = new(StatFHelper)
-	StatReport.Init(comm, cfg.Stat)
-	statInited <- struct{}{}
-	go StatReport.Run()
+	app.statInited <- struct{}{}
+	go statReport.Run()
 	return nil
 }
 
 // ReportStatBase is base method for report statistics.
 func ReportStatBase(head *statf.StatMicMsgHead, body *statf.StatMicMsgBody, FromServer bool) {
+	defaultApp.ReportStatBase(head, body, FromServer)
+}
+
+// ReportStatBase is base method for report statistics.
+func (a *Application) ReportStatBase(head *statf.StatMicMsgHead, body *statf.StatMicMsgBody, FromServer bool) {
 	statInfo := StatInfo{Head: *head, Body: *body}
 	statInfo.Head.TarsVersion = Version
 	// statInfo.Head.IStatVer = 2
-	if StatReport != nil {
-		StatReport.ReportMicMsg(statInfo, FromServer)
+	a.metrics.observeStat(&statInfo.Head, &statInfo.Body, FromServer)
+	if a.statReport != nil && len(a.statSinks()) > 0 {
+		a.statReport.ReportMicMsg(statInfo, FromServer)
 	}
 }
 
-// ReportStatFromClient report the statics from client.
+// ReportStatFromClient report the statics from client, to the application of the servant proxy of msg.
 func ReportStatFromClient(msg *Message, succ int32, timeout int32, exec int32) {
-	cCfg := GetClientConfig()
+	app := defaultApp
+	if msg.Ser != nil && msg.Ser.comm != nil {
+		app = msg.Ser.comm.app
+	}
+	app.ReportStatFromClient(msg, succ, timeout, exec)
+}
+
+// ReportStatFromClient report the statics from client.
+func (a *Application) ReportStatFromClient(msg *Message, succ int32, timeout int32, exec int32) {
+	cCfg := a.ClientConfig()
 	var head statf.StatMicMsgHead
 	var body statf.StatMicMsgBody
 	head.MasterName = cCfg.ModuleName
 	head.MasterIp = tools.GetLocalIP()
-	if sCfg := GetServerConfig(); sCfg != nil && sCfg.Enableset {
+	if sCfg := a.ServerConfig(); sCfg != nil && sCfg.Enableset {
 		head.MasterIp = sCfg.LocalIP
 		setList := strings.Split(sCfg.Setdivision, ".")
 		head.MasterName = fmt.Sprintf("%s.%s.%s%s%s@%s", sCfg.App, sCfg.Server, setList[0], setList[1], setList[2], sCfg.Version)
@@ -235,12 +295,17 @@ func ReportStatFromClient(msg *Message, succ int32, timeout int32, exec int32) {
 	body.TotalRspTime = msg.Cost()
 	body.MaxRspTime = int32(body.TotalRspTime)
 	body.MinRspTime = int32(body.TotalRspTime)
-	ReportStatBase(&head, &body, false)
+	a.ReportStatBase(&head, &body, false)
 }
 
 // ReportStatFromServer reports statics from server side.
 func ReportStatFromServer(InterfaceName, MasterName string, ReturnValue int32, TotalRspTime int64) {
-	cfg := GetServerConfig()
+	defaultApp.ReportStatFromServer(InterfaceName, MasterName, ReturnValue, TotalRspTime)
+}
+
+// ReportStatFromServer reports statics from server side.
+func (a *Application) ReportStatFromServer(InterfaceName, MasterName string, ReturnValue int32, TotalRspTime int64) {
+	cfg := a.ServerConfig()
 	var head statf.StatMicMsgHead
 	var body statf.StatMicMsgBody
 	head.SlaveName = fmt.Sprintf("%s.%s", cfg.App, cfg.Server)
@@ -264,5 +329,5 @@ func ReportStatFromServer(InterfaceName, MasterName string, ReturnValue int32, T
 	body.TotalRspTime = TotalRspTime
 	body.MaxRspTime = int32(body.TotalRspTime)
 	body.MinRspTime = int32(body.TotalRspTime)
-	ReportStatBase(&head, &body, true)
+	a.ReportStatBase(&head, &body, true)
 }
@@ -19,7 +19,8 @@ type dispatch interface {
 
 // Protocol is struct for dispatch with tars protocol.
 type Protocol struct {
-	app         *application
+	app         *Application
+	obj         string
 	dispatcher  dispatch
 	serverImp   interface{}
 	withContext bool
@@ -27,12 +28,14 @@ type Protocol struct {
 
 const (
 	reconnectMsg = "_reconnect_"
+	// shutdownMsg is the result of the requests rejected by the server which is shutting down
+	shutdownMsg = "_server_shutdown_"
 )
 
 // NewTarsProtocol return a TarsProtocol with dispatcher and implement interface.
 // withContext explain using context or not.
 func NewTarsProtocol(dispatcher dispatch, imp interface{}, withContext bool) *Protocol {
-	s := &Protocol{dispatcher: dispatcher, serverImp: imp, withContext: withContext}
+	s := &Protocol{app: defaultApp, dispatcher: dispatcher, serverImp: imp, withContext: withContext}
 	return s
 }
 
@@ -44,6 +47,11 @@ func (s *Protocol) Invoke(ctx context.Context, req []byte) (rsp []byte) {
 	is := codec.NewReader(req[4:])
 	reqPackage.ReadFrom(is)
 
+	// request-scoped fields of the structured log
+	current.SetServantNameWithContext(ctx, reqPackage.SServantName)
+	current.SetFuncNameWithContext(ctx, reqPackage.SFuncName)
+	current.SetRequestIDWithContext(ctx, reqPackage.IRequestId)
+
 	if reqPackage.HasMessageType(basef.TARSMESSAGETYPEDYED) {
 		if dyeingKey, ok := reqPackage.Status[current.StatusDyedKey]; ok {
 			if ok = current.SetDyeingKey(ctx, dyeingKey); !ok {
@@ -69,12 +77,12 @@ func (s *Protocol) Invoke(ctx context.Context, req []byte) (rsp []byte) {
 	if reqPackage.CPacketType == basef.TARSONEWAY {
 		defer func() {
 			endTime := time.Now().UnixNano() / 1e6
-			ReportStatFromServer(reqPackage.SFuncName, "one_way_client", rspPackage.IRet, endTime-recvPkgTs)
+			s.app.ReportStatFromServer(reqPackage.SFuncName, "one_way_client", rspPackage.IRet, endTime-recvPkgTs)
 		}()
 	} else if reqPackage.CPacketType == basef.TARSNORMAL {
 		defer func() {
 			endTime := time.Now().UnixNano() / 1e6
-			ReportStatFromServer(reqPackage.SFuncName, "stat_from_server", rspPackage.IRet, endTime-recvPkgTs)
+			s.app.ReportStatFromServer(reqPackage.SFuncName, "stat_from_server", rspPackage.IRet, endTime-recvPkgTs)
 		}()
 	}
 	// timeout or tars_ping or error
@@ -89,6 +97,18 @@ func (s *Protocol) Invoke(ctx context.Context, req []byte) (rsp []byte) {
 		port, _ := current.GetClientPortFromContext(ctx)
 		TLOG.Errorf("handle queue timeout, obj:%s, func:%s, recv time:%d, now:%d, timeout:%d, cost:%d,  addr:(%s:%s), reqId:%d",
 			reqPackage.SServantName, reqPackage.SFuncName, recvPkgTs, now, reqPackage.ITimeout, now-recvPkgTs, ip, port, reqPackage.IRequestId)
+	} else if reqPackage.SFuncName == reflectionFunc && s.app != nil && s.app.ServerConfig().Reflection {
+		// built-in reflection servant, which is not passed to the filters
+		if err := s.app.dispatchReflection(ctx, s.obj, &reqPackage, &rspPackage); err != nil {
+			rspPackage.IRet = 1
+			rspPackage.SResultDesc = err.Error()
+		}
+	} else if reqPackage.SFuncName == healthFunc && s.app != nil {
+		// built-in health servant
+		if err := s.app.dispatchHealth(ctx, &reqPackage, &rspPackage); err != nil {
+			rspPackage.IRet = 1
+			rspPackage.SResultDesc = err.Error()
+		}
 	} else if reqPackage.SFuncName != "tars_ping" { // not tars_ping, normal business call branch
 		if s.withContext {
 			if ok = current.SetRequestStatus(ctx, reqPackage.Status); !ok {
@@ -195,6 +215,26 @@ func (s *Protocol) InvokeTimeout(pkg []byte) []byte {
 	return s.rsp2Byte(&rspPackage)
 }
 
+// RejectShutdown rejects the request received while the server shuts down gracefully,
+// the request is not invoked and the client retries it on the new connection.
+// The oneway request is not rejected, since the client does not know it is rejected.
+func (s *Protocol) RejectShutdown(pkg []byte) ([]byte, bool) {
+	reqPackage := requestf.RequestPacket{}
+	is := codec.NewReader(pkg[4:])
+	reqPackage.ReadFrom(is)
+	if reqPackage.CPacketType == basef.TARSONEWAY {
+		return nil, false
+	}
+	rspPackage := requestf.ResponsePacket{
+		IVersion:    reqPackage.IVersion,
+		CPacketType: reqPackage.CPacketType,
+		IRequestId:  reqPackage.IRequestId,
+		IRet:        basef.TARSSERVEROVERLOAD,
+		SResultDesc: shutdownMsg,
+	}
+	return s.rsp2Byte(&rspPackage), true
+}
+
 // GetCloseMsg return a package to close connection
 func (s *Protocol) GetCloseMsg() []byte {
 	rspPackage := requestf.ResponsePacket{}
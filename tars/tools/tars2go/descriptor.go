@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/TarsCloud/TarsGo/tars/tools/tars2go/parse"
+)
+
+// descriptor is the interface description embedded into the generated code,
+// the reflection servant of the server returns it as json.
+type descriptor struct {
+	Module    string        `json:"module"`
+	Interface descInterface `json:"interface"`
+	Structs   []descStruct  `json:"structs,omitempty"`
+	Enums     []descEnum    `json:"enums,omitempty"`
+}
+
+type descInterface struct {
+	Name      string         `json:"name"`
+	Functions []descFunction `json:"functions"`
+}
+
+type descFunction struct {
+	Name string    `json:"name"`
+	Ret  string    `json:"ret,omitempty"`
+	Args []descArg `json:"args"`
+}
+
+type descArg struct {
+	Tag  int32  `json:"tag"`
+	Name string `json:"name"`
+	Out  bool   `json:"out,omitempty"`
+	Type string `json:"type"`
+}
+
+type descStruct struct {
+	Name    string       `json:"name"`
+	Members []descMember `json:"members"`
+}
+
+type descMember struct {
+	Tag     int32  `json:"tag"`
+	Name    string `json:"name"`
+	Require bool   `json:"require"`
+	Type    string `json:"type"`
+}
+
+type descEnum struct {
+	Name    string           `json:"name"`
+	Members []descEnumMember `json:"members"`
+}
+
+type descEnumMember struct {
+	Name  string `json:"name"`
+	Value int32  `json:"value"`
+}
+
+// genDescriptor returns the json description of itf, with all the structs and enums of the file and its includes.
+// The types are written as in the tars file, with the custom types qualified by the module name.
+func (gen *GenGo) genDescriptor(itf *parse.InterfaceInfo) string {
+	module := originName(gen.p.OriginModule, gen.p.Module)
+	d := descriptor{
+		Module:    module,
+		Interface: descInterface{Name: originName(itf.OriginName, itf.Name)},
+	}
+	for _, fun := range itf.Fun {
+		df := descFunction{Name: originName(fun.OriginName, fun.Name), Args: []descArg{}}
+		if fun.HasRet {
+			df.Ret = descType(module, fun.RetType)
+		}
+		for k, arg := range fun.Args {
+			df.Args = append(df.Args, descArg{
+				Tag:  int32(k + 1),
+				Name: originName(arg.OriginName, arg.Name),
+				Out:  arg.IsOut,
+				Type: descType(module, arg.Type),
+			})
+		}
+		d.Interface.Functions = append(d.Interface.Functions, df)
+	}
+
+	visited := make(map[string]bool)
+	var walk func(p *parse.Parse)
+	walk = func(p *parse.Parse) {
+		if visited[p.Source] {
+			return
+		}
+		visited[p.Source] = true
+		pm := originName(p.OriginModule, p.Module)
+		for _, st := range p.Struct {
+			ds := descStruct{Name: pm + "::" + originName(st.OriginName, st.Name), Members: []descMember{}}
+			for _, mb := range st.Mb {
+				ds.Members = append(ds.Members, descMember{
+					Tag:     mb.Tag,
+					Name:    originName(mb.OriginKey, mb.Key),
+					Require: mb.Require,
+					Type:    descType(pm, mb.Type),
+				})
+			}
+			d.Structs = append(d.Structs, ds)
+		}
+		for _, en := range p.Enum {
+			de := descEnum{Name: pm + "::" + originName(en.OriginName, en.Name)}
+			values := make(map[string]int32)
+			var it int32
+			for _, mb := range en.Mb {
+				switch mb.Type {
+				case 0:
+					it = mb.Value
+				case 1:
+					it = values[mb.Name]
+				}
+				values[mb.Key] = it
+				de.Members = append(de.Members, descEnumMember{Name: mb.Key, Value: it})
+				it++
+			}
+			d.Enums = append(d.Enums, de)
+		}
+		for _, inc := range p.IncParse {
+			walk(inc)
+		}
+	}
+	walk(gen.p)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(d); err != nil {
+		gen.genErr(err.Error())
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func originName(origin, name string) string {
+	if origin != "" {
+		return origin
+	}
+	return name
+}
+
+func descType(module string, ty *parse.VarType) string {
+	var s string
+	switch ty.Type {
+	case parse.TkTVector:
+		return "vector<" + descType(module, ty.TypeK) + ">"
+	case parse.TkTMap:
+		return "map<" + descType(module, ty.TypeK) + ", " + descType(module, ty.TypeV) + ">"
+	case parse.TkTArray:
+		return descType(module, ty.TypeK) + "[" + strconv.FormatInt(ty.TypeL, 10) + "]"
+	case parse.TkName:
+		s = ty.TypeSt
+		if !strings.Contains(s, "::") {
+			s = module + "::" + s
+		}
+	default:
+		s = parse.TokenMap[ty.Type]
+	}
+	if ty.Unsigned {
+		s = "unsigned " + s
+	}
+	return s
+}
+
+func (gen *GenGo) genIFDescriptor(itf *parse.InterfaceInfo) {
+	c := &gen.code
+	desc := gen.genDescriptor(itf)
+	if strings.Contains(desc, "`") {
+		desc = strconv.Quote(desc)
+	} else {
+		desc = "`" + desc + "`"
+	}
+	c.WriteString(`// TarsDescriptor returns the json description of the interface, which is served by the reflection servant.
+func (obj *` + itf.Name + `) TarsDescriptor() string {
+	return ` + desc + `
+}
+`)
+}
@@ -11,12 +11,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+
+	"github.com/TarsCloud/TarsGo/tars/tools/tars2go/parse"
 )
 
 var gE = flag.Bool("E", false, "Generate code before fmt for troubleshooting")
 var gAddServant = flag.Bool("add-servant", true, "Generate AddServant function")
-var gModuleCycle = flag.Bool("module-cycle", false, "support jce module cycle include(do not support jce file cycle include)")
-var gModuleUpper = flag.Bool("module-upper", false, "native module names are supported, otherwise the system will upper the first letter of the module name")
 var gJsonOmitEmpty = flag.Bool("json-omitempty", false, "Generate json omitempty support")
 var dispatchReporter = flag.Bool("dispatch-reporter", false, "Dispatch reporter support")
 var debug = flag.Bool("debug", false, "enable debug mode")
@@ -25,6 +25,8 @@ var gFileMap map[string]bool
 
 func init() {
 	gFileMap = make(map[string]bool)
+	flag.BoolVar(&parse.ModuleCycle, "module-cycle", false, "support jce module cycle include(do not support jce file cycle include)")
+	flag.BoolVar(&parse.ModuleUpper, "module-upper", false, "native module names are supported, otherwise the system will upper the first letter of the module name")
 }
 
 // GenGo record go code information.
@@ -36,7 +38,7 @@ type GenGo struct {
 	tarsPath string
 	module   string
 	prefix   string
-	p        *Parse
+	p        *parse.Parse
 
 	// proto file name(not include .tars)
 	ProtoName string
@@ -52,33 +54,7 @@ func NewGenGo(path string, module string, outdir string) *GenGo {
 		}
 	}
 
-	return &GenGo{path: path, module: module, prefix: outdir, ProtoName: path2ProtoName(path)}
-}
-
-func path2ProtoName(path string) string {
-	iBegin := strings.LastIndex(path, "/")
-	if iBegin == -1 || iBegin >= len(path)-1 {
-		iBegin = 0
-	} else {
-		iBegin++
-	}
-	iEnd := strings.LastIndex(path, ".tars")
-	if iEnd == -1 {
-		iEnd = len(path)
-	}
-
-	return path[iBegin:iEnd]
-}
-
-// Initial capitalization
-func upperFirstLetter(s string) string {
-	if len(s) == 0 {
-		return ""
-	}
-	if len(s) == 1 {
-		return strings.ToUpper(string(s[0]))
-	}
-	return strings.ToUpper(string(s[0])) + s[1:]
+	return &GenGo{path: path, module: module, prefix: outdir, ProtoName: parse.Path2ProtoName(path)}
 }
 
 func getShortTypeName(src string) string {
@@ -105,66 +81,6 @@ func genForHead(vc string) string {
 	return ` for ` + i + `,` + e + ` := int32(0), length;` + i + `<` + e + `;` + i + `++ `
 }
 
-// === rename area ===
-// 0. rename module
-func (p *Parse) rename() {
-	p.OriginModule = p.Module
-	if *gModuleUpper {
-		p.Module = upperFirstLetter(p.Module)
-	}
-}
-
-// 1. struct rename
-// struct Name { 1 require Mb type}
-func (st *StructInfo) rename() {
-	st.OriginName = st.Name
-	st.Name = upperFirstLetter(st.Name)
-	for i := range st.Mb {
-		st.Mb[i].OriginKey = st.Mb[i].Key
-		st.Mb[i].Key = upperFirstLetter(st.Mb[i].Key)
-	}
-}
-
-// 1. interface rename
-// interface Name { Fun }
-func (itf *InterfaceInfo) rename() {
-	itf.OriginName = itf.Name
-	itf.Name = upperFirstLetter(itf.Name)
-	for i := range itf.Fun {
-		itf.Fun[i].rename()
-	}
-}
-
-func (en *EnumInfo) rename() {
-	en.OriginName = en.Name
-	en.Name = upperFirstLetter(en.Name)
-	for i := range en.Mb {
-		en.Mb[i].Key = upperFirstLetter(en.Mb[i].Key)
-	}
-}
-
-func (cst *ConstInfo) rename() {
-	cst.OriginName = cst.Name
-	cst.Name = upperFirstLetter(cst.Name)
-}
-
-// 2. func rename
-// type Fun (arg ArgType), in case keyword and name conflicts,argname need to capitalize.
-// Fun (type int32)
-func (fun *FunInfo) rename() {
-	fun.OriginName = fun.Name
-	fun.Name = upperFirstLetter(fun.Name)
-	for i := range fun.Args {
-		fun.Args[i].OriginName = fun.Args[i].Name
-		// func args donot upper firs
-		//fun.Args[i].Name = upperFirstLetter(fun.Args[i].Name)
-	}
-}
-
-// 3. genType rename all Type
-
-// === rename end ===
-
 // Gen to parse file.
 func (gen *GenGo) Gen() {
 	defer func() {
@@ -175,7 +91,7 @@ func (gen *GenGo) Gen() {
 		}
 	}()
 
-	gen.p = ParseFile(gen.path, make([]string, 0))
+	gen.p = parse.ParseFile(gen.path, make([]string, 0))
 	gen.genAll()
 }
 
@@ -186,7 +102,7 @@ func (gen *GenGo) genAll() {
 	}
 	gFileMap[gen.path] = true
 
-	gen.p.rename()
+	gen.p.Rename()
 	gen.genInclude(gen.p.IncParse)
 
 	gen.code.Reset()
@@ -203,7 +119,7 @@ func (gen *GenGo) genAll() {
 		gen.genStruct(&v)
 	}
 	if len(gen.p.Enum) > 0 || len(gen.p.Const) > 0 || len(gen.p.Struct) > 0 {
-		gen.saveToSourceFile(path2ProtoName(gen.path) + ".go")
+		gen.saveToSourceFile(parse.Path2ProtoName(gen.path) + ".go")
 	}
 
 	for _, v := range gen.p.Interface {
@@ -238,7 +154,7 @@ func (gen *GenGo) saveToSourceFile(filename string) {
 		fmt.Println(string(beauty))
 	} else {
 		var mkPath string
-		if *gModuleCycle == true {
+		if parse.ModuleCycle {
 			mkPath = prefix + gen.ProtoName + "/" + gen.p.Module
 		} else {
 			mkPath = prefix + gen.p.Module
@@ -281,7 +197,7 @@ import (
 
 	mImports := make(map[string]bool)
 	for _, st := range gen.p.Struct {
-		if *gModuleCycle == true {
+		if parse.ModuleCycle {
 			for k, v := range st.DependModuleWithJce {
 				gen.genStructImport(k, v, mImports)
 			}
@@ -308,7 +224,7 @@ func (gen *GenGo) genStructImport(module string, protoName string, mImports map[
 	var moduleStr string
 	var jcePath string
 	var moduleAlia string
-	if *gModuleCycle == true {
+	if parse.ModuleCycle {
 		moduleStr = module[len(protoName)+1:]
 		jcePath = protoName + "/"
 		moduleAlia = module + " "
@@ -323,8 +239,8 @@ func (gen *GenGo) genStructImport(module string, protoName string, mImports map[
 		}
 	}
 
-	if *gModuleUpper {
-		moduleAlia = upperFirstLetter(moduleAlia)
+	if parse.ModuleUpper {
+		moduleAlia = parse.UpperFirstLetter(moduleAlia)
 	}
 
 	// example:
@@ -349,7 +265,7 @@ func (gen *GenGo) genStructImport(module string, protoName string, mImports map[
 	mImports[moduleAlia+`"`+modulePath+`"`] = true
 }
 
-func (gen *GenGo) genIFPackage(itf *InterfaceInfo) {
+func (gen *GenGo) genIFPackage(itf *parse.InterfaceInfo) {
 	gen.code.WriteString("package " + gen.p.Module + "\n\n")
 	gen.code.WriteString(`
 import (
@@ -375,7 +291,7 @@ import (
 		gen.code.WriteString("tarstrace \"" + gen.tarsPath + "/util/trace\"\n")
 	}
 
-	if *gModuleCycle == true {
+	if parse.ModuleCycle {
 		for k, v := range itf.DependModuleWithJce {
 			gen.genIFImport(k, v)
 		}
@@ -400,7 +316,7 @@ func (gen *GenGo) genIFImport(module string, protoName string) {
 	var moduleStr string
 	var jcePath string
 	var moduleAlia string
-	if *gModuleCycle == true {
+	if parse.ModuleCycle {
 		moduleStr = module[len(protoName)+1:]
 		jcePath = protoName + "/"
 		moduleAlia = module + " "
@@ -414,8 +330,8 @@ func (gen *GenGo) genIFImport(module string, protoName string) {
 		}
 	}
 
-	if *gModuleUpper {
-		moduleAlia = upperFirstLetter(moduleAlia)
+	if parse.ModuleUpper {
+		moduleAlia = parse.UpperFirstLetter(moduleAlia)
 	}
 
 	// example:
@@ -440,67 +356,67 @@ func (gen *GenGo) genIFImport(module string, protoName string) {
 	gen.code.WriteString(moduleAlia + `"` + modulePath + `"` + "\n")
 }
 
-func (gen *GenGo) genType(ty *VarType) string {
+func (gen *GenGo) genType(ty *parse.VarType) string {
 	ret := ""
 	switch ty.Type {
-	case tkTBool:
+	case parse.TkTBool:
 		ret = "bool"
-	case tkTInt:
+	case parse.TkTInt:
 		if ty.Unsigned {
 			ret = "uint32"
 		} else {
 			ret = "int32"
 		}
-	case tkTShort:
+	case parse.TkTShort:
 		if ty.Unsigned {
 			ret = "uint16"
 		} else {
 			ret = "int16"
 		}
-	case tkTByte:
+	case parse.TkTByte:
 		if ty.Unsigned {
 			ret = "uint8"
 		} else {
 			ret = "int8"
 		}
-	case tkTLong:
+	case parse.TkTLong:
 		if ty.Unsigned {
 			ret = "uint64"
 		} else {
 			ret = "int64"
 		}
-	case tkTFloat:
+	case parse.TkTFloat:
 		ret = "float32"
-	case tkTDouble:
+	case parse.TkTDouble:
 		ret = "float64"
-	case tkTString:
+	case parse.TkTString:
 		ret = "string"
-	case tkTVector:
+	case parse.TkTVector:
 		ret = "[]" + gen.genType(ty.TypeK)
-	case tkTMap:
+	case parse.TkTMap:
 		ret = "map[" + gen.genType(ty.TypeK) + "]" + gen.genType(ty.TypeV)
-	case tkName:
+	case parse.TkName:
 		ret = strings.Replace(ty.TypeSt, "::", ".", -1)
 		vec := strings.Split(ty.TypeSt, "::")
 		for i := range vec {
-			if *gModuleUpper {
-				vec[i] = upperFirstLetter(vec[i])
+			if parse.ModuleUpper {
+				vec[i] = parse.UpperFirstLetter(vec[i])
 			} else {
 				if i == (len(vec) - 1) {
-					vec[i] = upperFirstLetter(vec[i])
+					vec[i] = parse.UpperFirstLetter(vec[i])
 				}
 			}
 		}
 		ret = strings.Join(vec, ".")
-	case tkTArray:
+	case parse.TkTArray:
 		ret = "[" + fmt.Sprintf("%v", ty.TypeL) + "]" + gen.genType(ty.TypeK)
 	default:
-		gen.genErr("Unknown Type " + TokenMap[ty.Type])
+		gen.genErr("Unknown Type " + parse.TokenMap[ty.Type])
 	}
 	return ret
 }
 
-func (gen *GenGo) genStructDefine(st *StructInfo) {
+func (gen *GenGo) genStructDefine(st *parse.StructInfo) {
 	c := &gen.code
 	c.WriteString("// " + st.Name + " struct implement\n")
 	c.WriteString("type " + st.Name + " struct {\n")
@@ -515,13 +431,13 @@ func (gen *GenGo) genStructDefine(st *StructInfo) {
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genFunResetDefault(st *StructInfo) {
+func (gen *GenGo) genFunResetDefault(st *parse.StructInfo) {
 	c := &gen.code
 
 	c.WriteString("func (st *" + st.Name + ") ResetDefault() {\n")
 
 	for _, v := range st.Mb {
-		if v.Type.CType == tkStruct {
+		if v.Type.CType == parse.TkStruct {
 			c.WriteString("st." + v.Key + ".ResetDefault()\n")
 		}
 		if v.Default == "" {
@@ -532,7 +448,7 @@ func (gen *GenGo) genFunResetDefault(st *StructInfo) {
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genWriteSimpleList(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genWriteSimpleList(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	tag := strconv.Itoa(int(mb.Tag))
 	unsign := "Int8"
@@ -552,11 +468,11 @@ err = buf.WriteSlice` + unsign + `(` + gen.genVariableName(prefix, mb.Key) + `)
 `)
 }
 
-func (gen *GenGo) genWriteVector(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genWriteVector(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 
 	// SimpleList
-	if mb.Type.TypeK.Type == tkTByte && !mb.Type.TypeK.Unsigned {
+	if mb.Type.TypeK.Type == parse.TkTByte && !mb.Type.TypeK.Unsigned {
 		gen.genWriteSimpleList(mb, prefix, hasRet)
 		return
 	}
@@ -573,7 +489,7 @@ for _, v := range ` + gen.genVariableName(prefix, mb.Key) + ` {
 `)
 	// for _, v := range can nesting for _, v := range，does not conflict, support multidimensional arrays
 
-	dummy := &StructMember{}
+	dummy := &parse.StructMember{}
 	dummy.Type = mb.Type.TypeK
 	dummy.Key = "v"
 	gen.genWriteVar(dummy, "", hasRet)
@@ -581,11 +497,11 @@ for _, v := range ` + gen.genVariableName(prefix, mb.Key) + ` {
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genWriteArray(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genWriteArray(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 
 	// SimpleList
-	if mb.Type.TypeK.Type == tkTByte && !mb.Type.TypeK.Unsigned {
+	if mb.Type.TypeK.Type == parse.TkTByte && !mb.Type.TypeK.Unsigned {
 		gen.genWriteSimpleList(mb, prefix, hasRet)
 		return
 	}
@@ -602,7 +518,7 @@ for _, v := range ` + gen.genVariableName(prefix, mb.Key) + ` {
 `)
 	// for _, v := range can nesting for _, v := range，does not conflict, support multidimensional arrays
 
-	dummy := &StructMember{}
+	dummy := &parse.StructMember{}
 	dummy.Type = mb.Type.TypeK
 	dummy.Key = "v"
 	gen.genWriteVar(dummy, "", hasRet)
@@ -610,7 +526,7 @@ for _, v := range ` + gen.genVariableName(prefix, mb.Key) + ` {
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genWriteStruct(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genWriteStruct(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	tag := strconv.Itoa(int(mb.Tag))
 	c.WriteString(`
@@ -619,7 +535,7 @@ err = ` + prefix + mb.Key + `.WriteBlock(buf, ` + tag + `)
 `)
 }
 
-func (gen *GenGo) genWriteMap(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genWriteMap(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	tag := strconv.Itoa(int(mb.Tag))
 	vc := strconv.Itoa(gen.vc)
@@ -634,12 +550,12 @@ for k` + vc + `, v` + vc + ` := range ` + gen.genVariableName(prefix, mb.Key) +
 `)
 	// for _, v := range can nesting for _, v := range，does not conflict, support multidimensional arrays
 
-	dummy := &StructMember{}
+	dummy := &parse.StructMember{}
 	dummy.Type = mb.Type.TypeK
 	dummy.Key = "k" + vc
 	gen.genWriteVar(dummy, "", hasRet)
 
-	dummy = &StructMember{}
+	dummy = &parse.StructMember{}
 	dummy.Type = mb.Type.TypeV
 	dummy.Key = "v" + vc
 	dummy.Tag = 1
@@ -648,19 +564,19 @@ for k` + vc + `, v` + vc + ` := range ` + gen.genVariableName(prefix, mb.Key) +
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genWriteVar(v *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genWriteVar(v *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 
 	switch v.Type.Type {
-	case tkTVector:
+	case parse.TkTVector:
 		gen.genWriteVector(v, prefix, hasRet)
-	case tkTArray:
+	case parse.TkTArray:
 		gen.genWriteArray(v, prefix, hasRet)
-	case tkTMap:
+	case parse.TkTMap:
 		gen.genWriteMap(v, prefix, hasRet)
-	case tkName:
-		if v.Type.CType == tkEnum {
-			// tkEnum enumeration processing
+	case parse.TkName:
+		if v.Type.CType == parse.TkEnum {
+			// parse.TkEnum enumeration processing
 			tag := strconv.Itoa(int(v.Tag))
 			c.WriteString(`
 err = buf.WriteInt32(int32(` + gen.genVariableName(prefix, v.Key) + `),` + tag + `)
@@ -672,13 +588,13 @@ err = buf.WriteInt32(int32(` + gen.genVariableName(prefix, v.Key) + `),` + tag +
 	default:
 		tag := strconv.Itoa(int(v.Tag))
 		c.WriteString(`
-err = buf.Write` + upperFirstLetter(gen.genType(v.Type)) + `(` + gen.genVariableName(prefix, v.Key) + `, ` + tag + `)
+err = buf.Write` + parse.UpperFirstLetter(gen.genType(v.Type)) + `(` + gen.genVariableName(prefix, v.Key) + `, ` + tag + `)
 ` + errString(hasRet) + `
 `)
 	}
 }
 
-func (gen *GenGo) genFunWriteBlock(st *StructInfo) {
+func (gen *GenGo) genFunWriteBlock(st *parse.StructInfo) {
 	c := &gen.code
 
 	// WriteBlock function head
@@ -704,7 +620,7 @@ func (st *` + st.Name + `) WriteBlock(buf *codec.Buffer, tag byte) error {
 `)
 }
 
-func (gen *GenGo) genFunWriteTo(st *StructInfo) {
+func (gen *GenGo) genFunWriteTo(st *parse.StructInfo) {
 	c := &gen.code
 
 	c.WriteString(`// WriteTo encode struct to buffer
@@ -720,7 +636,7 @@ func (st *` + st.Name + `) WriteTo(buf *codec.Buffer) (err error) {
 `)
 }
 
-func (gen *GenGo) genReadSimpleList(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genReadSimpleList(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	unsign := "Int8"
 	if mb.Type.TypeK.Unsigned {
@@ -738,7 +654,7 @@ err = readBuf.ReadSlice` + unsign + `(&` + prefix + mb.Key + `, length, true)
 `)
 }
 
-func (gen *GenGo) genReadVector(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genReadVector(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	errStr := errString(hasRet)
 
@@ -770,7 +686,7 @@ if ty == codec.LIST {
   ` + genForHead(vc) + `{
 `)
 
-	dummy := &StructMember{}
+	dummy := &parse.StructMember{}
 	dummy.Type = mb.Type.TypeK
 	dummy.Key = mb.Key + "[i" + vc + "]"
 	gen.genReadVar(dummy, prefix, hasRet)
@@ -778,7 +694,7 @@ if ty == codec.LIST {
 	c.WriteString(`}
 } else if ty == codec.SimpleList {
 `)
-	if mb.Type.TypeK.Type == tkTByte {
+	if mb.Type.TypeK.Type == parse.TkTByte {
 		gen.genReadSimpleList(mb, prefix, hasRet)
 	} else {
 		c.WriteString(`err = fmt.Errorf("not support SimpleList type")
@@ -792,7 +708,7 @@ if ty == codec.LIST {
 `)
 }
 
-func (gen *GenGo) genReadArray(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genReadArray(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	errStr := errString(hasRet)
 
@@ -824,7 +740,7 @@ if ty == codec.LIST {
   ` + genForHead(vc) + `{
 `)
 
-	dummy := &StructMember{}
+	dummy := &parse.StructMember{}
 	dummy.Type = mb.Type.TypeK
 	dummy.Key = mb.Key + "[i" + vc + "]"
 	gen.genReadVar(dummy, prefix, hasRet)
@@ -832,7 +748,7 @@ if ty == codec.LIST {
 	c.WriteString(`}
 } else if ty == codec.SimpleList {
 `)
-	if mb.Type.TypeK.Type == tkTByte {
+	if mb.Type.TypeK.Type == parse.TkTByte {
 		gen.genReadSimpleList(mb, prefix, hasRet)
 	} else {
 		c.WriteString(`err = fmt.Errorf("not support SimpleList type")
@@ -846,7 +762,7 @@ if ty == codec.LIST {
 `)
 }
 
-func (gen *GenGo) genReadStruct(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genReadStruct(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	tag := strconv.Itoa(int(mb.Tag))
 	require := "false"
@@ -859,7 +775,7 @@ err = ` + prefix + mb.Key + `.ReadBlock(readBuf, ` + tag + `, ` + require + `)
 `)
 }
 
-func (gen *GenGo) genReadMap(mb *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genReadMap(mb *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 	tag := strconv.Itoa(int(mb.Tag))
 	errStr := errString(hasRet)
@@ -891,12 +807,12 @@ err = readBuf.ReadInt32(&length, 0, true)
 	var v` + vc + ` ` + gen.genType(mb.Type.TypeV) + `
 `)
 
-	dummy := &StructMember{}
+	dummy := &parse.StructMember{}
 	dummy.Type = mb.Type.TypeK
 	dummy.Key = "k" + vc
 	gen.genReadVar(dummy, "", hasRet)
 
-	dummy = &StructMember{}
+	dummy = &parse.StructMember{}
 	dummy.Type = mb.Type.TypeV
 	dummy.Key = "v" + vc
 	dummy.Tag = 1
@@ -908,18 +824,18 @@ err = readBuf.ReadInt32(&length, 0, true)
 `)
 }
 
-func (gen *GenGo) genReadVar(v *StructMember, prefix string, hasRet bool) {
+func (gen *GenGo) genReadVar(v *parse.StructMember, prefix string, hasRet bool) {
 	c := &gen.code
 
 	switch v.Type.Type {
-	case tkTVector:
+	case parse.TkTVector:
 		gen.genReadVector(v, prefix, hasRet)
-	case tkTArray:
+	case parse.TkTArray:
 		gen.genReadArray(v, prefix, hasRet)
-	case tkTMap:
+	case parse.TkTMap:
 		gen.genReadMap(v, prefix, hasRet)
-	case tkName:
-		if v.Type.CType == tkEnum {
+	case parse.TkName:
+		if v.Type.CType == parse.TkEnum {
 			require := "false"
 			if v.Require {
 				require = "true"
@@ -939,13 +855,13 @@ err = readBuf.ReadInt32((*int32)(&` + prefix + v.Key + `),` + tag + `, ` + requi
 		}
 		tag := strconv.Itoa(int(v.Tag))
 		c.WriteString(`
-err = readBuf.Read` + upperFirstLetter(gen.genType(v.Type)) + `(&` + prefix + v.Key + `, ` + tag + `, ` + require + `)
+err = readBuf.Read` + parse.UpperFirstLetter(gen.genType(v.Type)) + `(&` + prefix + v.Key + `, ` + tag + `, ` + require + `)
 ` + errString(hasRet) + `
 `)
 	}
 }
 
-func (gen *GenGo) genFunReadFrom(st *StructInfo) {
+func (gen *GenGo) genFunReadFrom(st *parse.StructInfo) {
 	c := &gen.code
 
 	c.WriteString(`// ReadFrom reads  from readBuf and put into struct.
@@ -974,7 +890,7 @@ func (st *` + st.Name + `) ReadFrom(readBuf *codec.Reader) error {
 `)
 }
 
-func (gen *GenGo) genFunReadBlock(st *StructInfo) {
+func (gen *GenGo) genFunReadBlock(st *parse.StructInfo) {
 	c := &gen.code
 
 	c.WriteString(`// ReadBlock reads struct from the given tag , require or optional.
@@ -1011,9 +927,9 @@ func (st *` + st.Name + `) ReadBlock(readBuf *codec.Reader, tag byte, require bo
 `)
 }
 
-func (gen *GenGo) genStruct(st *StructInfo) {
+func (gen *GenGo) genStruct(st *parse.StructInfo) {
 	gen.vc = 0
-	st.rename()
+	st.Rename()
 
 	gen.genStructDefine(st)
 	gen.genFunResetDefault(st)
@@ -1025,16 +941,16 @@ func (gen *GenGo) genStruct(st *StructInfo) {
 	gen.genFunWriteBlock(st)
 }
 
-func (gen *GenGo) makeEnumName(en *EnumInfo, mb *EnumMember) string {
-	return upperFirstLetter(en.Name) + "_" + upperFirstLetter(mb.Key)
+func (gen *GenGo) makeEnumName(en *parse.EnumInfo, mb *parse.EnumMember) string {
+	return parse.UpperFirstLetter(en.Name) + "_" + parse.UpperFirstLetter(mb.Key)
 }
 
-func (gen *GenGo) genEnum(en *EnumInfo) {
+func (gen *GenGo) genEnum(en *parse.EnumInfo) {
 	if len(en.Mb) == 0 {
 		return
 	}
 
-	en.rename()
+	en.Rename()
 
 	c := &gen.code
 	c.WriteString("type " + en.Name + " int32\n")
@@ -1073,7 +989,7 @@ func (gen *GenGo) genEnum(en *EnumInfo) {
 	c.WriteString(")\n")
 }
 
-func (gen *GenGo) genConst(cst []ConstInfo) {
+func (gen *GenGo) genConst(cst []parse.ConstInfo) {
 	if len(cst) == 0 {
 		return
 	}
@@ -1083,30 +999,30 @@ func (gen *GenGo) genConst(cst []ConstInfo) {
 	c.WriteString("const (\n")
 
 	for _, v := range gen.p.Const {
-		v.rename()
+		v.Rename()
 		c.WriteString(v.Name + " " + gen.genType(v.Type) + " = " + v.Value + "\n")
 	}
 
 	c.WriteString(")\n")
 }
 
-func (gen *GenGo) genInclude(ps []*Parse) {
+func (gen *GenGo) genInclude(ps []*parse.Parse) {
 	for _, v := range ps {
 		gen2 := &GenGo{
 			path:      v.Source,
 			module:    gen.module,
 			prefix:    gen.prefix,
 			tarsPath:  gTarsPath,
-			ProtoName: path2ProtoName(v.Source),
+			ProtoName: parse.Path2ProtoName(v.Source),
 		}
 		gen2.p = v
 		gen2.genAll()
 	}
 }
 
-func (gen *GenGo) genInterface(itf *InterfaceInfo) {
+func (gen *GenGo) genInterface(itf *parse.InterfaceInfo) {
 	gen.code.Reset()
-	itf.rename()
+	itf.Rename()
 
 	gen.genHead()
 	gen.genIFPackage(itf)
@@ -1118,10 +1034,14 @@ func (gen *GenGo) genInterface(itf *InterfaceInfo) {
 
 	gen.genIFDispatch(itf)
 
+	if !withoutReflection {
+		gen.genIFDescriptor(itf)
+	}
+
 	gen.saveToSourceFile(itf.Name + ".tars.go")
 }
 
-func (gen *GenGo) genIFProxy(itf *InterfaceInfo) {
+func (gen *GenGo) genIFProxy(itf *parse.InterfaceInfo) {
 	c := &gen.code
 	c.WriteString("// " + itf.Name + " struct\n")
 	c.WriteString("type " + itf.Name + ` struct {
@@ -1172,7 +1092,7 @@ func (obj *` + itf.Name + `) AddServantWithContext(imp ` + itf.Name + `ServantWi
 	}
 }
 
-func (gen *GenGo) genIFProxyFun(interfName string, fun *FunInfo, withContext bool, isOneWay bool) {
+func (gen *GenGo) genIFProxyFun(interfName string, fun *parse.FunInfo, withContext bool, isOneWay bool) {
 	c := &gen.code
 	if withContext {
 		if isOneWay {
@@ -1227,7 +1147,7 @@ func (gen *GenGo) genIFProxyFun(interfName string, fun *FunInfo, withContext boo
 		if v.IsOut {
 			isOut = true
 		}
-		dummy := &StructMember{}
+		dummy := &parse.StructMember{}
 		dummy.Type = v.Type
 		dummy.Key = v.Name
 		dummy.Tag = int32(k + 1)
@@ -1294,7 +1214,7 @@ tarsResp := new(requestf.ResponsePacket)`)
 		c.WriteString("readBuf := codec.NewReader(tools.Int8ToByte(tarsResp.SBuffer))")
 	}
 	if fun.HasRet && !isOneWay {
-		dummy := &StructMember{}
+		dummy := &parse.StructMember{}
 		dummy.Type = fun.RetType
 		dummy.Key = "ret"
 		dummy.Tag = 0
@@ -1305,7 +1225,7 @@ tarsResp := new(requestf.ResponsePacket)`)
 	if !isOneWay {
 		for k, v := range fun.Args {
 			if v.IsOut {
-				dummy := &StructMember{}
+				dummy := &parse.StructMember{}
 				dummy.Type = v.Type
 				dummy.Key = "(*" + v.Name + ")"
 				dummy.Tag = int32(k + 1)
@@ -1383,17 +1303,17 @@ if ok && trace.Call() {
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genArgs(arg *ArgInfo) {
+func (gen *GenGo) genArgs(arg *parse.ArgInfo) {
 	c := &gen.code
 	c.WriteString(arg.Name + " ")
-	if arg.IsOut || arg.Type.CType == tkStruct {
+	if arg.IsOut || arg.Type.CType == parse.TkStruct {
 		c.WriteString("*")
 	}
 
 	c.WriteString(gen.genType(arg.Type) + ",")
 }
 
-func (gen *GenGo) genIFServer(itf *InterfaceInfo) {
+func (gen *GenGo) genIFServer(itf *parse.InterfaceInfo) {
 	c := &gen.code
 	c.WriteString("type " + itf.Name + "Servant interface {\n")
 	for _, v := range itf.Fun {
@@ -1402,7 +1322,7 @@ func (gen *GenGo) genIFServer(itf *InterfaceInfo) {
 	c.WriteString("}\n")
 }
 
-func (gen *GenGo) genIFServerWithContext(itf *InterfaceInfo) {
+func (gen *GenGo) genIFServerWithContext(itf *parse.InterfaceInfo) {
 	c := &gen.code
 	c.WriteString("type " + itf.Name + "ServantWithContext interface {\n")
 	for _, v := range itf.Fun {
@@ -1411,7 +1331,7 @@ func (gen *GenGo) genIFServerWithContext(itf *InterfaceInfo) {
 	c.WriteString("} \n")
 }
 
-func (gen *GenGo) genIFServerFun(fun *FunInfo) {
+func (gen *GenGo) genIFServerFun(fun *parse.FunInfo) {
 	c := &gen.code
 	c.WriteString(fun.Name + "(")
 	for _, v := range fun.Args {
@@ -1425,7 +1345,7 @@ func (gen *GenGo) genIFServerFun(fun *FunInfo) {
 	c.WriteString("err error)\n")
 }
 
-func (gen *GenGo) genIFServerFunWithContext(fun *FunInfo) {
+func (gen *GenGo) genIFServerFunWithContext(fun *parse.FunInfo) {
 	c := &gen.code
 	c.WriteString(fun.Name + "(tarsCtx context.Context, ")
 	for _, v := range fun.Args {
@@ -1439,7 +1359,7 @@ func (gen *GenGo) genIFServerFunWithContext(fun *FunInfo) {
 	c.WriteString("err error)\n")
 }
 
-func (gen *GenGo) genIFDispatch(itf *InterfaceInfo) {
+func (gen *GenGo) genIFDispatch(itf *parse.InterfaceInfo) {
 	c := &gen.code
 	c.WriteString("// Dispatch is used to call the server side implement for the method defined in the tars file. withContext shows using context or not.  \n")
 	c.WriteString("func(obj *" + itf.Name + `) Dispatch(tarsCtx context.Context, val interface{}, tarsReq *requestf.RequestPacket, tarsResp *requestf.ResponsePacket, withContext bool) (err error) {
@@ -1506,7 +1426,7 @@ func (gen *GenGo) genIFDispatch(itf *InterfaceInfo) {
 `)
 }
 
-func (gen *GenGo) genSwitchCase(tname string, fun *FunInfo) {
+func (gen *GenGo) genSwitchCase(tname string, fun *parse.FunInfo) {
 	c := &gen.code
 	c.WriteString(`case "` + fun.OriginName + `":` + "\n")
 
@@ -1514,9 +1434,9 @@ func (gen *GenGo) genSwitchCase(tname string, fun *FunInfo) {
 	outArgsCount := 0
 	for _, v := range fun.Args {
 		c.WriteString("var " + v.Name + " " + gen.genType(v.Type) + "\n")
-		if v.Type.Type == tkTMap {
+		if v.Type.Type == parse.TkTMap {
 			c.WriteString(v.Name + " = make(" + gen.genType(v.Type) + ")\n")
-		} else if v.Type.Type == tkTVector {
+		} else if v.Type.Type == parse.TkTVector {
 			c.WriteString(v.Name + " = make(" + gen.genType(v.Type) + ", 0)\n")
 		}
 		if v.IsOut {
@@ -1536,7 +1456,7 @@ func (gen *GenGo) genSwitchCase(tname string, fun *FunInfo) {
 		for k, v := range fun.Args {
 
 			if !v.IsOut {
-				dummy := &StructMember{}
+				dummy := &parse.StructMember{}
 				dummy.Type = v.Type
 				dummy.Key = v.Name
 				dummy.Tag = int32(k + 1)
@@ -1558,7 +1478,7 @@ func (gen *GenGo) genSwitchCase(tname string, fun *FunInfo) {
 				c.WriteString(`reqTup.GetBuffer("` + v.Name + `", &tupBuffer)` + "\n")
 				c.WriteString("readBuf.Reset(tupBuffer)")
 
-				dummy := &StructMember{}
+				dummy := &parse.StructMember{}
 				dummy.Type = v.Type
 				dummy.Key = v.Name
 				dummy.Tag = 0
@@ -1581,7 +1501,7 @@ func (gen *GenGo) genSwitchCase(tname string, fun *FunInfo) {
 			if !v.IsOut {
 				c.WriteString("{\n")
 				c.WriteString(`jsonStr, _ := json.Marshal(jsonData["` + v.Name + `"])` + "\n")
-				if v.Type.CType == tkStruct {
+				if v.Type.CType == parse.TkStruct {
 					c.WriteString(v.Name + ".ResetDefault()\n")
 				}
 				c.WriteString("if err = json.Unmarshal(jsonStr, &" + v.Name + "); err != nil {")
@@ -1634,7 +1554,7 @@ if ok && trace.Call() {
 		imp := val.(` + tname + `Servant)
 		funRet, err = imp.` + fun.Name + `(`)
 		for _, v := range fun.Args {
-			if v.IsOut || v.Type.CType == tkStruct {
+			if v.IsOut || v.Type.CType == parse.TkStruct {
 				c.WriteString("&" + v.Name + ",")
 			} else {
 				c.WriteString(v.Name + ",")
@@ -1647,7 +1567,7 @@ if ok && trace.Call() {
 		imp := val.(` + tname + `ServantWithContext)
 		funRet, err = imp.` + fun.Name + `(tarsCtx ,`)
 		for _, v := range fun.Args {
-			if v.IsOut || v.Type.CType == tkStruct {
+			if v.IsOut || v.Type.CType == parse.TkStruct {
 				c.WriteString("&" + v.Name + ",")
 			} else {
 				c.WriteString(v.Name + ",")
@@ -1660,7 +1580,7 @@ if ok && trace.Call() {
 		imp := val.(` + tname + `Servant)
 		err = imp.` + fun.Name + `(`)
 		for _, v := range fun.Args {
-			if v.IsOut || v.Type.CType == tkStruct {
+			if v.IsOut || v.Type.CType == parse.TkStruct {
 				c.WriteString("&" + v.Name + ",")
 			} else {
 				c.WriteString(v.Name + ",")
@@ -1673,7 +1593,7 @@ if ok && trace.Call() {
 		imp := val.(` + tname + `ServantWithContext)
 		err = imp.` + fun.Name + `(tarsCtx ,`)
 		for _, v := range fun.Args {
-			if v.IsOut || v.Type.CType == tkStruct {
+			if v.IsOut || v.Type.CType == parse.TkStruct {
 				c.WriteString("&" + v.Name + ",")
 			} else {
 				c.WriteString(v.Name + ",")
@@ -1691,7 +1611,7 @@ if ok && trace.Call() {
 		}
 		for _, v := range fun.Args {
 			prefix := ""
-			if v.Type.CType == tkStruct {
+			if v.Type.CType == parse.TkStruct {
 				prefix = "&"
 			}
 			if v.IsOut {
@@ -1717,7 +1637,7 @@ if ok && trace.Call() {
 	`)
 
 	if fun.HasRet {
-		dummy := &StructMember{}
+		dummy := &parse.StructMember{}
 		dummy.Type = fun.RetType
 		dummy.Key = "funRet"
 		dummy.Tag = 0
@@ -1727,7 +1647,7 @@ if ok && trace.Call() {
 
 	for k, v := range fun.Args {
 		if v.IsOut {
-			dummy := &StructMember{}
+			dummy := &parse.StructMember{}
 			dummy.Type = v.Type
 			dummy.Key = v.Name
 			dummy.Tag = int32(k + 1)
@@ -1741,7 +1661,7 @@ if ok && trace.Call() {
 rspTup := tup.NewUniAttribute()
 `)
 	if fun.HasRet {
-		dummy := &StructMember{}
+		dummy := &parse.StructMember{}
 		dummy.Type = fun.RetType
 		dummy.Key = "funRet"
 		dummy.Tag = 0
@@ -1758,7 +1678,7 @@ rspTup := tup.NewUniAttribute()
 		if v.IsOut {
 			c.WriteString(`
 		buf.Reset()`)
-			dummy := &StructMember{}
+			dummy := &parse.StructMember{}
 			dummy.Type = v.Type
 			dummy.Key = v.Name
 			dummy.Tag = 0
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/TarsCloud/TarsGo/tars/tools/tars2go/parse"
 )
 
 type importPath []string
@@ -28,9 +30,9 @@ var (
 	gOutdir   string
 	gModule   string
 	gInclude  string
-	includes  []string
 
-	withoutTrace bool
+	withoutTrace      bool
+	withoutReflection bool
 )
 
 func printhelp() {
@@ -52,13 +54,14 @@ func main() {
 	flag.StringVar(&gModule, "module", "", "current go module path")
 	flag.StringVar(&gInclude, "include", "", "set search path of tars protocol")
 	flag.BoolVar(&withoutTrace, "without-trace", false, "不需要调用链追踪逻辑")
+	flag.BoolVar(&withoutReflection, "without-reflection", false, "不生成反射服务使用的接口描述")
 	flag.Parse()
 
 	if flag.NArg() == 0 {
 		printhelp()
 		os.Exit(0)
 	}
-	includes = strings.FieldsFunc(gInclude, func(r rune) bool {
+	parse.Includes = strings.FieldsFunc(gInclude, func(r rune) bool {
 		return r == ';' || r == ',' || r == ':' || r == ' '
 	})
 
@@ -1,4 +1,4 @@
-package main
+package parse
 
 import (
 	"bytes"
@@ -13,109 +13,109 @@ const EOS = 0
 type TK byte
 
 const (
-	tkEos          TK = iota
-	tkBraceLeft       //({)
-	tkBraceRight      //}
-	tkSemi            //;
-	tkEq              //=
-	tkShl             //<
-	tkShr             //>
-	tkComma           //,
-	tkPtl             //(
-	tkPtr             //)
-	tkSquareLeft      //[
-	tkSquarerRight    //]
-	tkInclude         //#include
-
-	tkDummyKeywordBegin
+	TkEos          TK = iota
+	TkBraceLeft       //({)
+	TkBraceRight      //}
+	TkSemi            //;
+	TkEq              //=
+	TkShl             //<
+	TkShr             //>
+	TkComma           //,
+	TkPtl             //(
+	TkPtr             //)
+	TkSquareLeft      //[
+	TkSquarerRight    //]
+	TkInclude         //#include
+
+	TkDummyKeywordBegin
 	// keyword
-	tkModule
-	tkEnum
-	tkStruct
-	tkInterface
-	tkRequire
-	tkOptional
-	tkConst
-	tkUnsigned
-	tkVoid
-	tkOut
-	tkKey
-	tkTrue
-	tkFalse
-	tkDummyKeywordEnd
-
-	tkDummyTypeBegin
+	TkModule
+	TkEnum
+	TkStruct
+	TkInterface
+	TkRequire
+	TkOptional
+	TkConst
+	TkUnsigned
+	TkVoid
+	TkOut
+	TkKey
+	TkTrue
+	TkFalse
+	TkDummyKeywordEnd
+
+	TkDummyTypeBegin
 	// type
-	tkTInt
-	tkTBool
-	tkTShort
-	tkTByte
-	tkTLong
-	tkTFloat
-	tkTDouble
-	tkTString
-	tkTVector
-	tkTMap
-	tkTArray
-	tkDummyTypeEnd
-
-	tkName // variable name
+	TkTInt
+	TkTBool
+	TkTShort
+	TkTByte
+	TkTLong
+	TkTFloat
+	TkTDouble
+	TkTString
+	TkTVector
+	TkTMap
+	TkTArray
+	TkDummyTypeEnd
+
+	TkName // variable name
 	// value
-	tkString
-	tkInteger
-	tkFloat
+	TkString
+	TkInteger
+	TkFloat
 )
 
 // TokenMap record token  value.
 var TokenMap = [...]string{
-	tkEos: "<eos>",
-
-	tkBraceLeft:    "{",
-	tkBraceRight:   "}",
-	tkSemi:         ";",
-	tkEq:           "=",
-	tkShl:          "<",
-	tkShr:          ">",
-	tkComma:        ",",
-	tkPtl:          "(",
-	tkPtr:          ")",
-	tkSquareLeft:   "[",
-	tkSquarerRight: "]",
-	tkInclude:      "#include",
+	TkEos: "<eos>",
+
+	TkBraceLeft:    "{",
+	TkBraceRight:   "}",
+	TkSemi:         ";",
+	TkEq:           "=",
+	TkShl:          "<",
+	TkShr:          ">",
+	TkComma:        ",",
+	TkPtl:          "(",
+	TkPtr:          ")",
+	TkSquareLeft:   "[",
+	TkSquarerRight: "]",
+	TkInclude:      "#include",
 
 	// keyword
-	tkModule:    "module",
-	tkEnum:      "enum",
-	tkStruct:    "struct",
-	tkInterface: "interface",
-	tkRequire:   "require",
-	tkOptional:  "optional",
-	tkConst:     "const",
-	tkUnsigned:  "unsigned",
-	tkVoid:      "void",
-	tkOut:       "out",
-	tkKey:       "key",
-	tkTrue:      "true",
-	tkFalse:     "false",
+	TkModule:    "module",
+	TkEnum:      "enum",
+	TkStruct:    "struct",
+	TkInterface: "interface",
+	TkRequire:   "require",
+	TkOptional:  "optional",
+	TkConst:     "const",
+	TkUnsigned:  "unsigned",
+	TkVoid:      "void",
+	TkOut:       "out",
+	TkKey:       "key",
+	TkTrue:      "true",
+	TkFalse:     "false",
 
 	// type
-	tkTInt:    "int",
-	tkTBool:   "bool",
-	tkTShort:  "short",
-	tkTByte:   "byte",
-	tkTLong:   "long",
-	tkTFloat:  "float",
-	tkTDouble: "double",
-	tkTString: "string",
-	tkTVector: "vector",
-	tkTMap:    "map",
-	tkTArray:  "array",
-
-	tkName: "<name>",
+	TkTInt:    "int",
+	TkTBool:   "bool",
+	TkTShort:  "short",
+	TkTByte:   "byte",
+	TkTLong:   "long",
+	TkTFloat:  "float",
+	TkTDouble: "double",
+	TkTString: "string",
+	TkTVector: "vector",
+	TkTMap:    "map",
+	TkTArray:  "array",
+
+	TkName: "<name>",
 	// value
-	tkString:  "<string>",
-	tkInteger: "<INTEGER>",
-	tkFloat:   "<FLOAT>",
+	TkString:  "<string>",
+	TkInteger: "<INTEGER>",
+	TkFloat:   "<FLOAT>",
 }
 
 // SemInfo is struct.
@@ -163,12 +163,12 @@ func isLetter(b byte) bool {
 }
 
 func isType(t TK) bool {
-	return t > tkDummyTypeBegin && t < tkDummyTypeEnd
+	return t > TkDummyTypeBegin && t < TkDummyTypeEnd
 }
 
 func isNumberType(t TK) bool {
 	switch t {
-	case tkTInt, tkTBool, tkTShort, tkTByte, tkTLong, tkTFloat, tkTDouble:
+	case TkTInt, TkTBool, TkTShort, TkTByte, TkTLong, TkTFloat, TkTDouble:
 		return true
 	default:
 		return false
@@ -211,14 +211,14 @@ func (ls *LexState) readNumber() (TK, *SemInfo) {
 			ls.lexErr(err.Error())
 		}
 		sem.F = f
-		return tkFloat, sem
+		return TkFloat, sem
 	}
 	i, err := strconv.ParseInt(sem.S, 0, 64)
 	if err != nil {
 		ls.lexErr(err.Error())
 	}
 	sem.I = i
-	return tkInteger, sem
+	return TkInteger, sem
 }
 
 func (ls *LexState) readIdent() (TK, *SemInfo) {
@@ -244,18 +244,18 @@ func (ls *LexState) readIdent() (TK, *SemInfo) {
 		}
 	}
 
-	for i := tkDummyKeywordBegin + 1; i < tkDummyKeywordEnd; i++ {
+	for i := TkDummyKeywordBegin + 1; i < TkDummyKeywordEnd; i++ {
 		if TokenMap[i] == sem.S {
 			return i, nil
 		}
 	}
-	for i := tkDummyTypeBegin + 1; i < tkDummyTypeEnd; i++ {
+	for i := TkDummyTypeBegin + 1; i < TkDummyTypeEnd; i++ {
 		if TokenMap[i] == sem.S {
 			return i, nil
 		}
 	}
 
-	return tkName, sem
+	return TkName, sem
 }
 
 func (ls *LexState) readSharp() (TK, *SemInfo) {
@@ -268,7 +268,7 @@ func (ls *LexState) readSharp() (TK, *SemInfo) {
 		ls.lexErr("not #include")
 	}
 
-	return tkInclude, nil
+	return TkInclude, nil
 }
 
 func (ls *LexState) readString() (TK, *SemInfo) {
@@ -288,7 +288,7 @@ func (ls *LexState) readString() (TK, *SemInfo) {
 	}
 	sem.S = ls.tokenBuff.String()
 
-	return tkString, sem
+	return TkString, sem
 }
 
 func (ls *LexState) readLongComment() {
@@ -339,7 +339,7 @@ func (ls *LexState) llex() (TK, *SemInfo) {
 		ls.tokenBuff.Reset()
 		switch ls.current {
 		case EOS:
-			return tkEos, nil
+			return TkEos, nil
 		case ' ', '\t', '\f', '\v':
 			ls.next()
 		case '\n', '\r':
@@ -358,37 +358,37 @@ func (ls *LexState) llex() (TK, *SemInfo) {
 			}
 		case '{':
 			ls.next()
-			return tkBraceLeft, nil
+			return TkBraceLeft, nil
 		case '}':
 			ls.next()
-			return tkBraceRight, nil
+			return TkBraceRight, nil
 		case ';':
 			ls.next()
-			return tkSemi, nil
+			return TkSemi, nil
 		case '=':
 			ls.next()
-			return tkEq, nil
+			return TkEq, nil
 		case '<':
 			ls.next()
-			return tkShl, nil
+			return TkShl, nil
 		case '>':
 			ls.next()
-			return tkShr, nil
+			return TkShr, nil
 		case ',':
 			ls.next()
-			return tkComma, nil
+			return TkComma, nil
 		case '(':
 			ls.next()
-			return tkPtl, nil
+			return TkPtl, nil
 		case ')':
 			ls.next()
-			return tkPtr, nil
+			return TkPtr, nil
 		case '[':
 			ls.next()
-			return tkSquareLeft, nil
+			return TkSquareLeft, nil
 		case ']':
 			ls.next()
-			return tkSquarerRight, nil
+			return TkSquarerRight, nil
 		case '"':
 			return ls.readString()
 		case '#':
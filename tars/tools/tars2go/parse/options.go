@@ -0,0 +1,115 @@
+package parse
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ModuleCycle support jce module cycle include(do not support jce file cycle include)
+	ModuleCycle bool
+	// ModuleUpper native module names are supported, otherwise the first letter of the module name is upper
+	ModuleUpper bool
+	// Includes is the search path of tars protocol
+	Includes []string
+	// Output is where the parser writes its progress messages.
+	Output io.Writer = os.Stdout
+)
+
+// Path2ProtoName returns the proto file name(not include .tars)
+func Path2ProtoName(path string) string {
+	iBegin := strings.LastIndex(path, "/")
+	if iBegin == -1 || iBegin >= len(path)-1 {
+		iBegin = 0
+	} else {
+		iBegin++
+	}
+	iEnd := strings.LastIndex(path, ".tars")
+	if iEnd == -1 {
+		iEnd = len(path)
+	}
+
+	return path[iBegin:iEnd]
+}
+
+// UpperFirstLetter Initial capitalization
+func UpperFirstLetter(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	if len(s) == 1 {
+		return strings.ToUpper(string(s[0]))
+	}
+	return strings.ToUpper(string(s[0])) + s[1:]
+}
+
+// === rename area ===
+// 0. rename module
+
+// Rename the module name
+func (p *Parse) Rename() {
+	p.OriginModule = p.Module
+	if ModuleUpper {
+		p.Module = UpperFirstLetter(p.Module)
+	}
+}
+
+// 1. struct rename
+// struct Name { 1 require Mb type}
+
+// Rename the struct and member names
+func (st *StructInfo) Rename() {
+	st.OriginName = st.Name
+	st.Name = UpperFirstLetter(st.Name)
+	for i := range st.Mb {
+		st.Mb[i].OriginKey = st.Mb[i].Key
+		st.Mb[i].Key = UpperFirstLetter(st.Mb[i].Key)
+	}
+}
+
+// 1. interface rename
+// interface Name { Fun }
+
+// Rename the interface and function names
+func (itf *InterfaceInfo) Rename() {
+	itf.OriginName = itf.Name
+	itf.Name = UpperFirstLetter(itf.Name)
+	for i := range itf.Fun {
+		itf.Fun[i].Rename()
+	}
+}
+
+// Rename the enum and member names
+func (en *EnumInfo) Rename() {
+	en.OriginName = en.Name
+	en.Name = UpperFirstLetter(en.Name)
+	for i := range en.Mb {
+		en.Mb[i].Key = UpperFirstLetter(en.Mb[i].Key)
+	}
+}
+
+// Rename the const name
+func (cst *ConstInfo) Rename() {
+	cst.OriginName = cst.Name
+	cst.Name = UpperFirstLetter(cst.Name)
+}
+
+// 2. func rename
+// type Fun (arg ArgType), in case keyword and name conflicts,argname need to capitalize.
+// Fun (type int32)
+
+// Rename the function name
+func (fun *FunInfo) Rename() {
+	fun.OriginName = fun.Name
+	fun.Name = UpperFirstLetter(fun.Name)
+	for i := range fun.Args {
+		fun.Args[i].OriginName = fun.Args[i].Name
+		// func args donot upper firs
+		//fun.Args[i].Name = upperFirstLetter(fun.Args[i].Name)
+	}
+}
+
+// 3. genType rename all Type
+
+// === rename end ===
@@ -1,4 +1,4 @@
-package main
+package parse
 
 import (
 	"fmt"
@@ -14,8 +14,8 @@ import (
 type VarType struct {
 	Type     TK       // basic type
 	Unsigned bool     // whether unsigned
-	TypeSt   string   // custom type name, such as an enumerated struct,at this time Type=tkName
-	CType    TK       // make sure which type of custom type is,tkEnum, tkStruct
+	TypeSt   string   // custom type name, such as an enumerated struct,at this time Type=TkName
+	CType    TK       // make sure which type of custom type is,TkEnum, TkStruct
 	TypeK    *VarType // vector's member variable,the key of map
 	TypeV    *VarType // the value of map
 	TypeL    int64    // length of array
@@ -56,7 +56,7 @@ type ArgInfo struct {
 	Type       *VarType
 }
 
-//FunInfo record function information.
+// FunInfo record function information.
 type FunInfo struct {
 	Name       string // after the uppercase converted name
 	OriginName string // original name
@@ -158,7 +158,7 @@ func (p *Parse) expect(t TK) {
 
 func (p *Parse) makeUnsigned(utype *VarType) {
 	switch utype.Type {
-	case tkTInt, tkTShort, tkTByte:
+	case TkTInt, TkTShort, TkTByte:
 		utype.Unsigned = true
 	default:
 		p.parseErr("type " + TokenMap[utype.Type] + " unsigned decoration is not supported")
@@ -169,24 +169,24 @@ func (p *Parse) parseType() *VarType {
 	vtype := &VarType{Type: p.t.T}
 
 	switch vtype.Type {
-	case tkName:
+	case TkName:
 		vtype.TypeSt = p.t.S.S
-	case tkTInt, tkTBool, tkTShort, tkTLong, tkTByte, tkTFloat, tkTDouble, tkTString:
+	case TkTInt, TkTBool, TkTShort, TkTLong, TkTByte, TkTFloat, TkTDouble, TkTString:
 		// no nothing
-	case tkTVector:
-		p.expect(tkShl)
+	case TkTVector:
+		p.expect(TkShl)
 		p.next()
 		vtype.TypeK = p.parseType()
-		p.expect(tkShr)
-	case tkTMap:
-		p.expect(tkShl)
+		p.expect(TkShr)
+	case TkTMap:
+		p.expect(TkShl)
 		p.next()
 		vtype.TypeK = p.parseType()
-		p.expect(tkComma)
+		p.expect(TkComma)
 		p.next()
 		vtype.TypeV = p.parseType()
-		p.expect(tkShr)
-	case tkUnsigned:
+		p.expect(TkShr)
+	case TkUnsigned:
 		p.next()
 		utype := p.parseType()
 		p.makeUnsigned(utype)
@@ -199,88 +199,88 @@ func (p *Parse) parseType() *VarType {
 
 func (p *Parse) parseEnum() {
 	enum := EnumInfo{}
-	p.expect(tkName)
+	p.expect(TkName)
 	enum.Name = p.t.S.S
 	for _, v := range p.Enum {
 		if v.Name == enum.Name {
 			p.parseErr(enum.Name + " Redefine.")
 		}
 	}
-	p.expect(tkBraceLeft)
+	p.expect(TkBraceLeft)
 
 LFOR:
 	for {
 		p.next()
 		switch p.t.T {
-		case tkBraceRight:
+		case TkBraceRight:
 			break LFOR
-		case tkName:
+		case TkName:
 			k := p.t.S.S
 			p.next()
 			switch p.t.T {
-			case tkComma:
+			case TkComma:
 				m := EnumMember{Key: k, Type: 2}
 				enum.Mb = append(enum.Mb, m)
-			case tkBraceRight:
+			case TkBraceRight:
 				m := EnumMember{Key: k, Type: 2}
 				enum.Mb = append(enum.Mb, m)
 				break LFOR
-			case tkEq:
+			case TkEq:
 				p.next()
 				switch p.t.T {
-				case tkInteger:
+				case TkInteger:
 					m := EnumMember{Key: k, Value: int32(p.t.S.I)}
 					enum.Mb = append(enum.Mb, m)
-				case tkName:
+				case TkName:
 					m := EnumMember{Key: k, Type: 1, Name: p.t.S.S}
 					enum.Mb = append(enum.Mb, m)
 				default:
 					p.parseErr("not expect " + TokenMap[p.t.T])
 				}
 				p.next()
-				if p.t.T == tkBraceRight {
+				if p.t.T == TkBraceRight {
 					break LFOR
-				} else if p.t.T == tkComma {
+				} else if p.t.T == TkComma {
 				} else {
 					p.parseErr("expect , or }")
 				}
 			}
 		}
 	}
-	p.expect(tkSemi)
+	p.expect(TkSemi)
 	p.Enum = append(p.Enum, enum)
 }
 
 func (p *Parse) parseStructMemberDefault(m *StructMember) {
 	m.DefType = p.t.T
 	switch p.t.T {
-	case tkInteger:
-		if !isNumberType(m.Type.Type) && m.Type.Type != tkName {
+	case TkInteger:
+		if !isNumberType(m.Type.Type) && m.Type.Type != TkName {
 			// enum auto defined type ,default value is number.
 			p.parseErr("type does not accept number")
 		}
 		m.Default = p.t.S.S
-	case tkFloat:
+	case TkFloat:
 		if !isNumberType(m.Type.Type) {
 			p.parseErr("type does not accept number")
 		}
 		m.Default = p.t.S.S
-	case tkString:
+	case TkString:
 		if isNumberType(m.Type.Type) {
 			p.parseErr("type does not accept string")
 		}
 		m.Default = `"` + p.t.S.S + `"`
-	case tkTrue:
-		if m.Type.Type != tkTBool {
+	case TkTrue:
+		if m.Type.Type != TkTBool {
 			p.parseErr("default value format error")
 		}
 		m.Default = "true"
-	case tkFalse:
-		if m.Type.Type != tkTBool {
+	case TkFalse:
+		if m.Type.Type != TkTBool {
 			p.parseErr("default value format error")
 		}
 		m.Default = "false"
-	case tkName:
+	case TkName:
 		m.Default = p.t.S.S
 	default:
 		p.parseErr("default value format error")
@@ -290,10 +290,10 @@ func (p *Parse) parseStructMemberDefault(m *StructMember) {
 func (p *Parse) parseStructMember() *StructMember {
 	// tag or end
 	p.next()
-	if p.t.T == tkBraceRight {
+	if p.t.T == TkBraceRight {
 		return nil
 	}
-	if p.t.T != tkInteger {
+	if p.t.T != TkInteger {
 		p.parseErr("expect tags.")
 	}
 	m := &StructMember{}
@@ -301,9 +301,9 @@ func (p *Parse) parseStructMember() *StructMember {
 
 	// require or optional
 	p.next()
-	if p.t.T == tkRequire {
+	if p.t.T == TkRequire {
 		m.Require = true
-	} else if p.t.T == tkOptional {
+	} else if p.t.T == TkOptional {
 		m.Require = false
 	} else {
 		p.parseErr("expect require or optional")
@@ -311,38 +311,38 @@ func (p *Parse) parseStructMember() *StructMember {
 
 	// type
 	p.next()
-	if !isType(p.t.T) && p.t.T != tkName && p.t.T != tkUnsigned {
+	if !isType(p.t.T) && p.t.T != TkName && p.t.T != TkUnsigned {
 		p.parseErr("expect type")
 	} else {
 		m.Type = p.parseType()
 	}
 
 	// key
-	p.expect(tkName)
+	p.expect(TkName)
 	m.Key = p.t.S.S
 
 	p.next()
-	if p.t.T == tkSemi {
+	if p.t.T == TkSemi {
 		return m
 	}
-	if p.t.T == tkSquareLeft {
-		p.expect(tkInteger)
-		m.Type = &VarType{Type: tkTArray, TypeK: m.Type, TypeL: p.t.S.I}
-		p.expect(tkSquarerRight)
-		p.expect(tkSemi)
+	if p.t.T == TkSquareLeft {
+		p.expect(TkInteger)
+		m.Type = &VarType{Type: TkTArray, TypeK: m.Type, TypeL: p.t.S.I}
+		p.expect(TkSquarerRight)
+		p.expect(TkSemi)
 		return m
 	}
-	if p.t.T != tkEq {
+	if p.t.T != TkEq {
 		p.parseErr("expect ; or =")
 	}
-	if p.t.T == tkTMap || p.t.T == tkTVector || p.t.T == tkName {
+	if p.t.T == TkTMap || p.t.T == TkTVector || p.t.T == TkName {
 		p.parseErr("map, vector, custom type cannot set default value")
 	}
 
 	// default
 	p.next()
 	p.parseStructMemberDefault(m)
-	p.expect(tkSemi)
+	p.expect(TkSemi)
 
 	return m
 }
@@ -363,14 +363,14 @@ func (p *Parse) sortTag(st *StructInfo) {
 
 func (p *Parse) parseStruct() {
 	st := StructInfo{}
-	p.expect(tkName)
+	p.expect(TkName)
 	st.Name = p.t.S.S
 	for _, v := range p.Struct {
 		if v.Name == st.Name {
 			p.parseErr(st.Name + " Redefine.")
 		}
 	}
-	p.expect(tkBraceLeft)
+	p.expect(TkBraceLeft)
 
 	for {
 		m := p.parseStructMember()
@@ -379,7 +379,7 @@ func (p *Parse) parseStruct() {
 		}
 		st.Mb = append(st.Mb, *m)
 	}
-	p.expect(tkSemi) //semicolon at the end of the struct.
+	p.expect(TkSemi) //semicolon at the end of the struct.
 
 	p.checkTag(&st)
 	p.sortTag(&st)
@@ -390,35 +390,35 @@ func (p *Parse) parseStruct() {
 func (p *Parse) parseInterfaceFun() *FunInfo {
 	fun := &FunInfo{}
 	p.next()
-	if p.t.T == tkBraceRight {
+	if p.t.T == TkBraceRight {
 		return nil
 	}
-	if p.t.T == tkVoid {
+	if p.t.T == TkVoid {
 		fun.HasRet = false
-	} else if !isType(p.t.T) && p.t.T != tkName && p.t.T != tkUnsigned {
+	} else if !isType(p.t.T) && p.t.T != TkName && p.t.T != TkUnsigned {
 		p.parseErr("expect type")
 	} else {
 		fun.HasRet = true
 		fun.RetType = p.parseType()
 	}
-	p.expect(tkName)
+	p.expect(TkName)
 	fun.Name = p.t.S.S
-	p.expect(tkPtl)
+	p.expect(TkPtl)
 
 	p.next()
-	if p.t.T == tkShr {
+	if p.t.T == TkShr {
 		return fun
 	}
 
 	// No parameter function, exit directly.
-	if p.t.T == tkPtr {
-		p.expect(tkSemi)
+	if p.t.T == TkPtr {
+		p.expect(TkSemi)
 		return fun
 	}
 
 	for {
 		arg := &ArgInfo{}
-		if p.t.T == tkOut {
+		if p.t.T == TkOut {
 			arg.IsOut = true
 			p.next()
 		} else {
@@ -427,17 +427,17 @@ func (p *Parse) parseInterfaceFun() *FunInfo {
 
 		arg.Type = p.parseType()
 		p.next()
-		if p.t.T == tkName {
+		if p.t.T == TkName {
 			arg.Name = p.t.S.S
 			p.next()
 		}
 
 		fun.Args = append(fun.Args, *arg)
 
-		if p.t.T == tkComma {
+		if p.t.T == TkComma {
 			p.next()
-		} else if p.t.T == tkPtr {
-			p.expect(tkSemi)
+		} else if p.t.T == TkPtr {
+			p.expect(TkSemi)
 			break
 		} else {
 			p.parseErr("expect , or )")
@@ -448,14 +448,14 @@ func (p *Parse) parseInterfaceFun() *FunInfo {
 
 func (p *Parse) parseInterface() {
 	itf := &InterfaceInfo{}
-	p.expect(tkName)
+	p.expect(TkName)
 	itf.Name = p.t.S.S
 	for _, v := range p.Interface {
 		if v.Name == itf.Name {
 			p.parseErr(itf.Name + " Redefine.")
 		}
 	}
-	p.expect(tkBraceLeft)
+	p.expect(TkBraceLeft)
 
 	for {
 		fun := p.parseInterfaceFun()
@@ -464,7 +464,7 @@ func (p *Parse) parseInterface() {
 		}
 		itf.Fun = append(itf.Fun, *fun)
 	}
-	p.expect(tkSemi) //semicolon at the end of struct.
+	p.expect(TkSemi) //semicolon at the end of struct.
 	p.Interface = append(p.Interface, *itf)
 }
 
@@ -474,69 +474,69 @@ func (p *Parse) parseConst() {
 	// type
 	p.next()
 	switch p.t.T {
-	case tkTVector, tkTMap:
+	case TkTVector, TkTMap:
 		p.parseErr("const no supports type vector or map.")
-	case tkTBool, tkTByte, tkTShort,
-		tkTInt, tkTLong, tkTFloat,
-		tkTDouble, tkTString, tkUnsigned:
+	case TkTBool, TkTByte, TkTShort,
+		TkTInt, TkTLong, TkTFloat,
+		TkTDouble, TkTString, TkUnsigned:
 		m.Type = p.parseType()
 	default:
 		p.parseErr("expect type.")
 	}
 
-	p.expect(tkName)
+	p.expect(TkName)
 	m.Name = p.t.S.S
 
-	p.expect(tkEq)
+	p.expect(TkEq)
 
 	// default
 	p.next()
 	switch p.t.T {
-	case tkInteger, tkFloat:
+	case TkInteger, TkFloat:
 		if !isNumberType(m.Type.Type) {
 			p.parseErr("type does not accept number")
 		}
 		m.Value = p.t.S.S
-	case tkString:
+	case TkString:
 		if isNumberType(m.Type.Type) {
 			p.parseErr("type does not accept string")
 		}
 		m.Value = `"` + p.t.S.S + `"`
-	case tkTrue:
-		if m.Type.Type != tkTBool {
+	case TkTrue:
+		if m.Type.Type != TkTBool {
 			p.parseErr("default value format error")
 		}
 		m.Value = "true"
-	case tkFalse:
-		if m.Type.Type != tkTBool {
+	case TkFalse:
+		if m.Type.Type != TkTBool {
 			p.parseErr("default value format error")
 		}
 		m.Value = "false"
 	default:
 		p.parseErr("default value format error")
 	}
-	p.expect(tkSemi)
+	p.expect(TkSemi)
 
 	p.Const = append(p.Const, m)
 }
 
 func (p *Parse) parseHashKey() {
 	hashKey := HashKeyInfo{}
-	p.expect(tkSquareLeft)
-	p.expect(tkName)
+	p.expect(TkSquareLeft)
+	p.expect(TkName)
 	hashKey.Name = p.t.S.S
-	p.expect(tkComma)
+	p.expect(TkComma)
 	for {
-		p.expect(tkName)
+		p.expect(TkName)
 		hashKey.Member = append(hashKey.Member, p.t.S.S)
 		p.next()
 		t := p.t
 		switch t.T {
-		case tkSquarerRight:
-			p.expect(tkSemi)
+		case TkSquarerRight:
+			p.expect(TkSemi)
 			p.HashKey = append(p.HashKey, hashKey)
 			return
-		case tkComma:
+		case TkComma:
 		default:
 			p.parseErr("expect ] or ,")
 		}
@@ -544,24 +544,24 @@ func (p *Parse) parseHashKey() {
 }
 
 func (p *Parse) parseModuleSegment() {
-	p.expect(tkBraceLeft)
+	p.expect(TkBraceLeft)
 
 	for {
 		p.next()
 		t := p.t
 		switch t.T {
-		case tkBraceRight:
-			p.expect(tkSemi)
+		case TkBraceRight:
+			p.expect(TkSemi)
 			return
-		case tkConst:
+		case TkConst:
 			p.parseConst()
-		case tkEnum:
+		case TkEnum:
 			p.parseEnum()
-		case tkStruct:
+		case TkStruct:
 			p.parseStruct()
-		case tkInterface:
+		case TkInterface:
 			p.parseInterface()
-		case tkKey:
+		case TkKey:
 			p.parseHashKey()
 		default:
 			p.parseErr("not except " + TokenMap[t.T])
@@ -570,7 +570,7 @@ func (p *Parse) parseModuleSegment() {
 }
 
 func (p *Parse) parseModule() {
-	p.expect(tkName)
+	p.expect(TkName)
 
 	if p.Module != "" {
 		// 解决一个tars文件中定义多个module
@@ -610,7 +610,7 @@ func (p *Parse) parseModule() {
 }
 
 func (p *Parse) parseInclude() {
-	p.expect(tkString)
+	p.expect(TkString)
 	p.Include = append(p.Include, p.t.S.S)
 }
 
@@ -618,24 +618,24 @@ func (p *Parse) parseInclude() {
 func (p *Parse) findTNameType(tname string) (TK, string, string) {
 	for _, v := range p.Struct {
 		if p.Module+"::"+v.Name == tname {
-			return tkStruct, p.Module, p.ProtoName
+			return TkStruct, p.Module, p.ProtoName
 		}
 	}
 
 	for _, v := range p.Enum {
 		if p.Module+"::"+v.Name == tname {
-			return tkEnum, p.Module, p.ProtoName
+			return TkEnum, p.Module, p.ProtoName
 		}
 	}
 
 	for _, pInc := range p.IncParse {
 		ret, mod, protoName := pInc.findTNameType(tname)
-		if ret != tkName {
+		if ret != TkName {
 			return ret, mod, protoName
 		}
 	}
 	// not find
-	return tkName, p.Module, p.ProtoName
+	return TkName, p.Module, p.ProtoName
 }
 
 func (p *Parse) findEnumName(ename string) (*EnumMember, *EnumInfo) {
@@ -669,7 +669,7 @@ func (p *Parse) findEnumName(ename string) (*EnumMember, *EnumInfo) {
 		}
 	}
 	if cenum != nil && cenum.Module == "" {
-		if *gModuleCycle == true {
+		if ModuleCycle {
 			cenum.Module = p.ProtoName + "_" + p.Module
 		} else {
 			cenum.Module = p.Module
@@ -693,7 +693,7 @@ func addToMap(m *map[string]string, module string, value string) {
 }
 
 func (p *Parse) checkDepTName(ty *VarType, dm *map[string]bool, dmj *map[string]string) {
-	if ty.Type == tkName {
+	if ty.Type == TkName {
 		name := ty.TypeSt
 		if strings.Count(name, "::") == 0 {
 			name = p.Module + "::" + name
@@ -702,14 +702,14 @@ func (p *Parse) checkDepTName(ty *VarType, dm *map[string]bool, dmj *map[string]
 		mod := ""
 		protoName := ""
 		ty.CType, mod, protoName = p.findTNameType(name)
-		if ty.CType == tkName {
+		if ty.CType == TkName {
 			p.parseErr(ty.TypeSt + " not find define")
 		}
-		if *gModuleCycle == true {
+		if ModuleCycle {
 			if mod != p.Module || protoName != p.ProtoName {
 				var modStr string
-				if *gModuleUpper {
-					modStr = upperFirstLetter(mod)
+				if ModuleUpper {
+					modStr = UpperFirstLetter(mod)
 				} else {
 					modStr = mod
 				}
@@ -732,9 +732,9 @@ func (p *Parse) checkDepTName(ty *VarType, dm *map[string]bool, dmj *map[string]
 				ty.TypeSt = strings.Replace(ty.TypeSt, mod+"::", "", 1)
 			}
 		}
-	} else if ty.Type == tkTVector {
+	} else if ty.Type == TkTVector {
 		p.checkDepTName(ty.TypeK, dm, dmj)
-	} else if ty.Type == tkTMap {
+	} else if ty.Type == TkTMap {
 		p.checkDepTName(ty.TypeK, dm, dmj)
 		p.checkDepTName(ty.TypeV, dm, dmj)
 	}
@@ -765,14 +765,14 @@ func (p *Parse) analyzeTName() {
 func (p *Parse) analyzeDefault() {
 	for _, v := range p.Struct {
 		for i, r := range v.Mb {
-			if r.Default != "" && r.DefType == tkName {
+			if r.Default != "" && r.DefType == TkName {
 				mb, enum := p.findEnumName(r.Default)
 				if mb == nil || enum == nil {
 					p.parseErr("can not find default value" + r.Default)
 				}
-				defValue := enum.Name + "_" + upperFirstLetter(mb.Key)
+				defValue := enum.Name + "_" + UpperFirstLetter(mb.Key)
 				var currModule string
-				if *gModuleCycle == true {
+				if ModuleCycle {
 					currModule = p.ProtoName + "_" + p.Module
 				} else {
 					currModule = p.Module
@@ -797,7 +797,7 @@ func (p *Parse) analyzeDepend() {
 		dependFile := relativePath + "/" + v
 		pInc := ParseFile(dependFile, p.IncChain)
 		p.IncParse = append(p.IncParse, pInc)
-		fmt.Println("parse include: ", v)
+		fmt.Fprintln(Output, "parse include: ", v)
 	}
 
 	p.analyzeDefault()
@@ -811,11 +811,11 @@ OUT:
 		p.next()
 		t := p.t
 		switch t.T {
-		case tkEos:
+		case TkEos:
 			break OUT
-		case tkInclude:
+		case TkInclude:
 			p.parseInclude()
-		case tkModule:
+		case TkModule:
 			p.parseModule()
 		default:
 			p.parseErr("Expect include or module.")
@@ -825,7 +825,7 @@ OUT:
 }
 
 func newParse(s string, b []byte, incChain []string) *Parse {
-	p := &Parse{Source: s, ProtoName: path2ProtoName(s)}
+	p := &Parse{Source: s, ProtoName: Path2ProtoName(s)}
 	for _, v := range incChain {
 		if s == v {
 			panic("jce circular reference: " + s)
@@ -833,7 +833,7 @@ func newParse(s string, b []byte, incChain []string) *Parse {
 	}
 	incChain = append(incChain, s)
 	p.IncChain = incChain
-	fmt.Println(s, p.IncChain)
+	fmt.Fprintln(Output, s, p.IncChain)
 
 	p.lex = NewLexState(s, b)
 	p.fileNames = map[string]bool{}
@@ -845,7 +845,7 @@ func ParseFile(filePath string, incChain []string) *Parse {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		// 查找tars文件路径
 		filename := path.Base(filePath)
-		for _, include := range includes {
+		for _, include := range Includes {
 			include = strings.TrimRight(include, "/")
 			filePath = include + "/" + filename
 			if _, err = os.Stat(filePath); err == nil {
@@ -855,7 +855,7 @@ func ParseFile(filePath string, incChain []string) *Parse {
 	}
 	b, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		fmt.Println("file read error: " + filePath + ". " + err.Error())
+		fmt.Fprintln(Output, "file read error: "+filePath+". "+err.Error())
 	}
 
 	p := newParse(filePath, b, incChain)
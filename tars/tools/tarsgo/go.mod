@@ -4,9 +4,20 @@ go 1.16
 
 require (
 	github.com/AlecAivazis/survey/v2 v2.2.15
+	github.com/TarsCloud/TarsGo v1.4.6
+	github.com/TarsCloud/TarsGo/tars/tools/tars2go v1.4.6
 	github.com/fatih/color v1.13.0
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/spf13/cobra v1.3.0
 	golang.org/x/mod v0.5.0
 	golang.org/x/sys v0.1.0 // indirect
 )
+
+// the commands use the framework and tars2go in this repository, which are newer than the tagged versions
+replace (
+	github.com/TarsCloud/TarsGo => ../../../
+	github.com/TarsCloud/TarsGo/tars/tools/tars2go => ../tars2go
+	// keep the versions used by the framework module
+	golang.org/x/crypto => golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
+	golang.org/x/net => golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
+)
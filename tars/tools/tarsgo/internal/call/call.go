@@ -0,0 +1,127 @@
+package call
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/TarsCloud/TarsGo/tars"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/basef"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/schema"
+	"github.com/TarsCloud/TarsGo/tars/util/tools"
+	"github.com/spf13/cobra"
+)
+
+// CmdNew represents the call command.
+var CmdNew = &cobra.Command{
+	Use:   "call Servant Method [Arguments]",
+	Short: "Call a method of a tars servant",
+	Long: `Call a method of a tars servant, the arguments are given as a json object by the argument
+names or a json array of the input arguments, "-" reads them from stdin. The return value and
+the output arguments are printed as json. Example:
+tarsgo call --tars Hello.tars "TestApp.HelloGo.SayHelloObj@tcp -h 127.0.0.1 -p 10015" Add '{"a":1,"b":2}'
+tarsgo call --tars Hello.tars --locator "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890" TestApp.HelloGo.SayHelloObj Add '[1,2]'`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: run,
+}
+
+var (
+	tarsFiles []string
+	includes  []string
+	itf       string
+	locator   string
+	timeout   int
+	reqCtx    map[string]string
+	oneway    bool
+)
+
+func init() {
+	CmdNew.Flags().StringSliceVarP(&tarsFiles, "tars", "t", nil, "tars files which define the method")
+	CmdNew.Flags().StringSliceVarP(&includes, "include", "I", nil, "search path of the included tars files")
+	CmdNew.Flags().StringVarP(&itf, "interface", "i", "", "interface of the method, required if the method name is ambiguous")
+	CmdNew.Flags().StringVarP(&locator, "locator", "l", "", "registry to find the servant without endpoints")
+	CmdNew.Flags().IntVar(&timeout, "timeout", 3000, "invoke timeout in milliseconds")
+	CmdNew.Flags().StringToStringVarP(&reqCtx, "context", "c", nil, "request context, such as key1=value1,key2=value2")
+	CmdNew.Flags().BoolVar(&oneway, "oneway", false, "call without waiting for the response")
+	_ = CmdNew.MarkFlagRequired("tars")
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	servant, method := args[0], args[1]
+	s, err := schema.Load(includes, tarsFiles...)
+	if err != nil {
+		return err
+	}
+	fun, err := s.Function(itf, method)
+	if err != nil {
+		return err
+	}
+	input, err := readArgs(args[2:])
+	if err != nil {
+		return err
+	}
+	buf, err := s.EncodeArgs(fun, input)
+	if err != nil {
+		return err
+	}
+
+	var opts []tars.Option
+	if locator != "" {
+		opts = append(opts, tars.WithLocator(locator))
+	} else if !strings.Contains(servant, "@") {
+		return fmt.Errorf("servant %s has no endpoint, set it as Servant@tcp -h host -p port or use --locator", servant)
+	}
+	// the isolated application does not parse the command line of this tool for the --config flag
+	comm := tars.NewApplication(opts...).Communicator()
+	sp := tars.NewServantProxy(comm, servant)
+	sp.TarsSetTimeout(timeout)
+
+	var cType byte
+	if oneway {
+		cType = byte(basef.TARSONEWAY)
+	}
+	resp := &requestf.ResponsePacket{}
+	if err = sp.TarsInvoke(context.Background(), cType, method, buf, nil, reqCtx, resp); err != nil {
+		return err
+	}
+	if oneway {
+		return nil
+	}
+
+	out, err := s.DecodeArgs(tools.Int8ToByte(resp.SBuffer), fun, true)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n", b)
+	return nil
+}
+
+func readArgs(args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	data := []byte(args[0])
+	if args[0] == "-" {
+		var err error
+		if data, err = ioutil.ReadAll(os.Stdin); err != nil {
+			return nil, err
+		}
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid json arguments: %v", err)
+	}
+	return v, nil
+}
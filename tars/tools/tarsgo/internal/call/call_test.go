@@ -0,0 +1,111 @@
+package call
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/util/tools"
+)
+
+const testTars = `
+module Test {
+	interface Hello {
+		int Add(int a, int b, out int product);
+	};
+};
+`
+
+// serveAdd serves Add of Test::Hello on the connections of ln, which returns a+b and outputs a*b.
+func serveAdd(ln net.Listener, requests chan<- *requestf.RequestPacket) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				head := make([]byte, 4)
+				if _, err := io.ReadFull(conn, head); err != nil {
+					return
+				}
+				pkg := make([]byte, binary.BigEndian.Uint32(head))
+				if _, err := io.ReadFull(conn, pkg[4:]); err != nil {
+					return
+				}
+				req := &requestf.RequestPacket{}
+				if err := req.ReadFrom(codec.NewReader(pkg[4:])); err != nil {
+					return
+				}
+				requests <- req
+				var a, b int32
+				is := codec.NewReader(tools.Int8ToByte(req.SBuffer))
+				is.ReadInt32(&a, 1, true)
+				is.ReadInt32(&b, 2, true)
+				buf := codec.NewBuffer()
+				buf.WriteInt32(a+b, 0)
+				buf.WriteInt32(a*b, 3)
+				rsp := requestf.ResponsePacket{
+					IVersion:   req.IVersion,
+					IRequestId: req.IRequestId,
+					SBuffer:    tools.ByteToInt8(buf.ToBytes()),
+				}
+				out := codec.NewBuffer()
+				rsp.WriteTo(out)
+				b4 := make([]byte, 4)
+				binary.BigEndian.PutUint32(b4, uint32(out.Len()+4))
+				conn.Write(append(b4, out.ToBytes()...))
+			}
+		}()
+	}
+}
+
+func TestCall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "call")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "Hello.tars")
+	if err = ioutil.WriteFile(file, []byte(testTars), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	requests := make(chan *requestf.RequestPacket, 1)
+	go serveAdd(ln, requests)
+	servant := fmt.Sprintf("Test.HelloServer.HelloObj@tcp -h 127.0.0.1 -p %d -t 60000", ln.Addr().(*net.TCPAddr).Port)
+
+	var out bytes.Buffer
+	CmdNew.SetOut(&out)
+	CmdNew.SetArgs([]string{"--tars", file, "--context", "k=v", servant, "Add", `{"a":3,"b":4}`})
+	if err = CmdNew.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	req := <-requests
+	if req.SServantName != "Test.HelloServer.HelloObj" || req.SFuncName != "Add" || req.Context["k"] != "v" {
+		t.Errorf("got request of %s.%s with context %v", req.SServantName, req.SFuncName, req.Context)
+	}
+	want := "{\n  \"ret\": 7,\n  \"product\": 12\n}\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+
+	// the servant without endpoint
+	CmdNew.SetArgs([]string{"--tars", file, "Test.HelloServer.HelloObj", "Add", `[3,4]`})
+	if err = CmdNew.Execute(); err == nil {
+		t.Error("got nil error for the servant without endpoint")
+	}
+}
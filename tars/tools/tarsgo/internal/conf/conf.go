@@ -0,0 +1,57 @@
+package conf
+
+import (
+	"fmt"
+
+	"github.com/TarsCloud/TarsGo/tars/util/conf"
+	"github.com/spf13/cobra"
+)
+
+// CmdNew represents the conf command.
+var CmdNew = &cobra.Command{
+	Use:   "conf",
+	Short: "Tools of the tars config files",
+	Long:  `Tools of the tars config files.`,
+}
+
+// cmdLint represents the conf lint command.
+var cmdLint = &cobra.Command{
+	Use:   "lint file...",
+	Short: "Check the tars config files",
+	Long: `Check the tars config files for the malformed xml, the missing includes, the undefined
+variables and the unknown keys of the server config, the issues are printed with the line numbers. Example:
+tarsgo conf lint TestApp.HelloGo.config.conf
+tarsgo conf lint --keys=false app.conf`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLint,
+}
+
+var checkKeys bool
+
+func init() {
+	cmdLint.Flags().BoolVar(&checkKeys, "keys", true, "report the unknown keys of the server config")
+	CmdNew.AddCommand(cmdLint)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	var keys map[string][]string
+	if checkKeys {
+		keys = conf.TarsKeys()
+	}
+	count := 0
+	for _, file := range args {
+		issues, err := conf.Lint(file, keys)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(cmd.OutOrStdout(), issue)
+		}
+		count += len(issues)
+	}
+	if count > 0 {
+		return fmt.Errorf("found %d issue(s)", count)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package inspect
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+)
+
+var typeNames = map[byte]string{
+	codec.BYTE:        "Byte",
+	codec.SHORT:       "Short",
+	codec.INT:         "Int",
+	codec.LONG:        "Long",
+	codec.FLOAT:       "Float",
+	codec.DOUBLE:      "Double",
+	codec.STRING1:     "String1",
+	codec.STRING4:     "String4",
+	codec.MAP:         "Map",
+	codec.LIST:        "List",
+	codec.StructBegin: "StructBegin",
+	codec.StructEnd:   "StructEnd",
+	codec.ZeroTag:     "ZeroTag",
+	codec.SimpleList:  "SimpleList",
+}
+
+// Node is a field decoded without schema.
+type Node struct {
+	Tag      byte        `json:"tag"`
+	Type     string      `json:"type"`
+	Value    interface{} `json:"value"`
+	Children []*Node     `json:"children,omitempty"`
+}
+
+// Dump decodes all the fields in data without schema.
+func Dump(data []byte) ([]*Node, error) {
+	r := codec.NewReader(data)
+	var nodes []*Node
+	for {
+		ty, tag, err := r.ReadHead()
+		if err == io.EOF {
+			return nodes, nil
+		}
+		if err != nil {
+			return nodes, err
+		}
+		node, err := readNode(r, ty, tag)
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+		if err != nil {
+			return nodes, err
+		}
+	}
+}
+
+func readNode(r *codec.Reader, ty, tag byte) (*Node, error) {
+	name, ok := typeNames[ty]
+	if !ok {
+		return nil, fmt.Errorf("invalid type %d of tag %d", ty, tag)
+	}
+	node := &Node{Tag: tag, Type: name}
+	var err error
+	switch ty {
+	case codec.BYTE, codec.SHORT, codec.INT, codec.LONG, codec.ZeroTag:
+		var v int64
+		r.UnreadHead(tag)
+		err = r.ReadInt64(&v, tag, true)
+		node.Value = v
+	case codec.FLOAT, codec.DOUBLE:
+		var v float64
+		r.UnreadHead(tag)
+		err = r.ReadFloat64(&v, tag, true)
+		node.Value = v
+	case codec.STRING1, codec.STRING4:
+		var v string
+		r.UnreadHead(tag)
+		err = r.ReadString(&v, tag, true)
+		node.Value = v
+	case codec.MAP, codec.LIST:
+		var length int32
+		if err = r.ReadInt32(&length, 0, true); err != nil {
+			break
+		}
+		if length < 0 || int(length) > r.Len() {
+			err = fmt.Errorf("invalid length %d of tag %d", length, tag)
+			break
+		}
+		node.Value = length
+		count := int(length)
+		if ty == codec.MAP {
+			count *= 2
+		}
+		for i := 0; i < count && err == nil; i++ {
+			var child *Node
+			if child, err = readChild(r); child != nil {
+				node.Children = append(node.Children, child)
+			}
+		}
+	case codec.SimpleList:
+		var length int32
+		if _, _, err = r.ReadHead(); err != nil {
+			break
+		}
+		if err = r.ReadInt32(&length, 0, true); err != nil {
+			break
+		}
+		if length < 0 || int(length) > r.Len() {
+			err = fmt.Errorf("invalid length %d of tag %d", length, tag)
+			break
+		}
+		data := r.Next(int(length))
+		node.Value = hex.EncodeToString(data)
+		// the bytes are often an encoded struct, such as the sBuffer of the request packet
+		if children, err := Dump(data); err == nil && len(children) > 0 {
+			node.Children = children
+		}
+	case codec.StructBegin:
+		for {
+			var child *Node
+			child, err = readChild(r)
+			if err != nil || child == nil {
+				break
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node, err
+}
+
+// readChild reads a field inside a map, list or struct, it returns nil at the end of a struct.
+func readChild(r *codec.Reader) (*Node, error) {
+	ty, tag, err := r.ReadHead()
+	if err != nil {
+		return nil, err
+	}
+	if ty == codec.StructEnd {
+		return nil, nil
+	}
+	return readNode(r, ty, tag)
+}
+
+// Print writes the nodes as an indented tree.
+func Print(w io.Writer, nodes []*Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, node := range nodes {
+		switch node.Type {
+		case "Map":
+			fmt.Fprintf(w, "%s%d (%s): %v entries\n", indent, node.Tag, node.Type, node.Value)
+		case "List":
+			fmt.Fprintf(w, "%s%d (%s): %v elements\n", indent, node.Tag, node.Type, node.Value)
+		case "SimpleList":
+			data, _ := node.Value.(string)
+			fmt.Fprintf(w, "%s%d (%s): %d bytes %s\n", indent, node.Tag, node.Type, len(data)/2, data)
+		case "StructBegin":
+			fmt.Fprintf(w, "%s%d (Struct)\n", indent, node.Tag)
+		case "String1", "String4":
+			fmt.Fprintf(w, "%s%d (%s): %q\n", indent, node.Tag, node.Type, node.Value)
+		default:
+			fmt.Fprintf(w, "%s%d (%s): %v\n", indent, node.Tag, node.Type, node.Value)
+		}
+		Print(w, node.Children, depth+1)
+	}
+}
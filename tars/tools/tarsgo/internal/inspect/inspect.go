@@ -0,0 +1,365 @@
+package inspect
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/basef"
+	"github.com/TarsCloud/TarsGo/tars/protocol/res/requestf"
+	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/schema"
+	"github.com/TarsCloud/TarsGo/tars/util/tools"
+	"github.com/spf13/cobra"
+)
+
+// CmdNew represents the inspect command.
+var CmdNew = &cobra.Command{
+	Use:   "inspect [file]",
+	Short: "Decode tars encoded packets",
+	Long: `Decode tars request and response packets read from a file or stdin.
+Without tars files the fields are printed by tag and wire type, with tars files the
+arguments and return values are decoded by the function definitions. Example:
+tarsgo inspect --format hex packet.txt
+tarsgo inspect --format pcap --tars Hello.tars --json capture.pcap
+tarsgo inspect --type struct --tars Hello.tars --struct TestApp::Request body.bin`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: run,
+}
+
+var (
+	format     string
+	packetType string
+	tarsFiles  []string
+	includes   []string
+	itf        string
+	structName string
+	funcName   string
+	jsonOutput bool
+)
+
+func init() {
+	CmdNew.Flags().StringVarP(&format, "format", "f", "raw", "input format: raw, hex, base64 or pcap")
+	CmdNew.Flags().StringVar(&packetType, "type", "auto", "packet type: auto, request, response or struct")
+	CmdNew.Flags().StringSliceVarP(&tarsFiles, "tars", "t", nil, "tars files used to decode the packets")
+	CmdNew.Flags().StringSliceVarP(&includes, "include", "I", nil, "search path of the included tars files")
+	CmdNew.Flags().StringVarP(&itf, "interface", "i", "", "interface of the function, required if the function name is ambiguous")
+	CmdNew.Flags().StringVarP(&structName, "struct", "s", "", "struct name with module, used with --type struct")
+	CmdNew.Flags().StringVar(&funcName, "func", "", "function name of the response packets whose request is not in the input")
+	CmdNew.Flags().BoolVar(&jsonOutput, "json", false, "print the result as json")
+}
+
+// Result is the decoded content of a packet.
+type Result struct {
+	Flow     string                   `json:"flow,omitempty"`
+	Request  *requestf.RequestPacket  `json:"request,omitempty"`
+	Response *requestf.ResponsePacket `json:"response,omitempty"`
+	Body     interface{}              `json:"body,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	var (
+		data []byte
+		err  error
+	)
+	if len(args) == 0 || args[0] == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	var s *schema.Schema
+	if len(tarsFiles) > 0 {
+		if s, err = schema.Load(includes, tarsFiles...); err != nil {
+			return err
+		}
+	}
+	streams, err := readInput(data, format)
+	if err != nil {
+		return err
+	}
+	in := &inspector{
+		schema:     s,
+		packetType: packetType,
+		itf:        itf,
+		structName: structName,
+		funcName:   funcName,
+		funcs:      make(map[int32]string),
+	}
+	results := in.inspectStreams(streams)
+
+	out := cmd.OutOrStdout()
+	if jsonOutput {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	for i, res := range results {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		printResult(out, res)
+	}
+	return nil
+}
+
+// readInput converts the input to the streams of packets.
+func readInput(data []byte, format string) ([]*Stream, error) {
+	switch format {
+	case "raw":
+	case "hex":
+		text := strings.Map(dropSeparator, string(data))
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "0x", ""), "0X", "")
+		b, err := hex.DecodeString(text)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	case "base64":
+		b, err := base64.StdEncoding.DecodeString(strings.Map(dropSeparator, string(data)))
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	case "pcap":
+		return ReadPcap(data)
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+	return []*Stream{{Data: data}}, nil
+}
+
+func dropSeparator(r rune) rune {
+	if unicode.IsSpace(r) || r == ':' || r == ',' {
+		return -1
+	}
+	return r
+}
+
+// maxPackageLength is the default maximum length of the packets of the servers.
+const maxPackageLength = 10485760
+
+type packet struct {
+	data []byte
+	err  error
+}
+
+// splitPackets splits the stream by the 4 bytes length header.
+// Data not starting with a valid length header is taken as a single packet without header.
+func splitPackets(st *Stream) []packet {
+	data := st.Data
+	if !framed(data) {
+		if truncated(data) {
+			return []packet{{data: data[4:], err: fmt.Errorf("incomplete packet of %d bytes", len(data))}}
+		}
+		return []packet{{data: data}}
+	}
+	var packets []packet
+	for len(data) > 0 {
+		if !framed(data) {
+			pkg := packet{data: data, err: fmt.Errorf("incomplete packet of %d bytes", len(data))}
+			if len(data) > 4 {
+				pkg.data = data[4:]
+			}
+			packets = append(packets, pkg)
+			break
+		}
+		length := binary.BigEndian.Uint32(data)
+		packets = append(packets, packet{data: data[4:length]})
+		data = data[length:]
+	}
+	return packets
+}
+
+func framed(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	length := binary.BigEndian.Uint32(data)
+	return length > 4 && int64(length) <= int64(len(data))
+}
+
+// truncated tells whether the data starts with the length header of an incomplete request or response packet,
+// whose first field is the iVersion of tag 1.
+func truncated(data []byte) bool {
+	if len(data) < 5 {
+		return false
+	}
+	length := binary.BigEndian.Uint32(data)
+	return int64(length) > int64(len(data)) && length <= maxPackageLength && data[4] == 0x10
+}
+
+type inspector struct {
+	schema     *schema.Schema
+	packetType string
+	itf        string
+	structName string
+	funcName   string
+	// function names of the requests seen, by request id
+	funcs map[int32]string
+}
+
+// inspectStreams splits the streams into packets and decodes them in order.
+func (in *inspector) inspectStreams(streams []*Stream) []*Result {
+	var results []*Result
+	for _, st := range streams {
+		for _, pkg := range splitPackets(st) {
+			res := in.Inspect(pkg.data)
+			res.Flow = st.Flow
+			if pkg.err != nil {
+				res.Error = pkg.err.Error()
+			}
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// Inspect decodes a packet without the length header.
+func (in *inspector) Inspect(data []byte) *Result {
+	res := &Result{}
+	typ := in.packetType
+	if typ == "auto" {
+		typ = detectType(data)
+	}
+	switch typ {
+	case "request":
+		req := &requestf.RequestPacket{}
+		if err := req.ReadFrom(codec.NewReader(data)); err != nil {
+			return in.raw(res, data, fmt.Errorf("decode request packet error: %v", err))
+		}
+		buf := tools.Int8ToByte(req.SBuffer)
+		req.SBuffer = nil
+		res.Request = req
+		in.funcs[req.IRequestId] = req.SFuncName
+		in.body(res, req.IVersion, req.SFuncName, buf, false)
+	case "response":
+		rsp := &requestf.ResponsePacket{}
+		if err := rsp.ReadFrom(codec.NewReader(data)); err != nil {
+			return in.raw(res, data, fmt.Errorf("decode response packet error: %v", err))
+		}
+		buf := tools.Int8ToByte(rsp.SBuffer)
+		rsp.SBuffer = nil
+		res.Response = rsp
+		// the function name is not in the response, take it from the request with the same id
+		name, ok := in.funcs[rsp.IRequestId]
+		if !ok {
+			name = in.funcName
+		}
+		in.body(res, rsp.IVersion, name, buf, true)
+	case "struct":
+		if in.schema == nil || in.structName == "" {
+			return in.raw(res, data, nil)
+		}
+		obj, err := in.schema.DecodeStruct(codec.NewReader(data), in.structName)
+		if err != nil {
+			return in.raw(res, data, err)
+		}
+		res.Body = obj
+	default:
+		return in.raw(res, data, fmt.Errorf("unknown packet type %s", typ))
+	}
+	return res
+}
+
+func (in *inspector) body(res *Result, version int16, funcName string, buf []byte, response bool) {
+	switch {
+	case version == basef.JSONVERSION && json.Valid(buf):
+		res.Body = json.RawMessage(buf)
+		return
+	case version != basef.TARSVERSION || in.schema == nil || funcName == "":
+		in.raw(res, buf, nil)
+		return
+	}
+	fun, err := in.schema.Function(in.itf, funcName)
+	if err != nil {
+		in.raw(res, buf, err)
+		return
+	}
+	obj, err := in.schema.DecodeArgs(buf, fun, response)
+	if err != nil {
+		in.raw(res, buf, err)
+		return
+	}
+	res.Body = obj
+}
+
+// raw dumps the data without schema, err is the reason why the schema is not used.
+func (in *inspector) raw(res *Result, data []byte, err error) *Result {
+	nodes, dumpErr := Dump(data)
+	res.Body = nodes
+	if err == nil {
+		err = dumpErr
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// detectType tells the request from the response by tag 5, which is sServantName of the request
+// and iRet of the response.
+func detectType(data []byte) string {
+	r := codec.NewReader(data)
+	have, ty, err := r.SkipToNoCheck(5, false)
+	if err != nil || !have {
+		return "struct"
+	}
+	if ty == codec.STRING1 || ty == codec.STRING4 {
+		return "request"
+	}
+	return "response"
+}
+
+func printResult(w io.Writer, res *Result) {
+	if res.Flow != "" {
+		fmt.Fprintf(w, "# %s\n", res.Flow)
+	}
+	if req := res.Request; req != nil {
+		fmt.Fprintf(w, "request: version=%d packetType=%d messageType=%d requestId=%d servant=%s func=%s timeout=%d\n",
+			req.IVersion, req.CPacketType, req.IMessageType, req.IRequestId, req.SServantName, req.SFuncName, req.ITimeout)
+		printMap(w, "context", req.Context)
+		printMap(w, "status", req.Status)
+	}
+	if rsp := res.Response; rsp != nil {
+		fmt.Fprintf(w, "response: version=%d packetType=%d messageType=%d requestId=%d ret=%d resultDesc=%q\n",
+			rsp.IVersion, rsp.CPacketType, rsp.IMessageType, rsp.IRequestId, rsp.IRet, rsp.SResultDesc)
+		printMap(w, "context", rsp.Context)
+		printMap(w, "status", rsp.Status)
+	}
+	if res.Error != "" {
+		fmt.Fprintf(w, "error: %s\n", res.Error)
+	}
+	switch body := res.Body.(type) {
+	case []*Node:
+		Print(w, body, 0)
+	case nil:
+	default:
+		b, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			fmt.Fprintf(w, "error: %v\n", err)
+			return
+		}
+		fmt.Fprintf(w, "%s\n", b)
+	}
+}
+
+func printMap(w io.Writer, name string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	b, _ := json.Marshal(m)
+	fmt.Fprintf(w, "%s: %s\n", name, b)
+}
@@ -0,0 +1,108 @@
+package inspect
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+const (
+	// Test.HelloServer.HelloObj Add(1, 2) with request id 7
+	requestHex = "0000003910012c3c40075619546573742e48656c6c6f5365727665722e48656c6c6f4f626a66034164647d00000410012002810bb8980ca80c"
+	// the response of request id 7 which returns 3
+	responseHex = "0000001710012c30074c5c6d0000020003780c8600980c"
+)
+
+func newTestInspector() *inspector {
+	return &inspector{packetType: "auto", funcs: make(map[int32]string)}
+}
+
+func TestInspect(t *testing.T) {
+	pcap, err := ioutil.ReadFile("testdata/add.pcap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+		// the printed results, separated by empty lines
+		want []string
+	}{
+		{
+			name:   "request",
+			format: "hex",
+			data:   []byte(requestHex),
+			want: []string{`request: version=1 packetType=0 messageType=0 requestId=7 servant=Test.HelloServer.HelloObj func=Add timeout=3000
+1 (Byte): 1
+2 (Byte): 2
+`},
+		},
+		{
+			name:   "response with separators",
+			format: "hex",
+			data:   []byte("0x00 0x00 0x00 0x17, 10:01:2c:30:07:4c:5c:6d:00:00:02:00:03:78:0c:86:00:98:0c"),
+			want: []string{`response: version=1 packetType=0 messageType=0 requestId=7 ret=0 resultDesc=""
+0 (Byte): 3
+`},
+		},
+		{
+			name:   "request and truncated response",
+			format: "hex",
+			data:   []byte(requestHex + responseHex[:20]),
+			want: []string{`request: version=1 packetType=0 messageType=0 requestId=7 servant=Test.HelloServer.HelloObj func=Add timeout=3000
+1 (Byte): 1
+2 (Byte): 2
+`, `error: incomplete packet of 10 bytes
+1 (Byte): 1
+2 (ZeroTag): 0
+3 (Byte): 7
+4 (ZeroTag): 0
+`},
+		},
+		{
+			name:   "truncated request",
+			format: "hex",
+			data:   []byte(requestHex[:40]),
+			want: []string{`error: incomplete packet of 20 bytes
+1 (Byte): 1
+2 (ZeroTag): 0
+3 (ZeroTag): 0
+4 (Byte): 7
+5 (String1): "Test.Hel"
+`},
+		},
+		{
+			name:   "pcap",
+			format: "pcap",
+			data:   pcap,
+			want: []string{`# 127.0.0.1:40000 > 127.0.0.1:10015
+request: version=1 packetType=0 messageType=0 requestId=7 servant=Test.HelloServer.HelloObj func=Add timeout=3000
+1 (Byte): 1
+2 (Byte): 2
+`, `# 127.0.0.1:10015 > 127.0.0.1:40000
+response: version=1 packetType=0 messageType=0 requestId=7 ret=0 resultDesc=""
+0 (Byte): 3
+`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, err := readInput(tt.data, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := newTestInspector().inspectStreams(streams)
+			if len(results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.want))
+			}
+			for i, res := range results {
+				var b bytes.Buffer
+				printResult(&b, res)
+				if got := b.String(); got != tt.want[i] {
+					t.Errorf("result %d got:\n%s\nwant:\n%s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
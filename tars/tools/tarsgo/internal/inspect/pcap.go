@@ -0,0 +1,181 @@
+package inspect
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// pcap link types
+const (
+	linkNull     = 0
+	linkEthernet = 1
+	linkRaw      = 101
+	linkLoop     = 108
+	linkLinuxSLL = 113
+	linkIPv4     = 228
+	linkIPv6     = 229
+	linkSLL2     = 276
+)
+
+// Stream is the payload sent in one direction of a tcp connection, or one udp datagram.
+type Stream struct {
+	Flow string
+	UDP  bool
+	Data []byte
+}
+
+// ReadPcap extracts the tcp streams and udp datagrams in a pcap file.
+// The tcp payloads are concatenated in capture order, retransmission and reordering are not handled.
+func ReadPcap(data []byte) ([]*Stream, error) {
+	if len(data) < 24 {
+		return nil, errors.New("pcap file too short")
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0xa1b2c3d4, 0xa1b23c4d:
+		order = binary.LittleEndian
+	case 0xd4c3b2a1, 0x4d3cb2a1:
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("not a pcap file, pcapng is not supported")
+	}
+	linkType := order.Uint32(data[20:]) & 0xffff
+
+	var streams []*Stream
+	tcp := make(map[string]*Stream)
+	for off := 24; off+16 <= len(data); {
+		capLen := int(order.Uint32(data[off+8:]))
+		off += 16
+		if off+capLen > len(data) {
+			break
+		}
+		frame := data[off : off+capLen]
+		off += capLen
+
+		flow, isUDP, payload, ok := decodeFrame(linkType, frame)
+		if !ok || len(payload) == 0 {
+			continue
+		}
+		if isUDP {
+			streams = append(streams, &Stream{Flow: flow, UDP: true, Data: payload})
+			continue
+		}
+		s, ok := tcp[flow]
+		if !ok {
+			s = &Stream{Flow: flow}
+			tcp[flow] = s
+			streams = append(streams, s)
+		}
+		s.Data = append(s.Data, payload...)
+	}
+	return streams, nil
+}
+
+func decodeFrame(linkType uint32, frame []byte) (flow string, isUDP bool, payload []byte, ok bool) {
+	var etherType uint16
+	switch linkType {
+	case linkEthernet:
+		if len(frame) < 14 {
+			return
+		}
+		etherType = binary.BigEndian.Uint16(frame[12:])
+		frame = frame[14:]
+		for etherType == 0x8100 && len(frame) >= 4 {
+			etherType = binary.BigEndian.Uint16(frame[2:])
+			frame = frame[4:]
+		}
+	case linkLinuxSLL:
+		if len(frame) < 16 {
+			return
+		}
+		etherType = binary.BigEndian.Uint16(frame[14:])
+		frame = frame[16:]
+	case linkSLL2:
+		if len(frame) < 20 {
+			return
+		}
+		etherType = binary.BigEndian.Uint16(frame)
+		frame = frame[20:]
+	case linkNull, linkLoop:
+		if len(frame) < 4 {
+			return
+		}
+		frame = frame[4:]
+		etherType = ipEtherType(frame)
+	case linkRaw, linkIPv4, linkIPv6:
+		etherType = ipEtherType(frame)
+	default:
+		return
+	}
+
+	var src, dst net.IP
+	var proto byte
+	switch etherType {
+	case 0x0800:
+		if len(frame) < 20 {
+			return
+		}
+		hl := int(frame[0]&0x0f) * 4
+		total := int(binary.BigEndian.Uint16(frame[2:]))
+		if hl < 20 || total < hl || len(frame) < hl {
+			return
+		}
+		if total < len(frame) {
+			// drop the ethernet padding
+			frame = frame[:total]
+		}
+		src, dst, proto = net.IP(frame[12:16]), net.IP(frame[16:20]), frame[9]
+		frame = frame[hl:]
+	case 0x86dd:
+		if len(frame) < 40 {
+			return
+		}
+		plen := int(binary.BigEndian.Uint16(frame[4:]))
+		src, dst, proto = net.IP(frame[8:24]), net.IP(frame[24:40]), frame[6]
+		frame = frame[40:]
+		if plen < len(frame) {
+			frame = frame[:plen]
+		}
+	default:
+		return
+	}
+
+	switch proto {
+	case 6:
+		if len(frame) < 20 {
+			return
+		}
+		dataOff := int(frame[12]>>4) * 4
+		if dataOff < 20 || len(frame) < dataOff {
+			return
+		}
+		payload = frame[dataOff:]
+	case 17:
+		if len(frame) < 8 {
+			return
+		}
+		isUDP = true
+		payload = frame[8:]
+	default:
+		return
+	}
+	flow = fmt.Sprintf("%s > %s",
+		net.JoinHostPort(src.String(), fmt.Sprint(binary.BigEndian.Uint16(frame))),
+		net.JoinHostPort(dst.String(), fmt.Sprint(binary.BigEndian.Uint16(frame[2:]))))
+	return flow, isUDP, payload, true
+}
+
+func ipEtherType(frame []byte) uint16 {
+	if len(frame) == 0 {
+		return 0
+	}
+	switch frame[0] >> 4 {
+	case 4:
+		return 0x0800
+	case 6:
+		return 0x86dd
+	}
+	return 0
+}
@@ -0,0 +1,264 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/tools/tars2go/parse"
+)
+
+// Field is a named value of an Object.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Object is a decoded struct or map, it keeps the order of the fields when marshaled to json.
+type Object []Field
+
+// MarshalJSON implements json.Marshaler.
+func (o Object) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Get returns the value of the field called name.
+func (o Object) Get(name string) (interface{}, bool) {
+	for _, f := range o {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Decode reads the field with tag as type ty, module is the module where ty is used.
+// It returns nil if the field is optional and absent.
+func (s *Schema) Decode(r *codec.Reader, module string, ty *parse.VarType, tag byte, require bool) (interface{}, error) {
+	have, _, err := r.SkipToNoCheck(tag, require)
+	if err != nil {
+		return nil, err
+	}
+	if !have {
+		return nil, nil
+	}
+	r.UnreadHead(tag)
+	return s.decodeValue(r, module, ty, tag)
+}
+
+// DecodeStruct reads the fields of the struct called name until the end of r.
+func (s *Schema) DecodeStruct(r *codec.Reader, name string) (Object, error) {
+	def, ok := s.structs[name]
+	if !ok {
+		return nil, fmt.Errorf("struct %s not found", name)
+	}
+	return s.decodeFields(r, def)
+}
+
+func (s *Schema) decodeFields(r *codec.Reader, def *structDef) (Object, error) {
+	obj := make(Object, 0, len(def.info.Mb))
+	for _, mb := range def.info.Mb {
+		v, err := s.Decode(r, def.module, mb.Type, byte(mb.Tag), mb.Require)
+		if err != nil {
+			return nil, fmt.Errorf("read %s::%s.%s error: %v", def.module, def.info.Name, mb.Key, err)
+		}
+		if v != nil {
+			obj = append(obj, Field{Name: mb.Key, Value: v})
+		}
+	}
+	return obj, nil
+}
+
+func (s *Schema) decodeValue(r *codec.Reader, module string, ty *parse.VarType, tag byte) (interface{}, error) {
+	switch ty.Type {
+	case parse.TkTBool:
+		var v bool
+		err := r.ReadBool(&v, tag, true)
+		return v, err
+	case parse.TkTByte, parse.TkTShort, parse.TkTInt, parse.TkTLong:
+		var v int64
+		err := r.ReadInt64(&v, tag, true)
+		return v, err
+	case parse.TkTFloat, parse.TkTDouble:
+		var v float64
+		err := r.ReadFloat64(&v, tag, true)
+		return v, err
+	case parse.TkTString:
+		var v string
+		err := r.ReadString(&v, tag, true)
+		return v, err
+	case parse.TkTVector, parse.TkTArray:
+		return s.decodeList(r, module, ty, tag)
+	case parse.TkTMap:
+		return s.decodeMap(r, module, ty, tag)
+	case parse.TkName:
+		return s.decodeName(r, module, ty, tag)
+	}
+	return nil, fmt.Errorf("unsupported type %s", parse.TokenMap[ty.Type])
+}
+
+func (s *Schema) decodeList(r *codec.Reader, module string, ty *parse.VarType, tag byte) (interface{}, error) {
+	_, t, err := r.SkipToNoCheck(tag, true)
+	if err != nil {
+		return nil, err
+	}
+	var length int32
+	switch t {
+	case codec.SimpleList:
+		if _, _, err = r.ReadHead(); err != nil {
+			return nil, err
+		}
+		if err = r.ReadInt32(&length, 0, true); err != nil {
+			return nil, err
+		}
+		if length < 0 || int(length) > r.Len() {
+			return nil, fmt.Errorf("invalid simple list length %d", length)
+		}
+		list := make([]int8, length)
+		for i, b := range r.Next(int(length)) {
+			list[i] = int8(b)
+		}
+		return list, nil
+	case codec.LIST:
+		if err = r.ReadInt32(&length, 0, true); err != nil {
+			return nil, err
+		}
+		if length < 0 || int(length) > r.Len() {
+			return nil, fmt.Errorf("invalid list length %d", length)
+		}
+		list := make([]interface{}, length)
+		for i := range list {
+			if list[i], err = s.Decode(r, module, ty.TypeK, 0, true); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("require vector, but got type %d", t)
+}
+
+func (s *Schema) decodeMap(r *codec.Reader, module string, ty *parse.VarType, tag byte) (interface{}, error) {
+	_, t, err := r.SkipToNoCheck(tag, true)
+	if err != nil {
+		return nil, err
+	}
+	if t != codec.MAP {
+		return nil, fmt.Errorf("require map, but got type %d", t)
+	}
+	var length int32
+	if err = r.ReadInt32(&length, 0, true); err != nil {
+		return nil, err
+	}
+	if length < 0 || int(length) > r.Len() {
+		return nil, fmt.Errorf("invalid map length %d", length)
+	}
+	// maps with scalar keys become json objects, others a list of key value pairs
+	obj := make(Object, 0, length)
+	var pairs []Object
+	for i := int32(0); i < length; i++ {
+		k, err := s.Decode(r, module, ty.TypeK, 0, true)
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.Decode(r, module, ty.TypeV, 1, true)
+		if err != nil {
+			return nil, err
+		}
+		switch key := k.(type) {
+		case string:
+			obj = append(obj, Field{Name: key, Value: v})
+		case int64:
+			obj = append(obj, Field{Name: strconv.FormatInt(key, 10), Value: v})
+		case bool:
+			obj = append(obj, Field{Name: strconv.FormatBool(key), Value: v})
+		default:
+			pairs = append(pairs, Object{{Name: "key", Value: k}, {Name: "value", Value: v}})
+		}
+	}
+	if pairs != nil {
+		return pairs, nil
+	}
+	return obj, nil
+}
+
+func (s *Schema) decodeName(r *codec.Reader, module string, ty *parse.VarType, tag byte) (interface{}, error) {
+	name := qualify(module, ty.TypeSt)
+	if en, ok := s.enums[name]; ok {
+		var v int32
+		if err := r.ReadInt32(&v, tag, true); err != nil {
+			return nil, err
+		}
+		if key, ok := en.names[v]; ok {
+			return key, nil
+		}
+		return int64(v), nil
+	}
+	def, ok := s.structs[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+	_, t, err := r.SkipToNoCheck(tag, true)
+	if err != nil {
+		return nil, err
+	}
+	if t != codec.StructBegin {
+		return nil, fmt.Errorf("require struct %s, but got type %d", name, t)
+	}
+	obj, err := s.decodeFields(r, def)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.SkipToStructEnd(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// DecodeArgs decodes the sBuffer of a request or response packet of fun.
+// The arguments are encoded with the tag of their index plus one, and the return value with tag 0.
+func (s *Schema) DecodeArgs(data []byte, fun *Function, response bool) (Object, error) {
+	r := codec.NewReader(data)
+	var obj Object
+	if response && fun.Info.HasRet {
+		v, err := s.Decode(r, fun.Module, fun.Info.RetType, 0, false)
+		if err != nil {
+			return nil, fmt.Errorf("read return value error: %v", err)
+		}
+		if v != nil {
+			obj = append(obj, Field{Name: "ret", Value: v})
+		}
+	}
+	for k, arg := range fun.Info.Args {
+		if arg.IsOut != response {
+			continue
+		}
+		v, err := s.Decode(r, fun.Module, arg.Type, byte(k+1), false)
+		if err != nil {
+			return nil, fmt.Errorf("read argument %s error: %v", arg.Name, err)
+		}
+		if v != nil {
+			obj = append(obj, Field{Name: arg.Name, Value: v})
+		}
+	}
+	return obj, nil
+}
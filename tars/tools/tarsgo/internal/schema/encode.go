@@ -0,0 +1,273 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/TarsCloud/TarsGo/tars/protocol/codec"
+	"github.com/TarsCloud/TarsGo/tars/tools/tars2go/parse"
+)
+
+// Encode writes v with tag as type ty, module is the module where ty is used.
+// v is a value decoded from json with json.Decoder.UseNumber, nil is written as the zero value.
+func (s *Schema) Encode(buf *codec.Buffer, module string, ty *parse.VarType, tag byte, v interface{}) error {
+	switch ty.Type {
+	case parse.TkTBool:
+		b, err := toBool(v)
+		if err != nil {
+			return err
+		}
+		return buf.WriteBool(b, tag)
+	case parse.TkTByte, parse.TkTShort, parse.TkTInt, parse.TkTLong:
+		n, err := toInt(v)
+		if err != nil {
+			return err
+		}
+		// the integers are written in the shortest form, whatever the size of the type
+		return buf.WriteInt64(n, tag)
+	case parse.TkTFloat:
+		f, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		return buf.WriteFloat32(float32(f), tag)
+	case parse.TkTDouble:
+		f, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		return buf.WriteFloat64(f, tag)
+	case parse.TkTString:
+		str, ok := v.(string)
+		if !ok && v != nil {
+			return fmt.Errorf("require string, but got %v", v)
+		}
+		return buf.WriteString(str, tag)
+	case parse.TkTVector, parse.TkTArray:
+		return s.encodeList(buf, module, ty, tag, v)
+	case parse.TkTMap:
+		return s.encodeMap(buf, module, ty, tag, v)
+	case parse.TkName:
+		return s.encodeName(buf, module, ty, tag, v)
+	}
+	return fmt.Errorf("unsupported type %s", parse.TokenMap[ty.Type])
+}
+
+// EncodeArgs encodes the input arguments of fun, args is either an object by the argument names
+// or an array of the input arguments in order.
+func (s *Schema) EncodeArgs(fun *Function, args interface{}) ([]byte, error) {
+	var named map[string]interface{}
+	var list []interface{}
+	switch a := args.(type) {
+	case nil:
+	case map[string]interface{}:
+		named = a
+	case []interface{}:
+		list = a
+	default:
+		return nil, fmt.Errorf("arguments must be a json object or array, but got %v", args)
+	}
+
+	buf := codec.NewBuffer()
+	var n int
+	for k, arg := range fun.Info.Args {
+		if arg.IsOut {
+			continue
+		}
+		var v interface{}
+		if named != nil {
+			v = named[arg.Name]
+		} else if n < len(list) {
+			v = list[n]
+		}
+		n++
+		if err := s.Encode(buf, fun.Module, arg.Type, byte(k+1), v); err != nil {
+			return nil, fmt.Errorf("write argument %s error: %v", arg.Name, err)
+		}
+	}
+	if len(list) > n {
+		return nil, fmt.Errorf("too many arguments, %s takes %d", fun.Info.Name, n)
+	}
+	return buf.ToBytes(), nil
+}
+
+func (s *Schema) encodeList(buf *codec.Buffer, module string, ty *parse.VarType, tag byte, v interface{}) error {
+	var list []interface{}
+	switch l := v.(type) {
+	case nil:
+	case []interface{}:
+		list = l
+	case string:
+		// a string is taken as the raw bytes of vector<byte>
+		if ty.TypeK.Type != parse.TkTByte {
+			return fmt.Errorf("require array, but got %v", v)
+		}
+		for _, b := range []byte(l) {
+			list = append(list, json.Number(strconv.Itoa(int(b))))
+		}
+	default:
+		return fmt.Errorf("require array, but got %v", v)
+	}
+
+	if ty.TypeK.Type == parse.TkTByte && !ty.TypeK.Unsigned {
+		data := make([]int8, len(list))
+		for i, e := range list {
+			n, err := toInt(e)
+			if err != nil {
+				return err
+			}
+			data[i] = int8(n)
+		}
+		if err := buf.WriteHead(codec.SimpleList, tag); err != nil {
+			return err
+		}
+		if err := buf.WriteHead(codec.BYTE, 0); err != nil {
+			return err
+		}
+		if err := buf.WriteInt32(int32(len(data)), 0); err != nil {
+			return err
+		}
+		return buf.WriteSliceInt8(data)
+	}
+
+	if err := buf.WriteHead(codec.LIST, tag); err != nil {
+		return err
+	}
+	if err := buf.WriteInt32(int32(len(list)), 0); err != nil {
+		return err
+	}
+	for _, e := range list {
+		if err := s.Encode(buf, module, ty.TypeK, 0, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) encodeMap(buf *codec.Buffer, module string, ty *parse.VarType, tag byte, v interface{}) error {
+	// maps are given as json objects, or lists of key value pairs for keys which are not scalar
+	var pairs [][2]interface{}
+	switch m := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		for key, value := range m {
+			pairs = append(pairs, [2]interface{}{key, value})
+		}
+	case []interface{}:
+		for _, e := range m {
+			pair, ok := e.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("require key value pair, but got %v", e)
+			}
+			pairs = append(pairs, [2]interface{}{pair["key"], pair["value"]})
+		}
+	default:
+		return fmt.Errorf("require object, but got %v", v)
+	}
+
+	if err := buf.WriteHead(codec.MAP, tag); err != nil {
+		return err
+	}
+	if err := buf.WriteInt32(int32(len(pairs)), 0); err != nil {
+		return err
+	}
+	for _, pair := range pairs {
+		if err := s.Encode(buf, module, ty.TypeK, 0, pair[0]); err != nil {
+			return err
+		}
+		if err := s.Encode(buf, module, ty.TypeV, 1, pair[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) encodeName(buf *codec.Buffer, module string, ty *parse.VarType, tag byte, v interface{}) error {
+	name := qualify(module, ty.TypeSt)
+	if en, ok := s.enums[name]; ok {
+		if key, ok := v.(string); ok {
+			value, ok := en.values[key]
+			if !ok {
+				return fmt.Errorf("enum %s has no member %s", name, key)
+			}
+			return buf.WriteInt32(value, tag)
+		}
+		n, err := toInt(v)
+		if err != nil {
+			return err
+		}
+		return buf.WriteInt32(int32(n), tag)
+	}
+	def, ok := s.structs[name]
+	if !ok {
+		return fmt.Errorf("type %s not found", name)
+	}
+	fields, ok := v.(map[string]interface{})
+	if !ok && v != nil {
+		return fmt.Errorf("require struct %s, but got %v", name, v)
+	}
+	if err := buf.WriteHead(codec.StructBegin, tag); err != nil {
+		return err
+	}
+	for _, mb := range def.info.Mb {
+		value, ok := fields[mb.Key]
+		if !ok && !mb.Require {
+			continue
+		}
+		if err := s.Encode(buf, def.module, mb.Type, byte(mb.Tag), value); err != nil {
+			return fmt.Errorf("write %s.%s error: %v", name, mb.Key, err)
+		}
+	}
+	return buf.WriteHead(codec.StructEnd, 0)
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch b := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return b, nil
+	case string:
+		return strconv.ParseBool(b)
+	}
+	return false, fmt.Errorf("require bool, but got %v", v)
+}
+
+func toInt(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		return n.Int64()
+	case float64:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case bool:
+		if n {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		// the keys of json objects are strings
+		return strconv.ParseInt(n, 10, 64)
+	}
+	return 0, fmt.Errorf("require integer, but got %v", v)
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		return n.Float64()
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	}
+	return 0, fmt.Errorf("require number, but got %v", v)
+}
@@ -0,0 +1,166 @@
+// Package schema loads tars protocol files and decodes tars encoded data with them.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/TarsCloud/TarsGo/tars/tools/tars2go/parse"
+)
+
+// the tars2go parser keeps its options in package variables and reports errors by panic
+var parseLock sync.Mutex
+
+type structDef struct {
+	module string
+	info   *parse.StructInfo
+}
+
+type enumDef struct {
+	module string
+	info   *parse.EnumInfo
+	names  map[int32]string
+	values map[string]int32
+}
+
+// Function is a function of an interface defined in the tars files.
+type Function struct {
+	Module    string
+	Interface string
+	Info      *parse.FunInfo
+}
+
+// Schema holds the structs, enums and interfaces defined in a set of tars files.
+type Schema struct {
+	structs    map[string]*structDef
+	enums      map[string]*enumDef
+	interfaces map[string]*parse.InterfaceInfo
+	funcs      map[string][]*Function
+	sources    map[string]bool
+}
+
+// Load parses the tars files, includes is the search path of the included files.
+func Load(includes []string, files ...string) (s *Schema, err error) {
+	parseLock.Lock()
+	defer parseLock.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			s = nil
+			err = fmt.Errorf("parse tars file error: %v", r)
+		}
+	}()
+
+	parse.Includes = includes
+	parse.Output = ioutil.Discard
+	s = &Schema{
+		structs:    make(map[string]*structDef),
+		enums:      make(map[string]*enumDef),
+		interfaces: make(map[string]*parse.InterfaceInfo),
+		funcs:      make(map[string][]*Function),
+		sources:    make(map[string]bool),
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			return nil, err
+		}
+		s.add(parse.ParseFile(file, nil))
+	}
+	return s, nil
+}
+
+func (s *Schema) add(p *parse.Parse) {
+	if s.sources[p.Source] {
+		return
+	}
+	s.sources[p.Source] = true
+	for _, inc := range p.IncParse {
+		s.add(inc)
+	}
+	for i := range p.Struct {
+		st := &p.Struct[i]
+		s.structs[p.Module+"::"+st.Name] = &structDef{module: p.Module, info: st}
+	}
+	for i := range p.Enum {
+		en := &p.Enum[i]
+		s.enums[p.Module+"::"+en.Name] = newEnumDef(p.Module, en)
+	}
+	for i := range p.Interface {
+		itf := &p.Interface[i]
+		s.interfaces[p.Module+"::"+itf.Name] = itf
+		for j := range itf.Fun {
+			fun := &itf.Fun[j]
+			s.funcs[fun.Name] = append(s.funcs[fun.Name], &Function{Module: p.Module, Interface: itf.Name, Info: fun})
+		}
+	}
+}
+
+// newEnumDef computes the values of the enum members the same way as the generated code.
+func newEnumDef(module string, en *parse.EnumInfo) *enumDef {
+	def := &enumDef{
+		module: module,
+		info:   en,
+		names:  make(map[int32]string),
+		values: make(map[string]int32),
+	}
+	var it int32
+	for _, v := range en.Mb {
+		switch v.Type {
+		case 0:
+			it = v.Value
+		case 1:
+			it = def.values[v.Name]
+		}
+		def.values[v.Key] = it
+		if _, ok := def.names[it]; !ok {
+			def.names[it] = v.Key
+		}
+		it++
+	}
+	return def
+}
+
+// qualify returns the full name of the type name used in module.
+func qualify(module, name string) string {
+	if strings.Contains(name, "::") {
+		return name
+	}
+	return module + "::" + name
+}
+
+// Function finds the function called name, itf is the interface name and can be omitted if the function name is unique.
+// itf can be qualified with the module name, such as "TestApp::Hello".
+func (s *Schema) Function(itf, name string) (*Function, error) {
+	var found []*Function
+	for _, fun := range s.funcs[name] {
+		if itf == "" || itf == fun.Interface || itf == fun.Module+"::"+fun.Interface {
+			found = append(found, fun)
+		}
+	}
+	switch len(found) {
+	case 0:
+		if itf != "" {
+			return nil, fmt.Errorf("function %s not found in interface %s", name, itf)
+		}
+		return nil, fmt.Errorf("function %s not found", name)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, 0, len(found))
+	for _, fun := range found {
+		names = append(names, fun.Module+"::"+fun.Interface)
+	}
+	return nil, errors.New("function " + name + " is ambiguous, specify the interface: " + strings.Join(names, ", "))
+}
+
+// Struct returns the definition of the struct called name, which is qualified with the module name.
+func (s *Schema) Struct(name string) (*parse.StructInfo, bool) {
+	def, ok := s.structs[name]
+	if !ok {
+		return nil, false
+	}
+	return def.info, true
+}
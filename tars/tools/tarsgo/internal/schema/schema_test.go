@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTars = `
+module Test {
+	enum Color { RED, GREEN = 5, BLUE };
+	struct Item {
+		0 require string name;
+		1 optional Color color;
+		2 optional map<string, int> counts;
+		3 optional vector<byte> data;
+		4 optional vector<double> values;
+	};
+	interface Hello {
+		int Echo(Item item, int n, out Item result);
+	};
+};
+`
+
+func TestEncodeDecodeArgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "Hello.tars")
+	if err = ioutil.WriteFile(file, []byte(testTars), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(nil, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fun, err := s.Function("Test::Hello", "Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := `{"item":{"name":"a","color":"BLUE","counts":{"x":1},"data":[1,2],"values":[1.5]},"n":3}`
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(args))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := s.EncodeArgs(fun, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := s.DecodeArgs(buf, fun, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != args {
+		t.Errorf("got %s, want %s", out, args)
+	}
+}
@@ -16,8 +16,11 @@ limitations under the License.
 package main
 
 import (
+	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/call"
 	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/cmake"
+	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/conf"
 	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/consts"
+	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/inspect"
 	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/make"
 	"github.com/TarsCloud/TarsGo/tars/tools/tarsgo/internal/upgrade"
 	"github.com/spf13/cobra"
@@ -35,6 +38,9 @@ func init() {
 	rootCmd.AddCommand(make.CmdNew)
 	rootCmd.AddCommand(cmake.CmdNew)
 	rootCmd.AddCommand(upgrade.CmdNew)
+	rootCmd.AddCommand(inspect.CmdNew)
+	rootCmd.AddCommand(call.CmdNew)
+	rootCmd.AddCommand(conf.CmdNew)
 }
 
 func main() {
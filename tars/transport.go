@@ -26,6 +26,13 @@ func WithMaxInvoke(maxInvoke int32) ServerConfOption {
 	}
 }
 
+// WithUDPReaders sets the number of the goroutines reading the udp sockets with SO_REUSEPORT.
+func WithUDPReaders(readers int) ServerConfOption {
+	return func(c *transport.TarsServerConf) {
+		c.UDPReaders = readers
+	}
+}
+
 func newTarsServerConf(proto, address string, svrCfg *serverConfig, opts ...ServerConfOption) *transport.TarsServerConf {
 	tarsSvrConf := &transport.TarsServerConf{
 		Proto:          proto,
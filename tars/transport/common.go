@@ -23,6 +23,15 @@ type ServerProtocol interface {
 	DoClose(ctx context.Context)
 }
 
+// ShutdownRejecter is implemented by the ServerProtocol which rejects the requests received on the old
+// connections while the server shuts down gracefully, so the clients can retry them on the new connections.
+// The requests are invoked as usual if the protocol does not implement it.
+type ShutdownRejecter interface {
+	// RejectShutdown returns the response of the rejected request, or false if the request is not rejected,
+	// such as the oneway request which the client can not retry, then the request is invoked as usual.
+	RejectShutdown(pkg []byte) (rsp []byte, rejected bool)
+}
+
 // ClientProtocol interface for handling tars client package.
 type ClientProtocol interface {
 	Recv(pkg []byte)
@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"crypto/tls"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxUDPPeers is the max number of the udp peers tracked.
+	maxUDPPeers = 1024
+	// udpPeerIdle is the time to forget the udp peer without package.
+	udpPeerIdle = 10 * time.Minute
+)
+
+// ConnStat is the stat of a live tcp connection.
+type ConnStat struct {
+	RemoteAddr string
+	Idle       time.Duration
+	NumInvoke  int32
+	BytesIn    int64
+	BytesOut   int64
+	// TLS is nil if the connection is not over tls or the handshake is not done.
+	TLS *tls.ConnectionState
+}
+
+// UDPPeerStat is the stat of the packages from an udp peer.
+type UDPPeerStat struct {
+	RemoteAddr string
+	Idle       time.Duration
+	Packets    int64
+	BytesIn    int64
+	BytesOut   int64
+}
+
+// udpPeer is the activity of an udp peer.
+type udpPeer struct {
+	lastActive int64
+	packets    int64
+	bytesIn    int64
+	bytesOut   int64
+}
+
+// connHandler is the ServerHandler tracking the connections.
+type connHandler interface {
+	connStats() []ConnStat
+	closeConn(remoteAddr string) bool
+	closeIdleConns(idle time.Duration) int
+}
+
+// Conns returns the live tcp connections sorted by the remote addresses.
+func (ts *TarsServer) Conns() []ConnStat {
+	h, ok := ts.handle.(connHandler)
+	if !ok {
+		return nil
+	}
+	stats := h.connStats()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].RemoteAddr < stats[j].RemoteAddr })
+	return stats
+}
+
+// CloseConn closes the tcp connection from remoteAddr, the invoking requests are done before close.
+func (ts *TarsServer) CloseConn(remoteAddr string) bool {
+	h, ok := ts.handle.(connHandler)
+	return ok && h.closeConn(remoteAddr)
+}
+
+// CloseIdleConns closes the tcp connections without invoking requests and package within idle,
+// and returns the number of the connections closed.
+func (ts *TarsServer) CloseIdleConns(idle time.Duration) int {
+	h, ok := ts.handle.(connHandler)
+	if !ok {
+		return 0
+	}
+	return h.closeIdleConns(idle)
+}
+
+// UDPPeers returns the udp peers sent packages within 10 minutes sorted by the remote addresses,
+// 1024 peers are tracked at most.
+func (ts *TarsServer) UDPPeers() []UDPPeerStat {
+	h, ok := ts.handle.(*udpHandler)
+	if !ok {
+		return nil
+	}
+	stats := h.peerStats()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].RemoteAddr < stats[j].RemoteAddr })
+	return stats
+}
+
+func (h *tcpHandler) connStats() []ConnStat {
+	now := time.Now().UnixNano()
+	var stats []ConnStat
+	h.conns.Range(func(key, val interface{}) bool {
+		connSt := val.(*connInfo)
+		stat := ConnStat{
+			RemoteAddr: key.(string),
+			Idle:       time.Duration(now - atomic.LoadInt64(&connSt.lastActive)),
+			NumInvoke:  atomic.LoadInt32(&connSt.numInvoke),
+			BytesIn:    atomic.LoadInt64(&connSt.bytesIn),
+			BytesOut:   atomic.LoadInt64(&connSt.bytesOut),
+		}
+		if c, ok := connSt.conn.(*tls.Conn); ok {
+			if state := c.ConnectionState(); state.HandshakeComplete {
+				stat.TLS = &state
+			}
+		}
+		stats = append(stats, stat)
+		return true
+	})
+	return stats
+}
+
+func (h *tcpHandler) closeConn(remoteAddr string) bool {
+	val, ok := h.conns.Load(remoteAddr)
+	if !ok {
+		return false
+	}
+	connSt := val.(*connInfo)
+	TLOG.Infof("close connection %s by admin", remoteAddr)
+	// the read is stopped, and the connection is closed after the invoking requests are done
+	atomic.StoreInt32(&connSt.closing, 1)
+	connSt.conn.SetReadDeadline(time.Now())
+	return true
+}
+
+func (h *tcpHandler) closeIdleConns(idle time.Duration) int {
+	before := time.Now().Add(-idle).UnixNano()
+	var n int
+	h.conns.Range(func(key, val interface{}) bool {
+		connSt := val.(*connInfo)
+		if atomic.LoadInt32(&connSt.numInvoke) == 0 && atomic.LoadInt64(&connSt.lastActive) <= before && h.closeConn(key.(string)) {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// trackPeer records the package from addr.
+func (h *udpHandler) trackPeer(addr string, n int) *udpPeer {
+	val, ok := h.peers.Load(addr)
+	if !ok {
+		if atomic.LoadInt32(&h.numPeer) >= maxUDPPeers {
+			h.prunePeers()
+			if atomic.LoadInt32(&h.numPeer) >= maxUDPPeers {
+				return nil
+			}
+		}
+		var loaded bool
+		val, loaded = h.peers.LoadOrStore(addr, &udpPeer{})
+		if !loaded {
+			atomic.AddInt32(&h.numPeer, 1)
+		}
+	}
+	peer := val.(*udpPeer)
+	atomic.StoreInt64(&peer.lastActive, time.Now().UnixNano())
+	atomic.AddInt64(&peer.packets, 1)
+	atomic.AddInt64(&peer.bytesIn, int64(n))
+	return peer
+}
+
+// prunePeers forgets the peers idle for udpPeerIdle.
+func (h *udpHandler) prunePeers() {
+	before := time.Now().Add(-udpPeerIdle).UnixNano()
+	h.peers.Range(func(key, val interface{}) bool {
+		if atomic.LoadInt64(&val.(*udpPeer).lastActive) < before {
+			h.peers.Delete(key)
+			atomic.AddInt32(&h.numPeer, -1)
+		}
+		return true
+	})
+}
+
+func (h *udpHandler) peerStats() []UDPPeerStat {
+	h.prunePeers()
+	now := time.Now().UnixNano()
+	var stats []UDPPeerStat
+	h.peers.Range(func(key, val interface{}) bool {
+		peer := val.(*udpPeer)
+		stats = append(stats, UDPPeerStat{
+			RemoteAddr: key.(string),
+			Idle:       time.Duration(now - atomic.LoadInt64(&peer.lastActive)),
+			Packets:    atomic.LoadInt64(&peer.packets),
+			BytesIn:    atomic.LoadInt64(&peer.bytesIn),
+			BytesOut:   atomic.LoadInt64(&peer.bytesOut),
+		})
+		return true
+	})
+	return stats
+}
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func waitConns(ts *TarsServer, n int) []ConnStat {
+	deadline := time.Now().Add(time.Second)
+	for {
+		conns := ts.Conns()
+		if len(conns) == n || time.Now().After(deadline) {
+			return conns
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConns(t *testing.T) {
+	conf := &TarsServerConf{
+		Proto:         "tcp",
+		Address:       "127.0.0.1:0",
+		AcceptTimeout: 500 * time.Millisecond,
+		ReadTimeout:   500 * time.Millisecond,
+		IdleTimeout:   time.Minute,
+	}
+	ts := NewTarsServer(&echoProtocol{}, conf)
+	if err := ts.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go ts.Serve()
+	defer shutdown(t, ts)
+	addr := ts.handle.(*tcpHandler).listener.Addr().String()
+
+	var clients []net.Conn
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		clients = append(clients, conn)
+	}
+	pkg := []byte{0, 0, 0, 5, 1}
+	if _, err := clients[0].Write(pkg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(clients[0], make([]byte, len(pkg))); err != nil {
+		t.Fatal(err)
+	}
+
+	conns := waitConns(ts, 2)
+	if len(conns) != 2 {
+		t.Fatalf("got %d connections, want 2", len(conns))
+	}
+	for _, c := range conns {
+		if c.RemoteAddr != clients[0].LocalAddr().String() {
+			continue
+		}
+		if c.BytesIn != 5 || c.BytesOut != 5 || c.NumInvoke != 0 || c.TLS != nil {
+			t.Errorf("got %+v, want 5 bytes in and out", c)
+		}
+	}
+
+	if ts.CloseConn("127.0.0.1:1") {
+		t.Error("closed the unknown connection")
+	}
+	if !ts.CloseConn(clients[0].LocalAddr().String()) {
+		t.Error("failed to close the connection")
+	}
+	clients[0].SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := clients[0].Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got %v, want the connection closed by the server", err)
+	}
+	if conns = waitConns(ts, 1); len(conns) != 1 {
+		t.Fatalf("got %d connections, want 1", len(conns))
+	}
+
+	if n := ts.CloseIdleConns(time.Minute); n != 0 {
+		t.Errorf("closed %d connections active within a minute", n)
+	}
+	if n := ts.CloseIdleConns(0); n != 1 {
+		t.Errorf("closed %d idle connections, want 1", n)
+	}
+	if conns = waitConns(ts, 0); len(conns) != 0 {
+		t.Fatalf("got %d connections, want 0", len(conns))
+	}
+}
+
+func TestUDPPeers(t *testing.T) {
+	conf := &TarsServerConf{Proto: "udp", Address: freeUDPAddr(t), MaxInvoke: 4, QueueCap: 100}
+	ts := startUDPServer(t, &echoProtocol{}, conf)
+	defer shutdown(t, ts)
+
+	conn, err := net.Dial("udp4", conf.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	pkg := []byte{0, 0, 0, 5, 1}
+	for i := 0; i < 2; i++ {
+		if _, err = conn.Write(pkg); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err = conn.Read(make([]byte, 16)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	peers := ts.UDPPeers()
+	if len(peers) != 1 {
+		t.Fatalf("got %d peers, want 1", len(peers))
+	}
+	p := peers[0]
+	if p.RemoteAddr != conn.LocalAddr().String() || p.Packets != 2 || p.BytesIn != 10 || p.BytesOut != 10 {
+		t.Errorf("got %+v, want 2 packages from %s", p, conn.LocalAddr())
+	}
+}
@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// sniffLen is the length of the head to tell http from tars. A tars package begins with its length in 4 bytes,
+// which is far beyond the max package length when read from the head of a http request.
+const sniffLen = 4
+
+// httpHeads are the heads of http/1.x requests and the preface of http/2 cleartext.
+var httpHeads = [][]byte{
+	[]byte("GET "),
+	[]byte("POST"),
+	[]byte("PUT "),
+	[]byte("HEAD"),
+	[]byte("DELE"),
+	[]byte("OPTI"),
+	[]byte("PATC"),
+	[]byte("CONN"),
+	[]byte("TRAC"),
+	[]byte("PRI "),
+}
+
+var errListenerClosed = errors.New("listener closed")
+
+func isHttpHead(head []byte) bool {
+	for _, h := range httpHeads {
+		if bytes.Equal(head, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffConn is a connection whose head has been peeked.
+type sniffConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *sniffConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// sniff peeks the head of conn, and returns the conn to read from the beginning and whether it is http.
+func sniff(conn net.Conn, timeout time.Duration) (net.Conn, bool, error) {
+	if timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+	c := &sniffConn{Conn: conn, r: bufio.NewReaderSize(conn, sniffLen)}
+	head, err := c.r.Peek(sniffLen)
+	if err != nil {
+		return nil, false, err
+	}
+	conn.SetReadDeadline(time.Time{})
+	return c, isHttpHead(head), nil
+}
+
+// connListener is a listener of the http connections sniffed from the listener of tars.
+type connListener struct {
+	addr  net.Addr
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+// Accept waits for the next sniffed connection.
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, errListenerClosed
+	}
+}
+
+// Close closes the listener, the connections sniffed afterwards are closed.
+func (l *connListener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+// Addr returns the address of the shared listener.
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
+
+func (l *connListener) dispatch(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.done:
+		conn.Close()
+	}
+}
+
+// h2cHandler serves http/2 cleartext besides http/1.x with handler.
+func h2cHandler(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	return h2c.NewHandler(handler, &http2.Server{})
+}
@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// echoProtocol echoes the packages which begin with their length in 4 bytes.
+type echoProtocol struct{}
+
+func (p *echoProtocol) Invoke(ctx context.Context, pkg []byte) []byte { return pkg }
+
+func (p *echoProtocol) ParsePackage(buff []byte) (int, int) {
+	if len(buff) < 4 {
+		return 0, PackageLess
+	}
+	length := int(binary.BigEndian.Uint32(buff[:4]))
+	if len(buff) < length {
+		return 0, PackageLess
+	}
+	return length, PackageFull
+}
+
+func (p *echoProtocol) InvokeTimeout(pkg []byte) []byte { return pkg }
+
+func (p *echoProtocol) GetCloseMsg() []byte { return nil }
+
+func (p *echoProtocol) DoClose(ctx context.Context) {}
+
+func TestSniff(t *testing.T) {
+	conf := &TarsServerConf{
+		Proto:         "tcp",
+		Address:       "127.0.0.1:0",
+		AcceptTimeout: 500 * time.Millisecond,
+		ReadTimeout:   500 * time.Millisecond,
+		IdleTimeout:   time.Second,
+	}
+	ts := NewTarsServer(&echoProtocol{}, conf)
+	ts.SetHttpServer(&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	})})
+	if err := ts.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go ts.Serve()
+	addr := ts.handle.(*tcpHandler).listener.Addr().String()
+
+	// tars
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := []byte{0, 0, 0, 9, 't', 'a', 'r', 's', '!'}
+	if _, err = conn.Write(pkg); err != nil {
+		t.Fatal(err)
+	}
+	rsp := make([]byte, len(pkg))
+	if _, err = io.ReadFull(conn, rsp); err != nil {
+		t.Fatal(err)
+	}
+	if string(rsp) != string(pkg) {
+		t.Errorf("tars got %q, want %q", rsp, pkg)
+	}
+	conn.Close()
+
+	// http/1.1 and http/2 cleartext
+	h2 := &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}
+	for _, c := range []struct {
+		client *http.Client
+		proto  string
+	}{
+		{http.DefaultClient, "HTTP/1.1"},
+		{&http.Client{Transport: h2}, "HTTP/2.0"},
+	} {
+		resp, err := c.client.Get("http://" + addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != c.proto {
+			t.Errorf("http got %q, want %q", body, c.proto)
+		}
+	}
+	h2.CloseIdleConnections()
+	http.DefaultClient.CloseIdleConnections()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = ts.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
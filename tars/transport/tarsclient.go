@@ -97,6 +97,11 @@ func (tc *TarsClient) Send(req []byte) error {
 	return nil
 }
 
+// QueueLen returns the number of the requests waiting to be sent.
+func (tc *TarsClient) QueueLen() int {
+	return len(tc.sendQueue)
+}
+
 // Close the client connection with the server.
 func (tc *TarsClient) Close() {
 	w := tc.conn
@@ -3,6 +3,8 @@ package transport
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
+	"net/http"
 	"sync/atomic"
 	"time"
 
@@ -35,17 +37,21 @@ type TarsServerConf struct {
 	TCPWriteBuffer int
 	TCPNoDelay     bool
 	TlsConfig      *tls.Config
+	UDPReaders     int
 }
 
 // TarsServer tars server struct.
 type TarsServer struct {
-	svr        ServerProtocol
-	conf       *TarsServerConf
-	handle     ServerHandler
-	lastInvoke time.Time
-	isClosed   int32
-	numInvoke  int32
-	numConn    int32
+	numDrop     int64 // first for the 64-bit alignment of atomic operations
+	numRejected int64
+	svr         ServerProtocol
+	conf        *TarsServerConf
+	handle      ServerHandler
+	lastInvoke  time.Time
+	isClosed    int32
+	numInvoke   int32
+	numConn     int32
+	httpSvr     *http.Server
 }
 
 // NewTarsServer new TarsServer and init with conf.
@@ -77,11 +83,27 @@ func (ts *TarsServer) Serve() error {
 
 // Listen listens on the network address
 func (ts *TarsServer) Listen() error {
+	if ts.httpSvr != nil && ts.conf.Proto != "tcp" {
+		return fmt.Errorf("http can not be served on %s", ts.conf.Proto)
+	}
 	ts.handle = ts.getHandler()
 	return ts.handle.Listen()
 }
 
-// Shutdown try to shutdown server gracefully.
+// DrainStat is the progress of the graceful shutdown of the server.
+type DrainStat struct {
+	Draining bool
+	// Conns is the number of the tcp connections not closed
+	Conns int
+	// NumInvoke is the number of the requests being invoked
+	NumInvoke int32
+	// Rejected is the number of the requests rejected during the shutdown
+	Rejected int64
+}
+
+// Shutdown try to shutdown server gracefully, the server stops accepting and notifies the clients to reconnect
+// at once, the requests received afterwards are rejected if the protocol implements ShutdownRejecter,
+// and the connections are closed after the invoking requests are done.
 func (ts *TarsServer) Shutdown(ctx context.Context) error {
 	// step 1: close listeners, notify client reconnect
 	atomic.StoreInt32(&ts.isClosed, 1)
@@ -103,11 +125,39 @@ func (ts *TarsServer) Shutdown(ctx context.Context) error {
 	}
 }
 
+// SetHttpServer sets the http server to serve on the same port, each accepted connection is routed
+// to the http server or the tars protocol by its first bytes. It must be set before Listen.
+func (ts *TarsServer) SetHttpServer(s *http.Server) {
+	ts.httpSvr = s
+}
+
 // GetConfig gets the tars server config.
 func (ts *TarsServer) GetConfig() *TarsServerConf {
 	return ts.conf
 }
 
+// DrainStat returns the progress of the graceful shutdown.
+func (ts *TarsServer) DrainStat() DrainStat {
+	stat := DrainStat{
+		Draining: atomic.LoadInt32(&ts.isClosed) == 1,
+		Rejected: atomic.LoadInt64(&ts.numRejected),
+	}
+	if h, ok := ts.handle.(connHandler); ok {
+		for _, c := range h.connStats() {
+			stat.Conns++
+			stat.NumInvoke += c.NumInvoke
+		}
+	} else {
+		stat.NumInvoke = atomic.LoadInt32(&ts.numInvoke)
+	}
+	return stat
+}
+
+// NumDrop returns the number of the packages dropped since the queue is full.
+func (ts *TarsServer) NumDrop() int64 {
+	return atomic.LoadInt64(&ts.numDrop)
+}
+
 // IsZombie show whether the server is hanged by the request.
 func (ts *TarsServer) IsZombie(timeout time.Duration) bool {
 	conf := ts.GetConfig()
@@ -0,0 +1,152 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// drainProtocol echoes the packages slowly if the second byte is 1, and rejects the packages during the shutdown
+// except the oneway packages whose second byte is 3.
+type drainProtocol struct {
+	echoProtocol
+	oneway chan []byte
+}
+
+var (
+	drainCloseMsg  = []byte{0, 0, 0, 5, 0xff}
+	drainRejectMsg = []byte{0, 0, 0, 5, 0xee}
+)
+
+func (p *drainProtocol) Invoke(ctx context.Context, pkg []byte) []byte {
+	switch pkg[4] {
+	case 1:
+		time.Sleep(500 * time.Millisecond)
+	case 3:
+		p.oneway <- pkg
+		return nil
+	}
+	return pkg
+}
+
+func (p *drainProtocol) GetCloseMsg() []byte { return drainCloseMsg }
+
+func (p *drainProtocol) RejectShutdown(pkg []byte) ([]byte, bool) {
+	if pkg[4] == 3 {
+		return nil, false
+	}
+	return drainRejectMsg, true
+}
+
+func readPkg(t *testing.T, conn net.Conn) []byte {
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	pkg := make([]byte, 5)
+	if _, err := io.ReadFull(conn, pkg); err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestShutdownDrain(t *testing.T) {
+	conf := &TarsServerConf{
+		Proto:         "tcp",
+		Address:       "127.0.0.1:0",
+		AcceptTimeout: 500 * time.Millisecond,
+		ReadTimeout:   500 * time.Millisecond,
+		IdleTimeout:   time.Minute,
+	}
+	proto := &drainProtocol{oneway: make(chan []byte, 1)}
+	ts := NewTarsServer(proto, conf)
+	if err := ts.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go ts.Serve()
+	addr := ts.handle.(*tcpHandler).listener.Addr().String()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	slow := []byte{0, 0, 0, 5, 1}
+	if _, err = conn.Write(slow); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); ts.DrainStat().NumInvoke != 1; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want 1 invoking request", ts.DrainStat())
+		}
+	}
+
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ts.Shutdown(ctx)
+		close(done)
+	}()
+	// the close message is sent before the invoking request is done
+	if pkg := readPkg(t, conn); string(pkg) != string(drainCloseMsg) {
+		t.Fatalf("got %v, want the close message", pkg)
+	}
+	if cost := time.Since(start); cost > 300*time.Millisecond {
+		t.Errorf("the close message is sent after %v", cost)
+	}
+	stat := ts.DrainStat()
+	if !stat.Draining || stat.Conns != 1 || stat.NumInvoke != 1 {
+		t.Errorf("got %+v, want 1 connection with 1 invoking request", stat)
+	}
+	// the new connections are refused
+	deadline := time.Now().Add(time.Second)
+	for {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			break
+		}
+		c.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("the new connection is accepted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the new request is rejected, and the invoking request is done
+	if _, err = conn.Write([]byte{0, 0, 0, 5, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if pkg := readPkg(t, conn); string(pkg) != string(drainRejectMsg) {
+		t.Fatalf("got %v, want the reject message", pkg)
+	}
+	// the oneway request is invoked, since the client can not retry it
+	oneway := []byte{0, 0, 0, 5, 3}
+	if _, err = conn.Write(oneway); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case pkg := <-proto.oneway:
+		if string(pkg) != string(oneway) {
+			t.Errorf("got %v, want the oneway request", pkg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the oneway request is not invoked")
+	}
+	if pkg := readPkg(t, conn); string(pkg) != string(slow) {
+		t.Fatalf("got %v, want the response of the invoking request", pkg)
+	}
+
+	// the connection is closed once it is drained
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if _, err = conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("got %v, want the connection closed by the server", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown is not done")
+	}
+	if stat = ts.DrainStat(); stat.Conns != 0 || stat.NumInvoke != 0 || stat.Rejected != 1 {
+		t.Errorf("got %+v, want 1 rejected request and no connection", stat)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"reflect"
 	"strings"
@@ -19,6 +20,10 @@ import (
 	"github.com/TarsCloud/TarsGo/tars/util/gtime"
 )
 
+// drainIdle is the time to close the connection without package after the close message is sent,
+// the connection is closed earlier if the client closes it after reconnecting.
+const drainIdle = time.Second
+
 type tcpHandler struct {
 	conf *TarsServerConf
 
@@ -31,12 +36,22 @@ type tcpHandler struct {
 	conns sync.Map
 
 	isListenClosed int32
+
+	// httpLn is the listener of the http connections when tars and http share the port
+	httpLn     *connListener
+	httpClosed int32
 }
 
 type connInfo struct {
-	conn      net.Conn
-	idleTime  int64
-	numInvoke int32
+	// the 64-bit fields first for the alignment of atomic operations
+	lastActive int64 // unix nano of the last package read or written
+	bytesIn    int64
+	bytesOut   int64
+	closeSent  int64 // unix nano of the close message sent
+	idleTime   int64 // unix seconds of the last read
+	conn       net.Conn
+	numInvoke  int32
+	closing    int32
 }
 
 func (h *tcpHandler) Listen() (err error) {
@@ -50,6 +65,11 @@ func (h *tcpHandler) Listen() (err error) {
 		} else {
 			h.listener = ln
 		}
+		if s := h.ts.httpSvr; s != nil {
+			TLOG.Infof("Serving http on %s", cfg.Address)
+			s.Handler = h2cHandler(s.Handler)
+			h.httpLn = newConnListener(ln.Addr())
+		}
 	} else {
 		TLOG.Infof("Listening on %s error: %v", cfg.Address, err)
 	}
@@ -88,7 +108,10 @@ func (h *tcpHandler) handleConn(connSt *connInfo, pkg []byte) {
 			return
 		}
 
-		if _, err := connSt.conn.Write(rsp); err != nil {
+		n, err := connSt.conn.Write(rsp)
+		atomic.AddInt64(&connSt.bytesOut, int64(n))
+		atomic.StoreInt64(&connSt.lastActive, time.Now().UnixNano())
+		if err != nil {
 			TLOG.Errorf("send pkg to %v failed %v", connSt.conn.RemoteAddr(), err)
 		}
 	}
@@ -103,6 +126,13 @@ func (h *tcpHandler) handleConn(connSt *connInfo, pkg []byte) {
 
 func (h *tcpHandler) Handle() error {
 	cfg := h.conf
+	if h.httpLn != nil {
+		go func() {
+			if err := h.ts.httpSvr.Serve(h.httpLn); err != nil && err != http.ErrServerClosed {
+				TLOG.Errorf("Serve http on %s error: %v", cfg.Address, err)
+			}
+		}()
+	}
 	for {
 		if atomic.LoadInt32(&h.ts.isClosed) == 1 {
 			TLOG.Errorf("Close accept %s %d", h.conf.Address, os.Getpid())
@@ -136,12 +166,29 @@ func (h *tcpHandler) Handle() error {
 			case *tls.Conn:
 				TLOG.Debugf("TLS accept: %s, %d", conn.RemoteAddr(), os.Getpid())
 			}
-			cf := &connInfo{conn: conn}
+			if h.httpLn != nil {
+				c, isHttp, err := sniff(conn, cfg.IdleTimeout)
+				if err != nil {
+					TLOG.Debugf("sniff %s error: %v", conn.RemoteAddr(), err)
+					conn.Close()
+					return
+				}
+				if isHttp {
+					h.httpLn.dispatch(c)
+					return
+				}
+				conn = c
+			}
+			cf := &connInfo{conn: conn, lastActive: time.Now().UnixNano()}
 			h.conns.Store(key, cf)
 			h.recv(cf)
 			h.conns.Delete(key)
 		}(conn)
 	}
+	// the new connections are refused, the inherited listener of the grace restart is not affected
+	if err := h.listener.Close(); err != nil {
+		TLOG.Errorf("Close listener %s error: %v", cfg.Address, err)
+	}
 	if h.gpool != nil {
 		h.gpool.Release()
 	}
@@ -151,29 +198,79 @@ func (h *tcpHandler) Handle() error {
 func (h *tcpHandler) OnShutdown() {
 	// close listeners
 	h.tcpListener.SetDeadline(time.Now())
-	if atomic.LoadInt32(&h.isListenClosed) == 1 {
-		h.sendCloseMsg()
-		atomic.StoreInt32(&h.isListenClosed, 2)
+	// notify the clients to reconnect at once, the connections accepted afterwards are notified by recv
+	h.sendCloseMsg()
+	if h.httpLn != nil {
+		go func() {
+			if err := h.ts.httpSvr.Shutdown(context.Background()); err != nil {
+				TLOG.Errorf("Shutdown http on %s error: %v", h.conf.Address, err)
+			}
+			atomic.StoreInt32(&h.httpClosed, 1)
+		}()
 	}
 }
 
 func (h *tcpHandler) sendCloseMsg() {
-	// send close-package
-	closeMsg := h.ts.svr.GetCloseMsg()
 	h.conns.Range(func(key, val interface{}) bool {
 		conn := val.(*connInfo)
-		if err := conn.conn.SetReadDeadline(time.Now()); err != nil {
-			TLOG.Errorf("SetReadDeadline: %w", err)
-		}
-		// send a reconnect-message
-		TLOG.Debugf("send close message to %v", conn.conn.RemoteAddr())
-		if _, err := conn.conn.Write(closeMsg); err != nil {
-			TLOG.Errorf("send closeMsg to %v failed %v", conn.conn.RemoteAddr(), err)
+		if h.sendConnCloseMsg(conn) {
+			// wake up the read to drain the connection
+			if err := conn.conn.SetReadDeadline(time.Now()); err != nil {
+				TLOG.Errorf("SetReadDeadline: %v", err)
+			}
 		}
 		return true
 	})
 }
 
+// sendConnCloseMsg sends the reconnect message to the connection once, and returns whether it is sent this time.
+func (h *tcpHandler) sendConnCloseMsg(connSt *connInfo) bool {
+	if !atomic.CompareAndSwapInt64(&connSt.closeSent, 0, time.Now().UnixNano()) {
+		return false
+	}
+	// send a reconnect-message
+	TLOG.Debugf("send close message to %v", connSt.conn.RemoteAddr())
+	n, err := connSt.conn.Write(h.ts.svr.GetCloseMsg())
+	atomic.AddInt64(&connSt.bytesOut, int64(n))
+	if err != nil {
+		TLOG.Errorf("send closeMsg to %v failed %v", connSt.conn.RemoteAddr(), err)
+	}
+	return true
+}
+
+// drained shows whether the connection can be closed during the shutdown, which has no invoking request
+// and no package within drainIdle since the close message is sent.
+func (h *tcpHandler) drained(connSt *connInfo) bool {
+	if atomic.LoadInt32(&connSt.numInvoke) > 0 {
+		return false
+	}
+	last := atomic.LoadInt64(&connSt.lastActive)
+	if sent := atomic.LoadInt64(&connSt.closeSent); sent > last {
+		last = sent
+	}
+	return time.Now().UnixNano()-last > int64(drainIdle)
+}
+
+// reject responds the request received during the shutdown without invoking it,
+// it returns false if the request is not rejected by the protocol.
+func (h *tcpHandler) reject(connSt *connInfo, r ShutdownRejecter, pkg []byte) bool {
+	rsp, rejected := r.RejectShutdown(pkg)
+	if !rejected {
+		return false
+	}
+	atomic.AddInt64(&h.ts.numRejected, 1)
+	atomic.StoreInt64(&connSt.lastActive, time.Now().UnixNano())
+	if len(rsp) == 0 {
+		return true
+	}
+	n, err := connSt.conn.Write(rsp)
+	atomic.AddInt64(&connSt.bytesOut, int64(n))
+	if err != nil {
+		TLOG.Errorf("send rejected pkg to %v failed %v", connSt.conn.RemoteAddr(), err)
+	}
+	return true
+}
+
 // CloseIdles close all idle connections(no active package within n secnods)
 func (h *tcpHandler) CloseIdles(n int64) bool {
 	if atomic.LoadInt32(&h.isListenClosed) == 0 {
@@ -191,12 +288,16 @@ func (h *tcpHandler) CloseIdles(n int64) bool {
 	allClosed := true
 	h.conns.Range(func(key, val interface{}) bool {
 		conn := val.(*connInfo)
-		TLOG.Debugf("num invoke %d %v", atomic.LoadInt32(&conn.numInvoke), conn.idleTime+n > time.Now().Unix())
-		if atomic.LoadInt32(&conn.numInvoke) > 0 || conn.idleTime+n > time.Now().Unix() {
+		idleTime := atomic.LoadInt64(&conn.idleTime)
+		TLOG.Debugf("num invoke %d %v", atomic.LoadInt32(&conn.numInvoke), idleTime+n > time.Now().Unix())
+		if atomic.LoadInt32(&conn.numInvoke) > 0 || idleTime+n > time.Now().Unix() {
 			allClosed = false
 		}
 		return true
 	})
+	if h.httpLn != nil && atomic.LoadInt32(&h.httpClosed) == 0 {
+		allClosed = false
+	}
 	return allClosed
 }
 
@@ -217,30 +318,36 @@ func (h *tcpHandler) recv(connSt *connInfo) {
 		ctx := h.getConnContext(connSt)
 		h.ts.svr.DoClose(ctx)
 
-		connSt.idleTime = 0
+		atomic.StoreInt64(&connSt.idleTime, 0)
 	}()
 
 	cfg := h.conf
 	buffer := make([]byte, 1024*4)
 	var currBuffer []byte // need a deep copy of buffer
-	connSt.idleTime = gtime.CurrUnixTime
+	atomic.StoreInt64(&connSt.idleTime, gtime.CurrUnixTime)
 	var n int
 	var err error
 	for {
+		if atomic.LoadInt32(&connSt.closing) == 1 {
+			// closed by admin
+			return
+		}
 		if atomic.LoadInt32(&h.ts.isClosed) == 1 {
-			// set short deadline to clear connection buffer
+			// notify the connection accepted during the shutdown,
+			// and set short deadline to close the connection once it is drained
+			h.sendConnCloseMsg(connSt)
 			conn.SetReadDeadline(time.Now().Add(time.Millisecond * 100))
 		} else if cfg.ReadTimeout > 0 {
 			conn.SetReadDeadline(time.Now().Add(cfg.ReadTimeout))
 		}
-		connSt.idleTime = time.Now().Unix()
+		atomic.StoreInt64(&connSt.idleTime, time.Now().Unix())
 		n, err = conn.Read(buffer)
 		// TLOG.Debugf("%s closed: %d, read %d, nil buff: %d, err: %v", h.ts.conf.Address, atomic.LoadInt32(&h.ts.isClosed), n, len(currBuffer), err)
 		if err != nil {
-			if atomic.LoadInt32(&h.ts.isClosed) == 1 && currBuffer == nil {
+			if atomic.LoadInt32(&h.ts.isClosed) == 1 && currBuffer == nil && (!isNoDataError(err) || h.drained(connSt)) {
 				return
 			}
-			if len(currBuffer) == 0 && connSt.numInvoke == 0 && (connSt.idleTime+int64(cfg.IdleTimeout)/int64(time.Second)) < time.Now().Unix() {
+			if len(currBuffer) == 0 && atomic.LoadInt32(&connSt.numInvoke) == 0 && (atomic.LoadInt64(&connSt.idleTime)+int64(cfg.IdleTimeout)/int64(time.Second)) < time.Now().Unix() {
 				return
 			}
 			if isNoDataError(err) {
@@ -253,6 +360,8 @@ func (h *tcpHandler) recv(connSt *connInfo) {
 			}
 			return
 		}
+		atomic.AddInt64(&connSt.bytesIn, int64(n))
+		atomic.StoreInt64(&connSt.lastActive, time.Now().UnixNano())
 		currBuffer = append(currBuffer, buffer[:n]...)
 		for {
 			pkgLen, status := h.ts.svr.ParsePackage(currBuffer)
@@ -260,11 +369,14 @@ func (h *tcpHandler) recv(connSt *connInfo) {
 				break
 			}
 			if status == PackageFull {
-				atomic.AddInt32(&connSt.numInvoke, 1)
 				pkg := make([]byte, pkgLen)
 				copy(pkg, currBuffer[:pkgLen])
 				currBuffer = currBuffer[pkgLen:]
-				h.handleConn(connSt, pkg)
+				r, ok := h.ts.svr.(ShutdownRejecter)
+				if !ok || atomic.LoadInt32(&h.ts.isClosed) == 0 || !h.reject(connSt, r, pkg) {
+					atomic.AddInt32(&connSt.numInvoke, 1)
+					h.handleConn(connSt, pkg)
+				}
 				if len(currBuffer) > 0 {
 					continue
 				}
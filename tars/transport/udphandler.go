@@ -4,11 +4,13 @@ import (
 	"context"
 	"net"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/TarsCloud/TarsGo/tars/protocol/res/basef"
 	"github.com/TarsCloud/TarsGo/tars/util/current"
+	"github.com/TarsCloud/TarsGo/tars/util/gpool"
 	"github.com/TarsCloud/TarsGo/tars/util/grace"
 )
 
@@ -16,25 +18,48 @@ type udpHandler struct {
 	conf *TarsServerConf
 	ts   *TarsServer
 
-	conn *net.UDPConn
+	// conns are the sockets with SO_REUSEPORT, each is read by its own goroutine
+	conns []*net.UDPConn
+
+	gpool *gpool.Pool
+	// jobs are the packages dispatched and not done
+	jobs sync.WaitGroup
+
+	// peers are the udp peers sent packages recently, addr -> *udpPeer
+	peers   sync.Map
+	numPeer int32
+}
+
+// udpPacket is a datagram read from the udp socket.
+type udpPacket struct {
+	data []byte
+	addr *net.UDPAddr
 }
 
 func (h *udpHandler) Listen() (err error) {
 	cfg := h.conf
-	h.conn, err = grace.CreateUDPConn(cfg.Address)
-	if err != nil {
+	h.conns, err = grace.CreateUDPConns(cfg.Address, cfg.UDPReaders)
+	if len(h.conns) == 0 {
 		return err
 	}
-	TLOG.Info("UDP listen", h.conn.LocalAddr())
+	if err != nil {
+		TLOG.Errorf("UDP listen %d of %d readers on %s: %v", len(h.conns), cfg.UDPReaders, cfg.Address, err)
+	}
+	TLOG.Info("UDP listen", h.conns[0].LocalAddr())
+
+	// init goroutine pool
+	if cfg.MaxInvoke > 0 {
+		h.gpool = gpool.NewPool(int(cfg.MaxInvoke), cfg.QueueCap)
+	}
 	return nil
 }
 
-func (h *udpHandler) getConnContext(udpAddr *net.UDPAddr) context.Context {
+func (h *udpHandler) getConnContext(conn *net.UDPConn, udpAddr *net.UDPAddr) context.Context {
 	ctx := current.ContextWithTarsCurrent(context.Background())
 	current.SetClientIPWithContext(ctx, udpAddr.IP.String())
 	current.SetClientPortWithContext(ctx, strconv.Itoa(udpAddr.Port))
 	current.SetRecvPkgTsFromContext(ctx, time.Now().UnixNano()/1e6)
-	current.SetRawConnWithContext(ctx, h.conn, udpAddr)
+	current.SetRawConnWithContext(ctx, conn, udpAddr)
 	return ctx
 }
 
@@ -42,19 +67,38 @@ func (h *udpHandler) Handle() error {
 	atomic.AddInt32(&h.ts.numConn, 1)
 	// wait invoke done
 	defer func() {
-		tick := time.NewTicker(time.Second)
-		defer tick.Stop()
-		for atomic.LoadInt32(&h.ts.numInvoke) > 0 {
-			<-tick.C
+		h.jobs.Wait()
+		if h.gpool != nil {
+			h.gpool.Release()
 		}
 		atomic.AddInt32(&h.ts.numConn, -1)
 	}()
-	buffer := make([]byte, 65535)
+	errs := make(chan error, len(h.conns))
+	for _, conn := range h.conns {
+		go func(conn *net.UDPConn) {
+			errs <- h.serve(conn)
+		}(conn)
+	}
+	var err error
+	for range h.conns {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+			// stop the other readers
+			h.closeConns()
+		}
+	}
+	return err
+}
+
+func (h *udpHandler) serve(conn *net.UDPConn) error {
+	reader := newUDPReader(conn)
+	var pkgs []udpPacket
 	for {
 		if atomic.LoadInt32(&h.ts.isClosed) == 1 {
 			return nil
 		}
-		n, udpAddr, err := h.conn.ReadFromUDP(buffer)
+		var err error
+		pkgs, err = reader.read(pkgs[:0])
 		if err != nil {
 			if atomic.LoadInt32(&h.ts.isClosed) == 1 {
 				return nil
@@ -66,27 +110,59 @@ func (h *udpHandler) Handle() error {
 				return err // TODO: check if necessary
 			}
 		}
-		pkg := make([]byte, n)
-		copy(pkg, buffer[0:n])
-		ctx := h.getConnContext(udpAddr)
-		go func() {
-			atomic.AddInt32(&h.ts.numInvoke, 1)
-			defer atomic.AddInt32(&h.ts.numInvoke, -1)
-			rsp := h.ts.invoke(ctx, pkg) // no need to check package
-
-			cPacketType, ok := current.GetPacketTypeFromContext(ctx)
-			if !ok {
-				TLOG.Error("Failed to GetPacketTypeFromContext")
-			}
+		for _, pkg := range pkgs {
+			h.dispatch(conn, pkg)
+		}
+	}
+}
 
-			if cPacketType == basef.TARSONEWAY {
-				return
-			}
+func (h *udpHandler) dispatch(conn *net.UDPConn, pkg udpPacket) {
+	ctx := h.getConnContext(conn, pkg.addr)
+	peer := h.trackPeer(pkg.addr.String(), len(pkg.data))
+	handler := func() {
+		defer h.jobs.Done()
+		defer atomic.AddInt32(&h.ts.numInvoke, -1)
+		rsp := h.ts.invoke(ctx, pkg.data) // no need to check package
 
-			if _, err := h.conn.WriteToUDP(rsp, udpAddr); err != nil {
-				TLOG.Errorf("send pkg to %v failed %v", udpAddr, err)
-			}
-		}()
+		cPacketType, ok := current.GetPacketTypeFromContext(ctx)
+		if !ok {
+			TLOG.Error("Failed to GetPacketTypeFromContext")
+		}
+
+		if cPacketType == basef.TARSONEWAY {
+			return
+		}
+
+		n, err := conn.WriteToUDP(rsp, pkg.addr)
+		if peer != nil {
+			atomic.AddInt64(&peer.bytesOut, int64(n))
+		}
+		if err != nil {
+			TLOG.Errorf("send pkg to %v failed %v", pkg.addr, err)
+		}
+	}
+
+	// the queued package is counted as invoking, so the sockets are not closed under it by the shutdown
+	h.jobs.Add(1)
+	atomic.AddInt32(&h.ts.numInvoke, 1)
+	if h.gpool == nil {
+		go handler()
+		return
+	}
+	// unlike tcp, there is no flow control for udp, so the package is dropped when the queue is full
+	select {
+	case h.gpool.JobQueue <- handler:
+	default:
+		h.jobs.Done()
+		atomic.AddInt32(&h.ts.numInvoke, -1)
+		atomic.AddInt64(&h.ts.numDrop, 1)
+		TLOG.Debugf("drop package from %v, the queue is full", pkg.addr)
+	}
+}
+
+func (h *udpHandler) closeConns() {
+	for _, conn := range h.conns {
+		conn.Close()
 	}
 }
 
@@ -94,8 +170,8 @@ func (h *udpHandler) OnShutdown() {
 }
 
 func (h *udpHandler) CloseIdles(n int64) bool {
-	if h.ts.numInvoke == 0 {
-		h.conn.Close()
+	if atomic.LoadInt32(&h.ts.numInvoke) == 0 {
+		h.closeConns()
 		return true
 	}
 	return false
@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// blockProtocol echoes the packages after release is closed.
+type blockProtocol struct {
+	echoProtocol
+	release chan struct{}
+}
+
+func (p *blockProtocol) Invoke(ctx context.Context, pkg []byte) []byte {
+	<-p.release
+	return pkg
+}
+
+func freeUDPAddr(t *testing.T) string {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().String()
+}
+
+func startUDPServer(t *testing.T, svr ServerProtocol, conf *TarsServerConf) *TarsServer {
+	ts := NewTarsServer(svr, conf)
+	if err := ts.Listen(); err != nil {
+		t.Fatal(err)
+	}
+	go ts.Serve()
+	return ts
+}
+
+func shutdown(t *testing.T, ts *TarsServer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ts.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUDPReaders(t *testing.T) {
+	conf := &TarsServerConf{Proto: "udp", Address: freeUDPAddr(t), MaxInvoke: 4, QueueCap: 100, UDPReaders: 4}
+	ts := startUDPServer(t, &echoProtocol{}, conf)
+	defer shutdown(t, ts)
+	if n := len(ts.handle.(*udpHandler).conns); n != 4 {
+		t.Fatalf("got %d sockets, want 4", n)
+	}
+
+	// the clients are balanced between the sockets by their ports
+	for i := 0; i < 8; i++ {
+		conn, err := net.Dial("udp4", conf.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg := []byte{0, 0, 0, 5, byte(i)}
+		if _, err = conn.Write(pkg); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		rsp := make([]byte, 16)
+		n, err := conn.Read(rsp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(rsp[:n]) != string(pkg) {
+			t.Errorf("got %v, want %v", rsp[:n], pkg)
+		}
+		conn.Close()
+	}
+}
+
+func TestUDPDrop(t *testing.T) {
+	svr := &blockProtocol{release: make(chan struct{})}
+	conf := &TarsServerConf{Proto: "udp", Address: freeUDPAddr(t), MaxInvoke: 1, QueueCap: 1}
+	ts := startUDPServer(t, svr, conf)
+	defer shutdown(t, ts)
+
+	conn, err := net.Dial("udp4", conf.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 10; i++ {
+		if _, err = conn.Write([]byte{0, 0, 0, 5, byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for ts.NumDrop() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(svr.release)
+	if ts.NumDrop() == 0 {
+		t.Fatal("no package dropped when the queue is full")
+	}
+
+	// the packages in the queue are still served
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	rsp := make([]byte, 16)
+	if _, err = conn.Read(rsp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUDPCloseIdles(t *testing.T) {
+	svr := &blockProtocol{release: make(chan struct{})}
+	conf := &TarsServerConf{Proto: "udp", Address: freeUDPAddr(t), MaxInvoke: 1, QueueCap: 10}
+	ts := startUDPServer(t, svr, conf)
+	defer shutdown(t, ts)
+
+	conn, err := net.Dial("udp4", conf.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 2; i++ {
+		if _, err = conn.Write([]byte{0, 0, 0, 5, byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// one package is invoking and the other is queued
+	for deadline := time.Now().Add(time.Second); ts.DrainStat().NumInvoke != 2; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %+v, want 2 invoking packages", ts.DrainStat())
+		}
+	}
+	if ts.handle.CloseIdles(0) {
+		t.Fatal("the sockets are closed with the queued package")
+	}
+	close(svr.release)
+	for i := 0; i < 2; i++ {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err = conn.Read(make([]byte, 16)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
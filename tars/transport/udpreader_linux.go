@@ -0,0 +1,45 @@
+//go:build linux
+// +build linux
+
+package transport
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+)
+
+// udpReadBatch is the max number of datagrams read by one recvmmsg.
+const udpReadBatch = 16
+
+// udpReader reads the datagrams in batch with recvmmsg.
+type udpReader struct {
+	conn *ipv4.PacketConn
+	msgs []ipv4.Message
+}
+
+func newUDPReader(conn *net.UDPConn) *udpReader {
+	msgs := make([]ipv4.Message, udpReadBatch)
+	for i := range msgs {
+		msgs[i].Buffers = [][]byte{make([]byte, 65535)}
+	}
+	return &udpReader{conn: ipv4.NewPacketConn(conn), msgs: msgs}
+}
+
+// read appends the datagrams read to pkgs, the data is copied from the buffer.
+func (r *udpReader) read(pkgs []udpPacket) ([]udpPacket, error) {
+	n, err := r.conn.ReadBatch(r.msgs, 0)
+	if err != nil {
+		return pkgs, err
+	}
+	for _, msg := range r.msgs[:n] {
+		addr, ok := msg.Addr.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		data := make([]byte, msg.N)
+		copy(data, msg.Buffers[0][:msg.N])
+		pkgs = append(pkgs, udpPacket{data: data, addr: addr})
+	}
+	return pkgs, nil
+}
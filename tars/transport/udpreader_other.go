@@ -0,0 +1,29 @@
+//go:build !linux
+// +build !linux
+
+package transport
+
+import (
+	"net"
+)
+
+// udpReader reads the datagrams one by one.
+type udpReader struct {
+	conn   *net.UDPConn
+	buffer []byte
+}
+
+func newUDPReader(conn *net.UDPConn) *udpReader {
+	return &udpReader{conn: conn, buffer: make([]byte, 65535)}
+}
+
+// read appends the datagram read to pkgs, the data is copied from the buffer.
+func (r *udpReader) read(pkgs []udpPacket) ([]udpPacket, error) {
+	n, udpAddr, err := r.conn.ReadFromUDP(r.buffer)
+	if err != nil {
+		return pkgs, err
+	}
+	data := make([]byte, n)
+	copy(data, r.buffer[:n])
+	return append(pkgs, udpPacket{data: data, addr: udpAddr}), nil
+}
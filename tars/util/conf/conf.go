@@ -4,9 +4,6 @@
 package conf
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/xml"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -32,6 +29,9 @@ type elem struct {
 	value    string
 	children map[string]*elem
 	line     []string
+	// the position in the config files
+	file   string
+	lineNo int
 }
 
 func newElem(kind int, name string) *elem {
@@ -199,13 +199,14 @@ func NewConf(fileName string) (*Conf, error) {
 	return c, nil
 }
 
-// InitFromFile returns error when init config from a file
+// InitFromFile returns error when init config from a file,
+// the relative paths of the included files are relative to the directory of the file.
 func (c *Conf) InitFromFile(fileName string) error {
 	content, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		return fmt.Errorf("read file %s error:%v", fileName, err)
 	}
-	return c.InitFromBytes(content)
+	return c.initFrom(content, fileName)
 }
 
 // InitFromString returns error when init config from a string
@@ -213,59 +214,21 @@ func (c *Conf) InitFromString(content string) error {
 	return c.InitFromBytes(([]byte)(content))
 }
 
-// InitFromBytes returns error when init config from bytes
+// InitFromBytes returns error when init config from bytes,
+// the relative paths of the included files are relative to the working directory.
 func (c *Conf) InitFromBytes(content []byte) error {
+	return c.initFrom(content, "")
+}
+
+func (c *Conf) initFrom(content []byte, fileName string) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	c.content = content
-	xmlDecoder := xml.NewDecoder(bytes.NewReader(c.content))
-	var nodeStack []*elem
-	nodeStack = append(nodeStack, c.root)
-	for {
-		currNode := nodeStack[len(nodeStack)-1]
-		token, _ := xmlDecoder.Token()
-		if token == nil {
-			break
-		}
-		switch t := token.(type) {
-		case xml.CharData:
-			lineDecoder := bufio.NewScanner(bytes.NewReader(t))
-			lineDecoder.Split(bufio.ScanLines)
-			for lineDecoder.Scan() {
-				line := strings.Trim(lineDecoder.Text(), whiteSpaceChars)
-				if (len(line) > 0 && line[0] == '#') || line == "" {
-					continue
-				}
-				// add Line data
-				currNode.addLine(line)
-				kv := strings.SplitN(line, "=", 2)
-				k, v := strings.Trim(kv[0], whiteSpaceChars), ""
-				if k == "" {
-					continue
-				}
-				if len(kv) == 2 {
-					v = strings.Trim(kv[1], whiteSpaceChars)
-				}
-				leaf := newElem(Leaf, k)
-				leaf.setValue(v)
-				currNode.addChild(k, leaf)
-			}
-		case xml.StartElement:
-			nodeName := t.Name.Local
-			node, ok := currNode.findChild(nodeName)
-			if !ok {
-				node = newElem(Node, nodeName)
-				currNode.addChild(nodeName, node)
-			}
-			nodeStack = append(nodeStack, node)
-		case xml.EndElement:
-			nodeName := t.Name.Local
-			if currNode.name != nodeName {
-				return fmt.Errorf("xml end not match :%s", nodeName)
-			}
-			nodeStack = nodeStack[:len(nodeStack)-1]
-		}
+	p := &parser{}
+	if err := p.parse(content, fileName, c.root); err != nil {
+		return err
 	}
+	p.substitute(c.root)
 	return nil
 }
 
@@ -346,6 +309,38 @@ func (c *Conf) GetMap(path string) map[string]string {
 	return kvMap
 }
 
+// Set sets the value for pointed path like /A/B<key>, the missing domains are added.
+func (c *Conf) Set(path string, value string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	pathVec := c.root.analysisPath(path)
+	if len(pathVec) == 0 {
+		return
+	}
+	node := c.root
+	for _, item := range pathVec[:len(pathVec)-1] {
+		child, ok := node.findChild(item)
+		if !ok || !child.isNode() {
+			child = newElem(Node, item)
+			node.addChild(item, child)
+		}
+		node = child
+	}
+	key := pathVec[len(pathVec)-1]
+	line := key + "=" + value
+	replaced := false
+	for i, l := range node.line {
+		if k := strings.SplitN(l, "=", 2)[0]; strings.Trim(k, whiteSpaceChars) == key {
+			node.line[i] = line
+			replaced = true
+		}
+	}
+	if !replaced {
+		node.addLine(line)
+	}
+	node.addChild(key, newElem(Leaf, key).setValue(value))
+}
+
 // ToString returns the config as a string
 func (c *Conf) ToString() string {
 	return c.root.toString(0)
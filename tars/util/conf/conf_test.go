@@ -24,3 +24,21 @@ func TestConf(t *testing.T) {
 	fmt.Println(d6)
 	fmt.Println(f1.ToString())
 }
+
+func TestSet(t *testing.T) {
+	c := New()
+	if err := c.InitFromString("<tars><application><server>\nmaxroutine=100\n</server></application></tars>"); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("/tars/application/server<maxroutine>", "200")
+	c.Set("/tars/application/client<locator>", "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890")
+	if v := c.GetInt("/tars/application/server<maxroutine>"); v != 200 {
+		t.Fatalf("unexpected maxroutine: %d", v)
+	}
+	if v := c.GetDomainLine("/tars/application/server"); len(v) != 1 || v[0] != "maxroutine=200" {
+		t.Fatalf("unexpected lines: %v", v)
+	}
+	if v := c.GetString("/tars/application/client<locator>"); v != "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890" {
+		t.Fatalf("unexpected locator: %s", v)
+	}
+}
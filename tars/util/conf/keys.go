@@ -0,0 +1,50 @@
+package conf
+
+// The known keys of the domains of the tars config read by the framework.
+var (
+	// ApplicationKeys are the keys of /tars/application.
+	ApplicationKeys = []string{"enableset", "setdivision"}
+	// ServerKeys are the keys of /tars/application/server.
+	ServerKeys = []string{
+		"node", "app", "server", "localip", "local", "logpath", "logsize", "lognum", "logLevel", "config", "notify",
+		"basepath", "datapath", "log", "accepttimeout", "readtimeout", "writetimeout", "handletimeout", "idletimeout",
+		"zombietimeout", "queuecap", "gracedowntimeout", "tcpreadbuffer", "tcpwritebuffer", "tcpnodelay", "maxroutine",
+		"propertyreportinterval", "statreportinterval", "mainloopticker", "statreportchannelbuflen", "maxPackageLength",
+		"configpollinterval", "hooktimeout", "gracerestarttimeout", "reflection", "health-address", "admin-http-port",
+		"admin-http-token", "admin-http-user", "admin-http-password", "key", "cert", "ca", "verifyclient", "ciphers",
+		"samplerate", "sampletype", "sampleaddress", "sampleencoding",
+	}
+	// ClientKeys are the keys of /tars/application/client.
+	ClientKeys = []string{
+		"locator", "stat", "property", "modulename", "async-invoke-timeout", "refresh-endpoint-interval",
+		"report-interval", "check-status-interval", "keep-alive-interval", "clientqueuelen", "clientidletimeout",
+		"clientreadtimeout", "clientwritetimeout", "clientdialtimeout", "reqdefaulttimeout", "objqueuemax",
+		"metrics-address", "metrics-path", "ca", "cert", "key", "ciphers",
+	}
+	// AdapterKeys are the keys of the adapters in /tars/application/server.
+	AdapterKeys = []string{
+		"endpoint", "servant", "protocol", "queuecap", "threads", "udpreaders", "key", "cert", "ca", "verifyclient",
+		"ciphers", "allow", "handlegroup", "maxconns", "queuetimeout", "shmcap", "shmkey",
+	}
+	// ClientObjKeys are the keys of the objs in /tars/application/client.
+	ClientObjKeys = []string{"ca", "cert", "key", "ciphers", "accesskey", "secretkey"}
+
+	// the keys generated by tarsnode for the other languages, which are ignored by tarsgo
+	nodeServerKeys = []string{
+		"deactivating-timeout", "activating-timeout", "netthread", "openthreadcontext", "threadcontextnum",
+		"threadcontextstack", "closecout", "mergenetasync", "opencoroutine", "coroutinememsize", "coroutinestack",
+		"manualListen",
+	}
+	nodeClientKeys = []string{"sync-invoke-timeout", "asyncthread", "sample-rate", "max-sample-count", "netthread"}
+)
+
+// TarsKeys returns the known keys of the domains of the tars config, which is used by Lint to find the unknown keys.
+func TarsKeys() map[string][]string {
+	return map[string][]string{
+		"/tars/application":          ApplicationKeys,
+		"/tars/application/server":   append(append([]string{}, ServerKeys...), nodeServerKeys...),
+		"/tars/application/client":   append(append([]string{}, ClientKeys...), nodeClientKeys...),
+		"/tars/application/server/*": AdapterKeys,
+		"/tars/application/client/*": ClientObjKeys,
+	}
+}
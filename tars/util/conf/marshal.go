@@ -0,0 +1,372 @@
+package conf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fieldTag is the tag of the struct field like `conf:"name,default=10,required"`.
+type fieldTag struct {
+	name     string
+	def      string
+	hasDef   bool
+	required bool
+}
+
+func parseFieldTag(field reflect.StructField) (fieldTag, bool) {
+	tag := field.Tag.Get("conf")
+	if tag == "-" {
+		return fieldTag{}, false
+	}
+	parts := strings.Split(tag, ",")
+	ft := fieldTag{name: parts[0]}
+	if ft.name == "" {
+		ft.name = strings.ToLower(field.Name)
+	}
+	for i := 1; i < len(parts); i++ {
+		switch {
+		case parts[i] == "required":
+			ft.required = true
+		case strings.HasPrefix(parts[i], "default="):
+			// the default value may contain commas
+			ft.def = strings.Join(append([]string{strings.TrimPrefix(parts[i], "default=")}, parts[i+1:]...), ",")
+			ft.hasDef = true
+			i = len(parts)
+		}
+	}
+	return ft, true
+}
+
+// Unmarshal binds the domain of the path to v, which must be a pointer to a struct.
+// The fields are bound by the tag `conf:"name,default=value,required"`, the name is the lower case field name by default:
+//
+//	the scalar fields are bound to the values of the domain, the time.Duration fields accept
+//	the durations like 1.5s and the milliseconds
+//	the struct and pointer to struct fields are bound to the child domains
+//	the slice fields are bound to the lines of the child domain
+//	the map fields are bound to the values of the child domain, or the child domains of it if the values are structs
+//
+// It returns error if the required field is missing or the value is invalid.
+func (c *Conf) Unmarshal(path string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("conf: Unmarshal needs a non-nil pointer to a struct")
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	e, err := c.root.getElem(c.root.analysisPath(path))
+	if err != nil || !e.isNode() {
+		return fmt.Errorf("conf: domain %s not found", path)
+	}
+	return unmarshalStruct(e, rv.Elem(), strings.TrimRight(path, "/"))
+}
+
+func unmarshalStruct(e *elem, rv reflect.Value, path string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		ft, ok := parseFieldTag(field)
+		if !ok {
+			continue
+		}
+		if err := unmarshalField(e, rv.Field(i), path, ft); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalField(e *elem, fv reflect.Value, path string, ft fieldTag) error {
+	child, ok := e.findChild(ft.name)
+	if fv.Type() != durationType {
+		switch fv.Kind() {
+		case reflect.Struct, reflect.Ptr, reflect.Slice, reflect.Map:
+			if !ok || !child.isNode() {
+				if ft.required {
+					return fmt.Errorf("conf: required domain %s/%s is missing", path, ft.name)
+				}
+				return nil
+			}
+			return unmarshalDomain(child, fv, path+"/"+ft.name)
+		}
+	}
+	value := ft.def
+	if ok && child.isLeaf() {
+		value = child.value
+	} else if ft.required {
+		return fmt.Errorf("conf: required key %s<%s> is missing", path, ft.name)
+	} else if !ft.hasDef {
+		return nil
+	}
+	if err := setValue(fv, value); err != nil {
+		return fmt.Errorf("conf: invalid value of %s<%s>: %v", path, ft.name, err)
+	}
+	return nil
+}
+
+func unmarshalDomain(e *elem, fv reflect.Value, path string) error {
+	switch fv.Kind() {
+	case reflect.Struct:
+		return unmarshalStruct(e, fv, path)
+	case reflect.Ptr:
+		if fv.Type().Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("conf: unsupported type %s of %s", fv.Type(), path)
+		}
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return unmarshalStruct(e, fv.Elem(), path)
+	case reflect.Slice:
+		s := reflect.MakeSlice(fv.Type(), 0, len(e.line))
+		for _, line := range e.line {
+			item := reflect.New(fv.Type().Elem()).Elem()
+			if err := setValue(item, line); err != nil {
+				return fmt.Errorf("conf: invalid line of %s: %v", path, err)
+			}
+			s = reflect.Append(s, item)
+		}
+		fv.Set(s)
+		return nil
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("conf: unsupported type %s of %s", fv.Type(), path)
+		}
+		m := reflect.MakeMap(fv.Type())
+		valueType := fv.Type().Elem()
+		for name, child := range e.children {
+			item := reflect.New(valueType).Elem()
+			if valueType.Kind() == reflect.Struct {
+				if !child.isNode() {
+					continue
+				}
+				if err := unmarshalStruct(child, item, path+"/"+name); err != nil {
+					return err
+				}
+			} else {
+				if !child.isLeaf() {
+					continue
+				}
+				if err := setValue(item, child.value); err != nil {
+					return fmt.Errorf("conf: invalid value of %s<%s>: %v", path, name, err)
+				}
+			}
+			m.SetMapIndex(reflect.ValueOf(name).Convert(fv.Type().Key()), item)
+		}
+		fv.Set(m)
+		return nil
+	}
+	return fmt.Errorf("conf: unsupported type %s of %s", fv.Type(), path)
+}
+
+func setValue(fv reflect.Value, value string) error {
+	if fv.Type() == durationType {
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// parseDuration parses the durations like 1.5s, and the integers as the milliseconds like the tars config.
+func parseDuration(value string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseBool also accepts Y and N like <enableset>.
+func parseBool(value string) (bool, error) {
+	switch strings.ToUpper(value) {
+	case "Y", "YES":
+		return true, nil
+	case "N", "NO", "":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// Marshal returns the tars config of v in the domain of the name, which is the reverse of Unmarshal,
+// the fields of v are written without the domain if the name is empty.
+func Marshal(name string, v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("conf: Marshal needs a non-nil struct")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("conf: Marshal needs a struct")
+	}
+	buf := &bytes.Buffer{}
+	depth := 0
+	if name != "" {
+		fmt.Fprintf(buf, "<%s>\n", name)
+		depth = 1
+	}
+	if err := marshalStruct(buf, rv, depth); err != nil {
+		return nil, err
+	}
+	if name != "" {
+		fmt.Fprintf(buf, "</%s>\n", name)
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalStruct(buf *bytes.Buffer, rv reflect.Value, depth int) error {
+	rt := rv.Type()
+	indent := strings.Repeat("    ", depth)
+	// the values are written before the domains
+	var domains []int
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		ft, ok := parseFieldTag(field)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Type() != durationType {
+			switch fv.Kind() {
+			case reflect.Struct, reflect.Ptr, reflect.Slice, reflect.Map:
+				domains = append(domains, i)
+				continue
+			}
+		}
+		value, err := formatValue(fv)
+		if err != nil {
+			return fmt.Errorf("conf: %s: %v", field.Name, err)
+		}
+		fmt.Fprintf(buf, "%s%s=%s\n", indent, ft.name, value)
+	}
+	for _, i := range domains {
+		ft, _ := parseFieldTag(rt.Field(i))
+		if err := marshalDomain(buf, ft.name, rv.Field(i), depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func marshalDomain(buf *bytes.Buffer, name string, fv reflect.Value, depth int) error {
+	indent := strings.Repeat("    ", depth)
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			return nil
+		}
+		return marshalDomain(buf, name, fv.Elem(), depth)
+	case reflect.Struct:
+		fmt.Fprintf(buf, "%s<%s>\n", indent, name)
+		if err := marshalStruct(buf, fv, depth+1); err != nil {
+			return err
+		}
+	case reflect.Slice:
+		if fv.Len() == 0 {
+			return nil
+		}
+		fmt.Fprintf(buf, "%s<%s>\n", indent, name)
+		for i := 0; i < fv.Len(); i++ {
+			value, err := formatValue(fv.Index(i))
+			if err != nil {
+				return fmt.Errorf("conf: %s: %v", name, err)
+			}
+			fmt.Fprintf(buf, "%s    %s\n", indent, value)
+		}
+	case reflect.Map:
+		if fv.Len() == 0 {
+			return nil
+		}
+		keys := fv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		fmt.Fprintf(buf, "%s<%s>\n", indent, name)
+		for _, key := range keys {
+			item := fv.MapIndex(key)
+			if item.Kind() == reflect.Struct {
+				if err := marshalDomain(buf, key.String(), item, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			value, err := formatValue(item)
+			if err != nil {
+				return fmt.Errorf("conf: %s: %v", name, err)
+			}
+			fmt.Fprintf(buf, "%s    %s=%s\n", indent, key.String(), value)
+		}
+	default:
+		return fmt.Errorf("conf: unsupported type %s of %s", fv.Type(), name)
+	}
+	fmt.Fprintf(buf, "%s</%s>\n", indent, name)
+	return nil
+}
+
+func formatValue(fv reflect.Value) (string, error) {
+	if fv.Type() == durationType {
+		return time.Duration(fv.Int()).String(), nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		s := fv.String()
+		if strings.ContainsAny(s, "\n<>") {
+			return "", fmt.Errorf("invalid value %q", s)
+		}
+		return s, nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'g', -1, fv.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", fv.Type())
+}
@@ -0,0 +1,140 @@
+package conf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAdapter struct {
+	Endpoint string `conf:"endpoint,required"`
+	Threads  int    `conf:"threads,default=1"`
+}
+
+type testServer struct {
+	App           string                 `conf:"app,required"`
+	Server        string                 `conf:"server"`
+	MaxRoutine    int32                  `conf:"maxroutine,default=100"`
+	HandleTimeout time.Duration          `conf:"handletimeout"`
+	IdleTimeout   time.Duration          `conf:"idletimeout,default=10m"`
+	Enable        bool                   `conf:"enable"`
+	Rate          float64                `conf:"rate"`
+	Ignored       string                 `conf:"-"`
+	Nodes         []string               `conf:"nodes"`
+	Adapters      map[string]testAdapter `conf:"adapters"`
+	Labels        map[string]string      `conf:"labels"`
+	Log           *struct {
+		Level string `conf:"level,default=INFO"`
+	} `conf:"log"`
+}
+
+const testServerConf = `<tars>
+	<server>
+		app=TestApp
+		server=HelloServer
+		handletimeout=1500
+		enable=Y
+		rate=0.5
+		<nodes>
+			127.0.0.1:80
+			127.0.0.2:80
+		</nodes>
+		<adapters>
+			<HelloObjAdapter>
+				endpoint=tcp -h 127.0.0.1 -p 10015 -t 60000
+				threads=4
+			</HelloObjAdapter>
+			<EchoObjAdapter>
+				endpoint=tcp -h 127.0.0.1 -p 10016 -t 60000
+			</EchoObjAdapter>
+		</adapters>
+		<labels>
+			zone=sz
+		</labels>
+		<log>
+		</log>
+	</server>
+</tars>`
+
+func TestUnmarshal(t *testing.T) {
+	c := New()
+	if err := c.InitFromString(testServerConf); err != nil {
+		t.Fatal(err)
+	}
+	var s testServer
+	s.Ignored = "keep"
+	if err := c.Unmarshal("/tars/server", &s); err != nil {
+		t.Fatal(err)
+	}
+	want := testServer{
+		App:           "TestApp",
+		Server:        "HelloServer",
+		MaxRoutine:    100,
+		HandleTimeout: 1500 * time.Millisecond,
+		IdleTimeout:   10 * time.Minute,
+		Enable:        true,
+		Rate:          0.5,
+		Ignored:       "keep",
+		Nodes:         []string{"127.0.0.1:80", "127.0.0.2:80"},
+		Adapters: map[string]testAdapter{
+			"HelloObjAdapter": {Endpoint: "tcp -h 127.0.0.1 -p 10015 -t 60000", Threads: 4},
+			"EchoObjAdapter":  {Endpoint: "tcp -h 127.0.0.1 -p 10016 -t 60000", Threads: 1},
+		},
+		Labels: map[string]string{"zone": "sz"},
+	}
+	want.Log = &struct {
+		Level string `conf:"level,default=INFO"`
+	}{Level: "INFO"}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("unexpected server: %+v", s)
+	}
+
+	// marshal and unmarshal again
+	data, err := Marshal("server", &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "<server>\n    app=TestApp\n") ||
+		!strings.Contains(string(data), "    <adapters>\n        <EchoObjAdapter>\n") {
+		t.Fatalf("unexpected config: %s", data)
+	}
+	c2 := New()
+	if err = c2.InitFromBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	var s2 testServer
+	s2.Ignored = "keep"
+	if err = c2.Unmarshal("/server", &s2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s2, want) {
+		t.Fatalf("unexpected server: %+v\n%s", s2, data)
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	c := New()
+	if err := c.InitFromString("<tars><server>\nserver=HelloServer\nmaxroutine=abc\n</server></tars>"); err != nil {
+		t.Fatal(err)
+	}
+	var s testServer
+	if err := c.Unmarshal("/tars/server", &s); err == nil || err.Error() != "conf: required key /tars/server<app> is missing" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Set("/tars/server<app>", "TestApp")
+	if err := c.Unmarshal("/tars/server", &s); err == nil || !strings.HasPrefix(err.Error(), "conf: invalid value of /tars/server<maxroutine>") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Set("/tars/server<maxroutine>", "1")
+	c.Set("/tars/server/adapters/HelloObjAdapter<threads>", "1")
+	if err := c.Unmarshal("/tars/server", &s); err == nil || err.Error() != "conf: required key /tars/server/adapters/HelloObjAdapter<endpoint> is missing" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Unmarshal("/tars/client", &s); err == nil {
+		t.Fatal("the missing domain should fail")
+	}
+	if err := c.Unmarshal("/tars/server", s); err == nil {
+		t.Fatal("the struct should fail")
+	}
+}
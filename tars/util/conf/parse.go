@@ -0,0 +1,326 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxIncludeDepth limits the nested includes.
+const maxIncludeDepth = 16
+
+// varPattern matches the variables like ${HOME} and ${/tars/application/server<app>}.
+var varPattern = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// rawVarPattern matches the variables in the raw content, whose <key> is escaped before parsing the xml.
+var rawVarPattern = regexp.MustCompile(`\$\{[^{}\n]*\}`)
+
+// Issue is the problem in the config file found by Lint, which is also the error of the include and the xml structure.
+type Issue struct {
+	File string
+	Line int
+	Msg  string
+}
+
+// Error implements error.
+func (i *Issue) Error() string {
+	return i.String()
+}
+
+// String returns the issue like file:line: msg.
+func (i *Issue) String() string {
+	file := i.File
+	if file == "" {
+		file = "<config>"
+	}
+	return fmt.Sprintf("%s:%d: %s", file, i.Line, i.Msg)
+}
+
+// parser parses the tars config into the tree, the includes are parsed into the domains where they are.
+type parser struct {
+	// lint reports the issues instead of ignoring them
+	lint   bool
+	issues []*Issue
+	// files are the including files to find the include cycle
+	files []string
+}
+
+func (p *parser) report(file string, line int, format string, v ...interface{}) {
+	if p.lint {
+		p.issues = append(p.issues, &Issue{File: file, Line: line, Msg: fmt.Sprintf(format, v...)})
+	}
+}
+
+// lineCounter returns the line numbers of the offsets in the content, which must be increasing.
+type lineCounter struct {
+	content []byte
+	offset  int64
+	line    int
+}
+
+func (l *lineCounter) at(offset int64) int {
+	if l.line == 0 {
+		l.line = 1
+	}
+	if offset > int64(len(l.content)) {
+		offset = int64(len(l.content))
+	}
+	if offset > l.offset {
+		l.line += bytes.Count(l.content[l.offset:offset], []byte("\n"))
+		l.offset = offset
+	}
+	return l.line
+}
+
+func (p *parser) parse(content []byte, file string, root *elem) error {
+	type frame struct {
+		node *elem
+		name string
+	}
+	content = rawVarPattern.ReplaceAllFunc(content, func(v []byte) []byte {
+		v = bytes.ReplaceAll(v, []byte("<"), []byte("&lt;"))
+		return bytes.ReplaceAll(v, []byte(">"), []byte("&gt;"))
+	})
+	xmlDecoder := xml.NewDecoder(bytes.NewReader(content))
+	// the mismatched end tags are reported with the line numbers below
+	xmlDecoder.Strict = false
+	lines := &lineCounter{content: content}
+	nodeStack := []frame{{node: root, name: root.name}}
+	for {
+		offset := xmlDecoder.InputOffset()
+		token, err := xmlDecoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the content after the malformed xml is ignored
+			if se, ok := err.(*xml.SyntaxError); ok {
+				p.report(file, se.Line, "malformed xml: %s", se.Msg)
+			} else {
+				p.report(file, lines.at(offset), "malformed xml: %v", err)
+			}
+			break
+		}
+		curr := nodeStack[len(nodeStack)-1]
+		switch t := token.(type) {
+		case xml.CharData:
+			base := lines.at(offset)
+			for i, l := range strings.Split(string(t), "\n") {
+				line := strings.Trim(l, whiteSpaceChars+"\r")
+				if (len(line) > 0 && line[0] == '#') || line == "" {
+					continue
+				}
+				// add Line data
+				curr.node.addLine(line)
+				kv := strings.SplitN(line, "=", 2)
+				k, v := strings.Trim(kv[0], whiteSpaceChars), ""
+				if k == "" {
+					continue
+				}
+				if len(kv) == 2 {
+					v = strings.Trim(kv[1], whiteSpaceChars)
+				}
+				leaf := newElem(Leaf, k)
+				leaf.setValue(v)
+				leaf.file, leaf.lineNo = file, base+i
+				curr.node.addChild(k, leaf)
+			}
+		case xml.StartElement:
+			nodeName := t.Name.Local
+			if nodeName == "include" {
+				if err = p.include(t, file, lines.at(offset), curr.node); err != nil {
+					return err
+				}
+				// the end of the include is matched by the name
+				nodeStack = append(nodeStack, frame{node: curr.node, name: nodeName})
+				continue
+			}
+			node, ok := curr.node.findChild(nodeName)
+			if !ok || !node.isNode() {
+				node = newElem(Node, nodeName)
+				node.file, node.lineNo = file, lines.at(offset)
+				curr.node.addChild(nodeName, node)
+			}
+			nodeStack = append(nodeStack, frame{node: node, name: nodeName})
+		case xml.EndElement:
+			nodeName := t.Name.Local
+			if curr.name != nodeName || len(nodeStack) == 1 {
+				return &Issue{File: file, Line: lines.at(offset), Msg: fmt.Sprintf("xml end not match :%s", nodeName)}
+			}
+			nodeStack = nodeStack[:len(nodeStack)-1]
+		}
+	}
+	return nil
+}
+
+// include parses the file of <include file="common.conf"/> into the node.
+func (p *parser) include(t xml.StartElement, file string, line int, node *elem) error {
+	var name string
+	for _, attr := range t.Attr {
+		if attr.Name.Local == "file" {
+			name = attr.Value
+		}
+	}
+	if name == "" {
+		return &Issue{File: file, Line: line, Msg: "include without file"}
+	}
+	if !filepath.IsAbs(name) && file != "" {
+		name = filepath.Join(filepath.Dir(file), name)
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return &Issue{File: file, Line: line, Msg: fmt.Sprintf("include %s error: %v", name, err)}
+	}
+	for _, f := range p.files {
+		if f == abs {
+			return &Issue{File: file, Line: line, Msg: fmt.Sprintf("include cycle of %s", name)}
+		}
+	}
+	if len(p.files) >= maxIncludeDepth {
+		return &Issue{File: file, Line: line, Msg: fmt.Sprintf("include %s exceeds the max depth %d", name, maxIncludeDepth)}
+	}
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		return &Issue{File: file, Line: line, Msg: fmt.Sprintf("include %s error: %v", name, err)}
+	}
+	p.files = append(p.files, abs)
+	defer func() {
+		p.files = p.files[:len(p.files)-1]
+	}()
+	return p.parse(content, name, node)
+}
+
+// substitute replaces the variables like ${HOME} by the environment variables and
+// ${/tars/application/server<app>} by the values of the path, the undefined variables are kept.
+func (p *parser) substitute(root *elem) {
+	var walk func(e *elem)
+	walk = func(e *elem) {
+		if e.isLeaf() {
+			e.value = p.expand(root, e, e.value, nil)
+			return
+		}
+		for i, line := range e.line {
+			e.line[i] = p.expand(root, e, line, nil)
+		}
+		for _, child := range e.children {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// expand replaces the variables in the value of e, the issues are reported by the leaves only
+// since the lines of the domains are the same as the leaves.
+func (p *parser) expand(root *elem, e *elem, value string, visiting []string) string {
+	if !strings.Contains(value, "${") {
+		return value
+	}
+	report := func(format string, v ...interface{}) {
+		if e.isLeaf() {
+			p.report(e.file, e.lineNo, format, v...)
+		}
+	}
+	return varPattern.ReplaceAllStringFunc(value, func(v string) string {
+		name := v[2 : len(v)-1]
+		if !strings.HasPrefix(name, "/") {
+			if env, ok := os.LookupEnv(name); ok {
+				return env
+			}
+			report("undefined variable %s", v)
+			return v
+		}
+		for _, path := range visiting {
+			if path == name {
+				report("variable cycle of %s", v)
+				return v
+			}
+		}
+		ref, err := root.getValue(name)
+		if err != nil {
+			report("undefined variable %s", v)
+			return v
+		}
+		return p.expand(root, e, ref, append(visiting, name))
+	})
+}
+
+// Lint parses the config file strictly and returns the issues of the malformed xml, the includes,
+// the undefined variables, and the unknown keys if keys are given, which maps the domain paths like
+// /tars/application/server to the known keys of them, where * matches any domain like /tars/application/server/*.
+func Lint(fileName string, keys map[string][]string) ([]*Issue, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{lint: true}
+	root := newElem(Node, "root")
+	if err = p.parse(content, fileName, root); err != nil {
+		if issue, ok := err.(*Issue); ok {
+			p.issues = append(p.issues, issue)
+		} else {
+			return nil, err
+		}
+	}
+	p.substitute(root)
+	if len(keys) > 0 {
+		lintKeys(p, root, "", keys)
+	}
+	sort.SliceStable(p.issues, func(i, j int) bool {
+		if p.issues[i].File != p.issues[j].File {
+			return p.issues[i].File < p.issues[j].File
+		}
+		return p.issues[i].Line < p.issues[j].Line
+	})
+	return p.issues, nil
+}
+
+func lintKeys(p *parser, e *elem, path string, keys map[string][]string) {
+	known, ok := matchDomain(path, keys)
+	names := make([]string, 0, len(e.children))
+	for name := range e.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := e.children[name]
+		if child.isNode() {
+			lintKeys(p, child, path+"/"+name, keys)
+			continue
+		}
+		if ok && !known[name] {
+			p.report(child.file, child.lineNo, "unknown key %s<%s>", path, name)
+		}
+	}
+}
+
+func matchDomain(path string, keys map[string][]string) (map[string]bool, bool) {
+	segs := strings.Split(path, "/")
+	for domain, domainKeys := range keys {
+		patterns := strings.Split(strings.TrimRight(domain, "/"), "/")
+		if len(patterns) != len(segs) {
+			continue
+		}
+		matched := true
+		for i := range patterns {
+			if patterns[i] != "*" && patterns[i] != segs[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			known := make(map[string]bool, len(domainKeys))
+			for _, k := range domainKeys {
+				known[k] = true
+			}
+			return known, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,130 @@
+package conf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInclude(t *testing.T) {
+	os.Setenv("TARS_CONF_TEST_PATH", "/data/app")
+	defer os.Unsetenv("TARS_CONF_TEST_PATH")
+	dir := writeFiles(t, map[string]string{
+		"main.conf": `<tars>
+  <application>
+    <server>
+      app=TestApp
+      basepath=${TARS_CONF_TEST_PATH}/bin
+      datapath=${/tars/application/server<basepath>}/data
+      home=${TARS_CONF_TEST_UNDEFINED}
+    </server>
+    <include file="client.conf"/>
+  </application>
+</tars>`,
+		"client.conf": `<client>
+  locator=tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890
+  modulename=${/tars/application/server<app>}.Server
+</client>`,
+	})
+	c, err := NewConf(filepath.Join(dir, "main.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"/tars/application/server<basepath>":   "/data/app/bin",
+		"/tars/application/server<datapath>":   "/data/app/bin/data",
+		"/tars/application/server<home>":       "${TARS_CONF_TEST_UNDEFINED}",
+		"/tars/application/client<locator>":    "tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890",
+		"/tars/application/client<modulename>": "TestApp.Server",
+	}
+	for path, want := range cases {
+		if v := c.GetString(path); v != want {
+			t.Errorf("unexpected %s: %s", path, v)
+		}
+	}
+	if v := c.GetDomainLine("/tars/application/server"); len(v) != 4 || v[1] != "basepath=/data/app/bin" {
+		t.Errorf("unexpected lines: %v", v)
+	}
+
+	dir = writeFiles(t, map[string]string{
+		"a.conf": `<tars><include file="b.conf"/></tars>`,
+		"b.conf": `<include file="a.conf"/>`,
+	})
+	if _, err = NewConf(filepath.Join(dir, "a.conf")); err == nil {
+		t.Error("include cycle is not found")
+	}
+	if _, err = NewConf(filepath.Join(dir, "c.conf")); err == nil {
+		t.Error("missing file is not found")
+	}
+}
+
+func TestLint(t *testing.T) {
+	keys := map[string][]string{
+		"/tars/application/server":   {"app", "server", "logpath"},
+		"/tars/application/server/*": {"endpoint", "servant"},
+	}
+	dir := writeFiles(t, map[string]string{
+		"main.conf": `<tars>
+  <application>
+    <server>
+      app=TestApp
+      servr=HelloGo
+      logpath=${TARS_CONF_TEST_UNDEFINED}
+      <TestApp.HelloGo.HelloObjAdapter>
+        endpoint=tcp -h 127.0.0.1 -p 10015
+        servant=TestApp.HelloGo.HelloObj
+        thread=1
+      </TestApp.HelloGo.HelloObjAdapter>
+    </server>
+    <include file="client.conf"/>
+  </application>
+</tars>`,
+		"client.conf": `<client>
+  locator=tars.tarsregistry.QueryObj@tcp -h 127.0.0.1 -p 17890
+</clent>`,
+		"bad.conf": `<tars>
+  <application>
+  </server>
+</tars>`,
+	})
+	issues, err := Lint(filepath.Join(dir, "main.conf"), keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Issue{
+		{File: filepath.Join(dir, "client.conf"), Line: 3},
+		{File: filepath.Join(dir, "main.conf"), Line: 5},
+		{File: filepath.Join(dir, "main.conf"), Line: 6},
+		{File: filepath.Join(dir, "main.conf"), Line: 10},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+	for i, issue := range issues {
+		if issue.File != want[i].File || issue.Line != want[i].Line {
+			t.Errorf("unexpected issue: %v", issue)
+		}
+	}
+
+	issues, err = Lint(filepath.Join(dir, "bad.conf"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Line != 3 {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+	if _, err = Lint(filepath.Join(dir, "missing.conf"), nil); err == nil {
+		t.Error("missing file is not found")
+	}
+}
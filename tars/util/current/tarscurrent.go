@@ -25,6 +25,9 @@ type Current struct {
 	needDyeing  bool
 	dyeingUser  string
 	trace       *tarstrace.Trace
+	servantName string
+	funcName    string
+	requestID   int32
 
 	rawConn net.Conn
 	udpAddr *net.UDPAddr
@@ -78,6 +81,60 @@ func SetClientPortWithContext(ctx context.Context, port string) bool {
 	return ok
 }
 
+// GetServantNameFromContext gets the servant name of the request from the context.
+func GetServantNameFromContext(ctx context.Context) (string, bool) {
+	tc, ok := currentFromContext(ctx)
+	if ok {
+		return tc.servantName, ok
+	}
+	return "", ok
+}
+
+// SetServantNameWithContext set the servant name of the request to the tars current.
+func SetServantNameWithContext(ctx context.Context, servantName string) bool {
+	tc, ok := currentFromContext(ctx)
+	if ok {
+		tc.servantName = servantName
+	}
+	return ok
+}
+
+// GetFuncNameFromContext gets the func name of the request from the context.
+func GetFuncNameFromContext(ctx context.Context) (string, bool) {
+	tc, ok := currentFromContext(ctx)
+	if ok {
+		return tc.funcName, ok
+	}
+	return "", ok
+}
+
+// SetFuncNameWithContext set the func name of the request to the tars current.
+func SetFuncNameWithContext(ctx context.Context, funcName string) bool {
+	tc, ok := currentFromContext(ctx)
+	if ok {
+		tc.funcName = funcName
+	}
+	return ok
+}
+
+// GetRequestIDFromContext gets the request id of the request from the context.
+func GetRequestIDFromContext(ctx context.Context) (int32, bool) {
+	tc, ok := currentFromContext(ctx)
+	if ok {
+		return tc.requestID, ok
+	}
+	return 0, ok
+}
+
+// SetRequestIDWithContext set the request id of the request to the tars current.
+func SetRequestIDWithContext(ctx context.Context, requestID int32) bool {
+	tc, ok := currentFromContext(ctx)
+	if ok {
+		tc.requestID = requestID
+	}
+	return ok
+}
+
 // currentFromContext gets current from the context
 func currentFromContext(ctx context.Context) (*Current, bool) {
 	tc, ok := ctx.Value(tcKey).(*Current)
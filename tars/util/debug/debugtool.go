@@ -37,17 +37,21 @@ func write2File(logname string, desc string, buf []byte) error {
 // logname: prefix of the file's name, which is like logname.20060102-150405
 // msg: description message
 func DumpStack(all bool, logname string, desc string) {
+	write2File(logname, desc, Stack(all))
+}
+
+// Stack returns the stack info
+// all: true means all running goroutine stack, else only the one that calls the func
+func Stack(all bool) []byte {
 	buf := make([]byte, 1024)
 	for {
 		//the buf is no more than 64M, because Stack dumps no more than 64M
 		n := runtime.Stack(buf, all)
 		if n < len(buf) {
-			buf = buf[:n] //trim unreadable characters
-			break
+			return buf[:n] //trim unreadable characters
 		}
 		buf = make([]byte, 2*len(buf))
 	}
-	write2File(logname, desc, buf)
 }
 
 // SigNotifyStack register os signals to be notified when to dumpstack
@@ -1,6 +1,7 @@
 package grace
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -11,6 +12,8 @@ import (
 var (
 	// InheritFdPrefix marks the fd inherited from parent process
 	InheritFdPrefix = "LISTEN_FD_INHERIT"
+	// ReadyFdKey marks the pipe fd inherited from parent process, which is written once the process is ready
+	ReadyFdKey = "GRACE_READY_FD"
 
 	allListenFds *sync.Map
 )
@@ -81,6 +84,51 @@ CreateUdp:
 	return conn, err
 }
 
+// CreateUDPConns creates n udp connections on addr with SO_REUSEPORT, and the kernel balances the datagrams
+// between them. The connections are inherited from the parent process like CreateUDPConn.
+// If not all the connections can be created, the ones created are returned with the error.
+func CreateUDPConns(addr string, n int) ([]*net.UDPConn, error) {
+	if n <= 1 {
+		conn, err := CreateUDPConn(addr)
+		if err != nil {
+			return nil, err
+		}
+		return []*net.UDPConn{conn}, nil
+	}
+	uaddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	lc := net.ListenConfig{Control: reusePort}
+	conns := make([]*net.UDPConn, 0, n)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("%s_%s_%s", InheritFdPrefix, "udp", addr)
+		if i > 0 {
+			key = fmt.Sprintf("%s_%d", key, i)
+		}
+		if val := os.Getenv(key); val != "" {
+			if fd, err := strconv.Atoi(val); err == nil {
+				file := os.NewFile(uintptr(fd), "listener")
+				if conn, err := net.FileConn(file); err == nil {
+					udpConn := conn.(*net.UDPConn)
+					allListenFds.Store(key, udpConn)
+					conns = append(conns, udpConn)
+					continue
+				}
+				file.Close()
+			}
+		}
+		conn, err := lc.ListenPacket(context.Background(), "udp4", uaddr.String())
+		if err != nil {
+			return conns, err
+		}
+		udpConn := conn.(*net.UDPConn)
+		allListenFds.Store(key, udpConn)
+		conns = append(conns, udpConn)
+	}
+	return conns, nil
+}
+
 // GetAllListenFiles returns all listen files
 func GetAllListenFiles() map[string]*os.File {
 	files := make(map[string]*os.File)
@@ -95,6 +143,24 @@ func GetAllListenFiles() map[string]*os.File {
 	return files
 }
 
+// NotifyReady tells the parent process that the process is ready to serve by writing the inherited pipe,
+// it does nothing if there is no inherited pipe.
+func NotifyReady() error {
+	val := os.Getenv(ReadyFdKey)
+	if val == "" {
+		return nil
+	}
+	_ = os.Unsetenv(ReadyFdKey)
+	fd, err := strconv.Atoi(val)
+	if err != nil {
+		return err
+	}
+	file := os.NewFile(uintptr(fd), "ready")
+	defer file.Close()
+	_, err = file.Write([]byte{1})
+	return err
+}
+
 type filer interface {
 	File() (*os.File, error)
 }
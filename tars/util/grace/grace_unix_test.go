@@ -0,0 +1,43 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package grace
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestNotifyReady(t *testing.T) {
+	if err := NotifyReady(); err != nil {
+		t.Fatal(err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// the fd is owned by NotifyReady like the inherited one
+	fd, err := syscall.Dup(int(w.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	os.Setenv(ReadyFdKey, fmt.Sprint(fd))
+	if err = NotifyReady(); err != nil {
+		t.Fatal(err)
+	}
+	if v := os.Getenv(ReadyFdKey); v != "" {
+		t.Errorf("got %s=%s, want it unset", ReadyFdKey, v)
+	}
+	buf := make([]byte, 2)
+	if n, err := r.Read(buf); err != nil || n != 1 {
+		t.Fatalf("got %d bytes and %v, want 1 byte ready", n, err)
+	}
+	if _, err = r.Read(buf); err != io.EOF {
+		t.Errorf("got %v, want the pipe closed", err)
+	}
+}
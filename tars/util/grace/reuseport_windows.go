@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package grace
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePort is not supported in windows
+func reusePort(network, address string, c syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported")
+}
@@ -79,7 +79,7 @@ func (l *Logger) DyeingWritef(ctx context.Context, depth int, level LogLevel, ex
 	}
 
 	buf := bytes.NewBuffer(nil)
-	if l.writer.NeedPrefix() {
+	if l.Writer().NeedPrefix() {
 		fmt.Fprintf(buf, "%s|", time.Now().Format("2006-01-02 15:04:05.000"))
 
 		if callerFlag {
@@ -105,7 +105,7 @@ func (l *Logger) DyeingWritef(ctx context.Context, depth int, level LogLevel, ex
 	} else {
 		fmt.Fprintf(buf, format, v...)
 	}
-	if l.writer.NeedPrefix() {
+	if l.Writer().NeedPrefix() {
 		buf.WriteByte('\n')
 	}
 
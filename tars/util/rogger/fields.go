@@ -0,0 +1,342 @@
+package rogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TarsCloud/TarsGo/tars/util/current"
+)
+
+// Field is a key-value pair of the structured log.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns the field of string value.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns the field of int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 returns the field of int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 returns the field of float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns the field of bool value.
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration returns the field of duration value, which is logged like 1.5s.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time returns the field of time value, which is logged in RFC3339 with milliseconds.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns the field of the error with key error.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Any returns the field of any value.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// toFields turns the key-value pairs to the fields, the arguments may be Field or the key followed by its value.
+func toFields(kv []interface{}) []Field {
+	fields := make([]Field, 0, len(kv))
+	for i := 0; i < len(kv); i++ {
+		switch v := kv[i].(type) {
+		case Field:
+			fields = append(fields, v)
+		case []Field:
+			fields = append(fields, v...)
+		default:
+			key, ok := v.(string)
+			if !ok {
+				key = fmt.Sprint(v)
+			}
+			if i+1 == len(kv) {
+				// the key without value
+				fields = append(fields, Field{Key: "!BADKEY", Value: key})
+				break
+			}
+			i++
+			fields = append(fields, Field{Key: key, Value: kv[i]})
+		}
+	}
+	return fields
+}
+
+// With returns a child logger with the fields bound, which are logged with each log.
+// The arguments may be Field or the key followed by its value.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := toFields(kv)
+	child := &Logger{
+		name:   l.name,
+		prefix: l.prefix,
+		parent: l,
+		fields: make([]Field, 0, len(l.fields)+len(fields)),
+	}
+	child.fields = append(append(child.fields, l.fields...), fields...)
+	return child
+}
+
+// WithContext returns a child logger with the fields of the request in the context,
+// which are servant, func, request_id, trace_id and dyeing_key.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return l.With(ContextFields(ctx))
+}
+
+// ContextFields returns the fields of the request in the context.
+func ContextFields(ctx context.Context) []Field {
+	var fields []Field
+	if servant, ok := current.GetServantNameFromContext(ctx); ok && servant != "" {
+		fields = append(fields, String("servant", servant))
+	}
+	if funcName, ok := current.GetFuncNameFromContext(ctx); ok && funcName != "" {
+		fields = append(fields, String("func", funcName))
+	}
+	if requestID, ok := current.GetRequestIDFromContext(ctx); ok && requestID != 0 {
+		fields = append(fields, Int64("request_id", int64(requestID)))
+	}
+	if trace, ok := current.GetTarsTrace(ctx); ok {
+		if traceID := trace.SpanContext().TraceID(); traceID != "" {
+			fields = append(fields, String("trace_id", traceID))
+		}
+	}
+	if dyeingKey, ok := current.GetDyeingKey(ctx); ok {
+		fields = append(fields, String("dyeing_key", dyeingKey))
+	}
+	return fields
+}
+
+// Fields returns the fields bound to the logger.
+func (l *Logger) Fields() []Field {
+	return l.fields
+}
+
+// Debugw logs the message with the key-value pairs in debug loglevel.
+func (l *Logger) Debugw(msg string, kv ...interface{}) {
+	l.Writew(0, DEBUG, msg, kv)
+}
+
+// Infow logs the message with the key-value pairs in info loglevel.
+func (l *Logger) Infow(msg string, kv ...interface{}) {
+	l.Writew(0, INFO, msg, kv)
+}
+
+// Warnw logs the message with the key-value pairs in warning loglevel.
+func (l *Logger) Warnw(msg string, kv ...interface{}) {
+	l.Writew(0, WARN, msg, kv)
+}
+
+// Errorw logs the message with the key-value pairs in error loglevel.
+func (l *Logger) Errorw(msg string, kv ...interface{}) {
+	l.Writew(0, ERROR, msg, kv)
+}
+
+// Writew writes the message with the fields bound to the logger and the key-value pairs.
+func (l *Logger) Writew(depth int, level LogLevel, msg string, kv []interface{}) {
+	if level < logLevel {
+		return
+	}
+	fields := l.fields
+	if len(kv) > 0 {
+		fields = append(append(make([]Field, 0, len(l.fields)+len(kv)), l.fields...), toFields(kv)...)
+	}
+	if s := l.sink(); s != nil {
+		l.writeSink(depth, s, level, msg, fields)
+		return
+	}
+	logQueue <- l.writeFields(depth, level, msg, fields)
+}
+
+// writeFields formats the log with the fields, the caller is found by depth.
+func (l *Logger) writeFields(depth int, level LogLevel, msg string, fields []Field) *logValue {
+	var file, funcName string
+	var line int
+	if callerFlag {
+		pc, f, n, ok := runtime.Caller(depth + callerSkip)
+		if !ok {
+			file, line = "???", 0
+		} else {
+			file, line = filepath.Base(f), n
+			funcName = FuncName(runtime.FuncForPC(pc))
+		}
+	}
+	return l.formatFields(time.Now(), file, funcName, line, level, msg, fields)
+}
+
+// formatFields formats the log with the fields by the log format:
+//
+//	Text:   2006-01-02 15:04:05.000|file.go:func:1|INFO|msg key=value
+//	Logfmt: time=2006-01-02T15:04:05.000 caller=file.go:1 func=func level=INFO msg=msg key=value
+//	Json:   {"time":"2006-01-02 15:04:05.000","func":"func","file":"file.go:1","level":"INFO","msg":"msg","key":"value"}
+func (l *Logger) formatFields(now time.Time, file, funcName string, line int, level LogLevel, msg string, fields []Field) *logValue {
+	w := l.Writer()
+	buf := bytes.NewBuffer(nil)
+
+	switch logFormat {
+	case Json:
+		buf.WriteByte('{')
+		sep := false
+		if l.prefix != "" {
+			writeJsonField(buf, &sep, "pre", l.prefix)
+		}
+		writeJsonField(buf, &sep, "time", now.Format("2006-01-02 15:04:05.000"))
+		if callerFlag {
+			writeJsonField(buf, &sep, "func", funcName)
+			writeJsonField(buf, &sep, "file", fmt.Sprintf("%s:%d", file, line))
+		}
+		writeJsonField(buf, &sep, "level", level.String())
+		writeJsonField(buf, &sep, "msg", msg)
+		for _, f := range fields {
+			writeJsonField(buf, &sep, f.Key, f.Value)
+		}
+		buf.WriteString("}\n")
+	case Logfmt:
+		sep := false
+		if w.NeedPrefix() {
+			writeLogfmtField(buf, &sep, "time", now.Format("2006-01-02T15:04:05.000"))
+			if l.prefix != "" {
+				writeLogfmtField(buf, &sep, "pre", l.prefix)
+			}
+			if callerFlag {
+				writeLogfmtField(buf, &sep, "caller", fmt.Sprintf("%s:%d", file, line))
+				writeLogfmtField(buf, &sep, "func", funcName)
+			}
+			writeLogfmtField(buf, &sep, "level", level.String())
+		}
+		writeLogfmtField(buf, &sep, "msg", msg)
+		for _, f := range fields {
+			writeLogfmtField(buf, &sep, f.Key, f.Value)
+		}
+		if w.NeedPrefix() {
+			buf.WriteByte('\n')
+		}
+	default:
+		if w.NeedPrefix() {
+			fmt.Fprintf(buf, "%s|", now.Format("2006-01-02 15:04:05.000"))
+			if len(l.prefix) > 0 {
+				fmt.Fprintf(buf, "%s|", l.prefix)
+			}
+			if callerFlag {
+				fmt.Fprintf(buf, "%s:%s:%d|", file, funcName, line)
+			}
+			if IsColored() && l.IsConsoleWriter() {
+				buf.WriteString(level.ColoredString())
+			} else {
+				buf.WriteString(level.String())
+			}
+			buf.WriteByte('|')
+		}
+		buf.WriteString(msg)
+		sep := len(msg) > 0
+		for _, f := range fields {
+			writeLogfmtField(buf, &sep, f.Key, f.Value)
+		}
+		if w.NeedPrefix() {
+			buf.WriteByte('\n')
+		}
+	}
+	return &logValue{value: buf.Bytes(), writer: w}
+}
+
+// fieldValue returns the value to be encoded.
+func fieldValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case time.Duration:
+		return val.String()
+	case time.Time:
+		return val.Format("2006-01-02T15:04:05.000Z07:00")
+	case fmt.Stringer:
+		return val.String()
+	}
+	return v
+}
+
+func writeJsonField(buf *bytes.Buffer, sep *bool, key string, value interface{}) {
+	if *sep {
+		buf.WriteByte(',')
+	}
+	*sep = true
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	v, err := marshalJson(fieldValue(value))
+	if err != nil {
+		v, _ = marshalJson(fmt.Sprint(value))
+	}
+	buf.Write(v)
+}
+
+func marshalJson(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func writeLogfmtField(buf *bytes.Buffer, sep *bool, key string, value interface{}) {
+	if *sep {
+		buf.WriteByte(' ')
+	}
+	*sep = true
+	buf.WriteString(logfmtValue(key))
+	buf.WriteByte('=')
+	var s string
+	switch v := fieldValue(value).(type) {
+	case string:
+		s = v
+	case nil:
+		s = "nil"
+	default:
+		s = fmt.Sprint(v)
+	}
+	buf.WriteString(logfmtValue(s))
+}
+
+// logfmtValue quotes the value with the space, quote, equal sign or the control characters.
+func logfmtValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if !utf8.ValidString(s) {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
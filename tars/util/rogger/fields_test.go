@@ -0,0 +1,85 @@
+package rogger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/util/current"
+)
+
+type bufWriter struct {
+	prefix bool
+}
+
+func (w *bufWriter) Write(v []byte) {}
+
+func (w *bufWriter) NeedPrefix() bool {
+	return w.prefix
+}
+
+func formatFields(l *Logger, format LogFormat, msg string, kv ...interface{}) string {
+	old := logFormat
+	defer SetFormat(old)
+	SetFormat(format)
+	fields := append(append([]Field(nil), l.fields...), toFields(kv)...)
+	return string(l.writeFields(0, INFO, msg, fields).value)
+}
+
+// TestFields test the structured logs in the formats.
+func TestFields(t *testing.T) {
+	lg := &Logger{name: "fields", writer: &bufWriter{prefix: true}}
+	child := lg.With("app", "TestApp", Int("n", 1))
+	if child.Writer() != lg.Writer() {
+		t.Fatal("child logger should write to the writer of the parent")
+	}
+
+	line := formatFields(child, Text, "hello world", "cost", 1500*time.Millisecond, Err(errors.New("bad thing")))
+	if !strings.Contains(line, "|INFO|hello world app=TestApp n=1 cost=1.5s error=\"bad thing\"\n") {
+		t.Fatalf("unexpected text log: %q", line)
+	}
+
+	line = formatFields(child, Logfmt, "hello world", "quote", `a"b`, "dangling")
+	if !strings.HasPrefix(line, "time=") ||
+		!strings.Contains(line, " level=INFO msg=\"hello world\" app=TestApp n=1 quote=\"a\\\"b\" !BADKEY=dangling\n") {
+		t.Fatalf("unexpected logfmt log: %q", line)
+	}
+
+	line = formatFields(child, Json, "hello", Bool("ok", true), Err(errors.New("<bad>")))
+	m := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		t.Fatalf("unexpected json log %q: %v", line, err)
+	}
+	if m["msg"] != "hello" || m["level"] != "INFO" || m["app"] != "TestApp" || m["n"] != float64(1) ||
+		m["ok"] != true || m["error"] != "<bad>" {
+		t.Fatalf("unexpected json log: %q", line)
+	}
+
+	// the remote writer has no prefix
+	remote := &Logger{name: "remote", writer: &bufWriter{}}
+	line = formatFields(remote.With("k", "v"), Logfmt, "msg")
+	if line != "msg=msg k=v" {
+		t.Fatalf("unexpected remote log: %q", line)
+	}
+}
+
+// TestContextFields test the fields of the request in the context.
+func TestContextFields(t *testing.T) {
+	ctx := current.ContextWithTarsCurrent(context.Background())
+	current.SetServantNameWithContext(ctx, "TestApp.HelloServer.HelloObj")
+	current.SetFuncNameWithContext(ctx, "Add")
+	current.SetRequestIDWithContext(ctx, 7)
+	current.SetDyeingKey(ctx, "user1")
+
+	lg := &Logger{name: "ctx", writer: &bufWriter{}}
+	line := formatFields(lg.WithContext(ctx), Logfmt, "msg")
+	if line != "msg=msg servant=TestApp.HelloServer.HelloObj func=Add request_id=7 dyeing_key=user1" {
+		t.Fatalf("unexpected context log: %q", line)
+	}
+	if fields := ContextFields(context.Background()); len(fields) != 0 {
+		t.Fatalf("unexpected fields without current: %v", fields)
+	}
+}
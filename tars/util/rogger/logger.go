@@ -27,6 +27,7 @@ const (
 const (
 	Text LogFormat = iota
 	Json
+	Logfmt
 )
 
 var (
@@ -51,6 +52,16 @@ type Logger struct {
 	name   string
 	prefix string
 	writer LogWriter
+	// parent and fields are of the child logger created by With
+	parent *Logger
+	fields []Field
+	// logSink receives the logs instead of the writer if it is set
+	logSink logSink
+}
+
+// logSink receives the logs of the logger, see SetSlogger.
+type logSink interface {
+	log(pc uintptr, level LogLevel, msg string, fields []Field)
 }
 
 type JsonLog struct {
@@ -120,6 +131,8 @@ func (lv *LogFormat) String() string {
 		return "LINE"
 	case Json:
 		return "JSON"
+	case Logfmt:
+		return "LOGFMT"
 	default:
 		return "UNKNOWN"
 	}
@@ -141,6 +154,15 @@ func GetLogger(name string) *Logger {
 	return lg
 }
 
+// NewLogger returns a logger which is not shared by GetLogger, such as the loggers of the same name
+// in the different directories.
+func NewLogger(name string) *Logger {
+	return &Logger{
+		name:   name,
+		writer: &ConsoleWriter{},
+	}
+}
+
 // SetLevel sets the log level
 func SetLevel(level LogLevel) {
 	logLevel = level
@@ -244,7 +266,7 @@ func (l *Logger) SetFileRoller(logpath string, num int, sizeMB int) error {
 
 // IsConsoleWriter returns whether is consoleWriter or not.
 func (l *Logger) IsConsoleWriter() bool {
-	return reflect.TypeOf(l.writer) == reflect.TypeOf(&ConsoleWriter{})
+	return reflect.TypeOf(l.Writer()) == reflect.TypeOf(&ConsoleWriter{})
 }
 
 // SetWriter sets the writer to the logger.
@@ -252,8 +274,11 @@ func (l *Logger) SetWriter(w LogWriter) {
 	l.writer = w
 }
 
-// Writer return the log LogWriter.
+// Writer return the log LogWriter, the child logger writes to the writer of its parent unless it is set.
 func (l *Logger) Writer() LogWriter {
+	if l.writer == nil && l.parent != nil {
+		return l.parent.Writer()
+	}
 	return l.writer
 }
 
@@ -305,11 +330,11 @@ func (l *Logger) Error(v ...interface{}) {
 // Trace log
 func (l *Logger) Trace(msg string) {
 	buf := bytes.NewBuffer(nil)
-	if l.writer.NeedPrefix() {
+	if l.Writer().NeedPrefix() {
 		fmt.Fprintf(buf, "%s|", time.Now().Format("2006-01-02 15:04:05"))
 	}
 	fmt.Fprint(buf, msg)
-	if l.writer.NeedPrefix() {
+	if l.Writer().NeedPrefix() {
 		buf.WriteByte('\n')
 	}
 	l.WriteLog(buf.Bytes())
@@ -340,7 +365,12 @@ func (l *Logger) Writef(depth int, level LogLevel, format string, v []interface{
 		return
 	}
 
-	if logFormat == Json {
+	if s := l.sink(); s != nil {
+		l.writeSink(depth, s, level, sprintf(format, v), l.fields)
+	} else if len(l.fields) > 0 || logFormat == Logfmt {
+		// the bound fields are appended to the message
+		logQueue <- l.writeFields(depth, level, sprintf(format, v), l.fields)
+	} else if logFormat == Json {
 		logQueue <- l.writeJson(depth, level, format, v)
 	} else {
 		logQueue <- l.writeLine(depth, level, format, v)
@@ -349,7 +379,7 @@ func (l *Logger) Writef(depth int, level LogLevel, format string, v []interface{
 
 func (l *Logger) writeLine(depth int, level LogLevel, format string, v []interface{}) *logValue {
 	buf := bytes.NewBuffer(nil)
-	if l.writer.NeedPrefix() {
+	if l.Writer().NeedPrefix() {
 		fmt.Fprintf(buf, "%s|", time.Now().Format("2006-01-02 15:04:05.000"))
 		if len(l.prefix) > 0 {
 			fmt.Fprintf(buf, "%s|", l.prefix)
@@ -378,10 +408,10 @@ func (l *Logger) writeLine(depth int, level LogLevel, format string, v []interfa
 	} else {
 		fmt.Fprintf(buf, format, v...)
 	}
-	if l.writer.NeedPrefix() {
+	if l.Writer().NeedPrefix() {
 		buf.WriteByte('\n')
 	}
-	return &logValue{value: buf.Bytes(), writer: l.writer}
+	return &logValue{value: buf.Bytes(), writer: l.Writer()}
 }
 
 func (l *Logger) writeJson(depth int, level LogLevel, format string, v []interface{}) *logValue {
@@ -411,16 +441,44 @@ func (l *Logger) writeJson(depth int, level LogLevel, format string, v []interfa
 	encoder := json.NewEncoder(buf)
 	encoder.SetEscapeHTML(false)
 	_ = encoder.Encode(log)
-	return &logValue{value: buf.Bytes(), writer: l.writer}
+	return &logValue{value: buf.Bytes(), writer: l.Writer()}
+}
+
+// sink returns the log sink of the logger or its parent.
+func (l *Logger) sink() logSink {
+	for ; l != nil; l = l.parent {
+		if l.logSink != nil {
+			return l.logSink
+		}
+	}
+	return nil
+}
+
+func (l *Logger) writeSink(depth int, s logSink, level LogLevel, msg string, fields []Field) {
+	var pcs [1]uintptr
+	// writeSink is called at the depth of writeLine, and runtime.Callers counts itself
+	runtime.Callers(depth+callerSkip+1, pcs[:])
+	s.log(pcs[0], level, msg, fields)
+}
+
+func sprintf(format string, v []interface{}) string {
+	if format == "" {
+		return fmt.Sprint(v...)
+	}
+	return fmt.Sprintf(format, v...)
 }
 
 // WriteLog write log into log files ignore the log level and log prefix
 func (l *Logger) WriteLog(msg []byte) {
-	logQueue <- &logValue{value: msg, writer: l.writer}
+	logQueue <- &logValue{value: msg, writer: l.Writer()}
 }
 
 func FuncName(f *runtime.Func) string {
-	name := f.Name()
+	return shortFuncName(f.Name())
+}
+
+// shortFuncName trims the package path of the func name.
+func shortFuncName(name string) string {
 	idx := strings.LastIndexByte(name, '/')
 	if idx != -1 {
 		name = name[idx:]
@@ -0,0 +1,176 @@
+//go:build go1.21
+// +build go1.21
+
+package rogger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/util/current"
+)
+
+// SlogHandler is the slog.Handler which writes the logs by the writer of the logger,
+// such as RollFileWriter, DateWriter and RemoteTimeWriter.
+// The records below the global log level are dropped unless the request in the context is dyeing,
+// and the records of the dyeing requests are also sent to the dyeing log queue.
+type SlogHandler struct {
+	l      *Logger
+	fields []Field
+	group  string
+}
+
+// NewSlogHandler returns the slog.Handler backed by the logger.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{l: l}
+}
+
+// NewSlogger returns the slog.Logger backed by the logger.
+func NewSlogger(l *Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// Enabled implements slog.Handler.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if fromSlogLevel(level) >= logLevel {
+		return true
+	}
+	_, dyeing := current.GetDyeingKey(ctx)
+	return dyeing
+}
+
+// Handle implements slog.Handler.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := fromSlogLevel(r.Level)
+	fields := make([]Field, 0, len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.fields...)
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, h.group, attr)
+		return true
+	})
+
+	var file, funcName string
+	var line int
+	if callerFlag && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		file, line = filepath.Base(frame.File), frame.Line
+		funcName = shortFuncName(frame.Function)
+	}
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	v := h.l.formatFields(t, file, funcName, line, level, r.Message, fields)
+	if level >= logLevel {
+		logQueue <- v
+	}
+	if dyeingKey, ok := current.GetDyeingKey(ctx); ok {
+		select {
+		case dyeingLogQueue <- &dyeingLogValue{Level: level, Value: v.value, DyeingKey: dyeingKey}:
+		default:
+			dyeingErrorLog.Error("dyeingLogQueue is full")
+		}
+	}
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.fields = make([]Field, 0, len(h.fields)+len(attrs))
+	h2.fields = append(h2.fields, h.fields...)
+	for _, attr := range attrs {
+		h2.fields = appendAttr(h2.fields, h.group, attr)
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler, the keys of the attrs in the group are prefixed with the group name and a dot.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+func appendAttr(fields []Field, group string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			group += attr.Key + "."
+		}
+		for _, a := range attr.Value.Group() {
+			fields = appendAttr(fields, group, a)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: group + attr.Key, Value: attr.Value.Any()})
+}
+
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	}
+	return ERROR
+}
+
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case DEBUG:
+		return slog.LevelDebug
+	case INFO:
+		return slog.LevelInfo
+	case WARN:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// slogSink sends the logs to the slog.Logger.
+type slogSink struct {
+	logger *slog.Logger
+}
+
+func (s *slogSink) log(pc uintptr, level LogLevel, msg string, fields []Field) {
+	lv := toSlogLevel(level)
+	ctx := context.Background()
+	if !s.logger.Enabled(ctx, lv) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), lv, msg, pc)
+	for _, f := range fields {
+		if err, ok := f.Value.(error); ok {
+			r.AddAttrs(slog.String(f.Key, err.Error()))
+			continue
+		}
+		r.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	if err := s.logger.Handler().Handle(ctx, r); err != nil {
+		fmt.Println("rogger: slog handle error", err)
+	}
+}
+
+// SetSlogger routes the logs of the logger to the slog.Logger instead of the writer,
+// for example tars.TLOG.SetSlogger(slog.Default()) routes the framework logs to the default slog.Logger.
+// The logs below the global log level are still dropped. Set nil to restore the writer.
+func (l *Logger) SetSlogger(s *slog.Logger) {
+	if s == nil {
+		l.logSink = nil
+		return
+	}
+	l.logSink = &slogSink{logger: s}
+}
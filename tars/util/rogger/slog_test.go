@@ -0,0 +1,98 @@
+//go:build go1.21
+// +build go1.21
+
+package rogger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TarsCloud/TarsGo/tars/util/current"
+)
+
+type chanWriter chan string
+
+func (w chanWriter) Write(v []byte) {
+	w <- string(v)
+}
+
+func (w chanWriter) NeedPrefix() bool {
+	return true
+}
+
+// next returns the log written, which is taken from the queue if the logs are flushed by the other tests.
+func (w chanWriter) next(t *testing.T) string {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case v := <-w:
+			return v
+		case v := <-logQueue:
+			if v.writer == LogWriter(w) {
+				return string(v.value)
+			}
+		case <-timeout:
+			t.Fatal("no log written")
+			return ""
+		}
+	}
+}
+
+// TestSlogHandler test the slog.Handler backed by the logger.
+func TestSlogHandler(t *testing.T) {
+	SetLevel(INFO)
+	defer SetLevel(DEBUG)
+	w := make(chanWriter, 10)
+	lg := &Logger{name: "slog", writer: w}
+	sl := NewSlogger(lg).With("app", "TestApp").WithGroup("req")
+
+	sl.Info("hello", "id", 1, slog.Group("user", "name", "tom"))
+	line := w.next(t)
+	if !strings.Contains(line, "|slog_test.go:TestSlogHandler:") ||
+		!strings.HasSuffix(line, "|INFO|hello app=TestApp req.id=1 req.user.name=tom\n") {
+		t.Fatalf("unexpected log: %q", line)
+	}
+
+	// the debug log is dropped unless the request is dyeing
+	sl.Debug("dropped")
+	ctx := current.ContextWithTarsCurrent(context.Background())
+	current.SetDyeingKey(ctx, "user1")
+	sl.DebugContext(ctx, "dyeing")
+	select {
+	case v := <-dyeingLogQueue:
+		if v.DyeingKey != "user1" || !strings.HasSuffix(string(v.Value), "|DEBUG|dyeing app=TestApp\n") {
+			t.Fatalf("unexpected dyeing log: %q %q", v.DyeingKey, v.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no dyeing log")
+	}
+	sl.Warn("next")
+	if line := w.next(t); !strings.Contains(line, "|WARN|next") {
+		t.Fatalf("unexpected log: %q", line)
+	}
+}
+
+// TestSetSlogger test routing the logs of the logger to the slog.Logger.
+func TestSetSlogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lg := &Logger{name: "route", writer: &bufWriter{prefix: true}}
+	lg.SetSlogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{AddSource: true})))
+	lg.With("k", "v").Infof("hello %d", 1)
+	lg.Debugw("debug", "n", 2)
+	out := buf.String()
+	if !strings.Contains(out, "level=INFO source=") || !strings.Contains(out, "slog_test.go:") ||
+		!strings.Contains(out, `msg="hello 1" k=v`) {
+		t.Fatalf("unexpected slog output: %q", out)
+	}
+	if strings.Contains(out, "debug") {
+		t.Fatalf("the debug log should be dropped by the slog.Logger: %q", out)
+	}
+	lg.SetSlogger(nil)
+	if lg.sink() != nil {
+		t.Fatal("the sink should be removed")
+	}
+}